@@ -0,0 +1,129 @@
+package pshgo
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"net"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// AccessRule allows or denies the addresses of a network.
+type AccessRule struct {
+	Allow   bool
+	Network *net.IPNet
+}
+
+// ParseAccessRule parses an entry of HTTPAccess.Addresses: a permission,
+// allow or deny, followed by an address or a CIDR network, separated by a
+// space or a colon, such as "allow 192.0.2.0/24" or "deny:0.0.0.0/0". An entry
+// without a permission allows its network.
+func ParseAccessRule(s string) (AccessRule, error) {
+	rule := AccessRule{Allow: true}
+
+	addr := strings.TrimSpace(s)
+	if idx := strings.IndexAny(addr, " \t:"); idx > 0 {
+		switch strings.ToLower(addr[:idx]) {
+		case "allow":
+			addr = strings.TrimSpace(addr[idx+1:])
+		case "deny":
+			rule.Allow = false
+			addr = strings.TrimSpace(addr[idx+1:])
+		}
+	}
+
+	if !strings.Contains(addr, "/") {
+		ip := net.ParseIP(addr)
+		if ip == nil {
+			return AccessRule{}, errors.Errorf("invalid address %q", s)
+		}
+		bits := 8 * net.IPv6len
+		if ip4 := ip.To4(); ip4 != nil {
+			ip, bits = ip4, 8*net.IPv4len
+		}
+		rule.Network = &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}
+		return rule, nil
+	}
+
+	_, network, err := net.ParseCIDR(addr)
+	if err != nil {
+		return AccessRule{}, errors.Errorf("invalid address %q", s)
+	}
+	rule.Network = network
+	return rule, nil
+}
+
+func (r AccessRule) String() string {
+	if r.Allow {
+		return "allow " + r.Network.String()
+	}
+	return "deny " + r.Network.String()
+}
+
+// AccessPolicy enforces the HTTPAccess settings of a route.
+//
+// The address rules are evaluated in order and the first one whose network
+// contains the client address decides; addresses matching no rule are
+// allowed. When credentials are configured, allowed clients must also
+// authenticate with one of them.
+type AccessPolicy struct {
+	Rules []AccessRule
+
+	credentials map[string][sha256.Size]byte
+}
+
+// NewAccessPolicy returns the policy of access. Invalid addresses are logged
+// and ignored.
+func NewAccessPolicy(access HTTPAccess) *AccessPolicy {
+	p := AccessPolicy{
+		credentials: make(map[string][sha256.Size]byte, len(access.BasicAuth)),
+	}
+
+	for _, addr := range access.Addresses {
+		rule, err := ParseAccessRule(addr)
+		if err != nil {
+			logrus.WithError(err).WithField("address", addr).Warn("ignoring invalid access rule")
+			continue
+		}
+		p.Rules = append(p.Rules, rule)
+	}
+
+	for user, password := range access.BasicAuth {
+		p.credentials[user] = sha256.Sum256([]byte(password))
+	}
+
+	return &p
+}
+
+// RequiresAuth reports whether clients must authenticate.
+func (p *AccessPolicy) RequiresAuth() bool {
+	return len(p.credentials) > 0
+}
+
+// Allowed reports whether ip may access the route, along with the rule
+// deciding it, if any.
+func (p *AccessPolicy) Allowed(ip net.IP) (bool, *AccessRule) {
+	for idx := range p.Rules {
+		if p.Rules[idx].Network.Contains(ip) {
+			return p.Rules[idx].Allow, &p.Rules[idx]
+		}
+	}
+	return true, nil
+}
+
+// Authorized reports whether the credentials are those of a user of the
+// policy. Passwords are compared in constant time and every user is checked,
+// so the time taken reveals neither the users nor their passwords.
+func (p *AccessPolicy) Authorized(user, password string) bool {
+	digest := sha256.Sum256([]byte(password))
+
+	ok := 0
+	for name, expected := range p.credentials {
+		match := subtle.ConstantTimeCompare([]byte(name), []byte(user)) &
+			subtle.ConstantTimeCompare(expected[:], digest[:])
+		ok |= match
+	}
+	return ok == 1
+}
@@ -0,0 +1,85 @@
+package pshgo_test
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	. "github.com/demosdemon/pshgo"
+)
+
+func TestParseAccessRule(t *testing.T) {
+	cases := map[string]string{
+		"allow 192.0.2.0/24":  "allow 192.0.2.0/24",
+		"deny:0.0.0.0/0":      "deny 0.0.0.0/0",
+		"DENY  198.51.100.7":  "deny 198.51.100.7/32",
+		"192.0.2.1/16":        "allow 192.0.0.0/16",
+		"allow 2001:db8::/32": "allow 2001:db8::/32",
+		"2001:db8::1":         "allow 2001:db8::1/128",
+	}
+
+	for input, expected := range cases {
+		rule, err := ParseAccessRule(input)
+		if assert.NoError(t, err, input) {
+			assert.Equal(t, expected, rule.String(), input)
+		}
+	}
+
+	for _, input := range []string{"", "allow", "deny everyone", "allow 192.0.2.0/33"} {
+		_, err := ParseAccessRule(input)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestAccessPolicy(t *testing.T) {
+	p := NewAccessPolicy(HTTPAccess{
+		Addresses: []string{
+			"allow 192.0.2.8/29",
+			"deny 192.0.2.0/24",
+			"invalid",
+			"deny 198.51.100.0/24",
+		},
+		BasicAuth: map[string]string{
+			"admin": "secret",
+			"guest": "",
+		},
+	})
+
+	require.Len(t, p.Rules, 3)
+	assert.True(t, p.RequiresAuth())
+
+	cases := map[string]struct {
+		allowed bool
+		rule    string
+	}{
+		"192.0.2.9":    {true, "allow 192.0.2.8/29"},
+		"192.0.2.1":    {false, "deny 192.0.2.0/24"},
+		"198.51.100.1": {false, "deny 198.51.100.0/24"},
+		"203.0.113.1":  {true, ""},
+		"2001:db8::1":  {true, ""},
+	}
+
+	for addr, c := range cases {
+		allowed, rule := p.Allowed(net.ParseIP(addr))
+		assert.Equal(t, c.allowed, allowed, addr)
+		if c.rule == "" {
+			assert.Nil(t, rule, addr)
+		} else if assert.NotNil(t, rule, addr) {
+			assert.Equal(t, c.rule, rule.String(), addr)
+		}
+	}
+
+	assert.True(t, p.Authorized("admin", "secret"))
+	assert.True(t, p.Authorized("guest", ""))
+	assert.False(t, p.Authorized("admin", "Secret"))
+	assert.False(t, p.Authorized("admin", ""))
+	assert.False(t, p.Authorized("root", "secret"))
+
+	open := NewAccessPolicy(HTTPAccess{})
+	assert.False(t, open.RequiresAuth())
+	allowed, rule := open.Allowed(net.ParseIP("192.0.2.1"))
+	assert.True(t, allowed)
+	assert.Nil(t, rule)
+}
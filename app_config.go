@@ -0,0 +1,298 @@
+package pshgo
+
+import (
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// ApplicationConfig is the content of a .platform.app.yaml file: the
+// application as seen at runtime plus the keys only used while building it.
+type ApplicationConfig struct {
+	Application  `yaml:",inline"`
+	Dependencies JSONObject `json:"dependencies" yaml:"dependencies"`
+	Build        Build      `json:"build" yaml:"build"`
+	Source       Source     `json:"source" yaml:"source"`
+}
+
+// Builder returns the application as described to the build container.
+func (c ApplicationConfig) Builder() ApplicationBuilder {
+	return ApplicationBuilder{
+		ApplicationCore: c.ApplicationCore,
+		Dependencies:    c.Dependencies,
+		Build:           c.Build,
+		Source:          c.Source,
+	}
+}
+
+// LoadApplicationConfig reads and validates a .platform.app.yaml file.
+func LoadApplicationConfig(path string) (*ApplicationConfig, error) {
+	logrus.WithField("path", path).Trace("LoadApplicationConfig")
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading application config")
+	}
+
+	return ParseApplicationConfig(path, data)
+}
+
+// LoadApplication returns the application of the .platform.app.yaml file at
+// path, or of env when path is empty.
+func LoadApplication(env *Environment, path string) (*Application, error) {
+	if path != "" {
+		cfg, err := LoadApplicationConfig(path)
+		if err != nil {
+			return nil, err
+		}
+		return &cfg.Application, nil
+	}
+
+	app := env.GetApplication()
+	if app == nil {
+		return nil, errors.New("the environment has no application")
+	}
+	return app, nil
+}
+
+// ParseApplicationConfig decodes and validates the content of a
+// .platform.app.yaml file. Malformed values and invalid settings are all
+// reported, each as a *ConfigError located by the line and column of its key
+// in filename, as are unknown keys with StrictConfig.
+func ParseApplicationConfig(filename string, data []byte) (*ApplicationConfig, error) {
+	logrus.WithField("filename", filename).Trace("ParseApplicationConfig")
+
+	var (
+		cfg  ApplicationConfig
+		node interface{}
+	)
+
+	d := newYAMLDecoder(filename, data)
+	if err := d.parse(data, &node); err != nil {
+		// the document itself is malformed
+		return nil, err
+	}
+
+	d.decode(nil, node, reflect.ValueOf(&cfg).Elem())
+	webDefaults(node, &cfg.Web)
+
+	for _, path := range sortedMountKeys(cfg.Mounts) {
+		// the zero source is local; only the document tells it was omitted
+		if _, ok := yamlLookup(node, "mounts", path, "source"); !ok {
+			d.fail([]string{"mounts", path, "source"}, errors.New("source is required"))
+		}
+	}
+
+	cfg.validate(func(path []string, err error) {
+		d.fail(path, err)
+	})
+
+	if d.errs != nil {
+		return nil, sortConfigErrors(d.errs)
+	}
+
+	cfg.Mounts = cfg.Mounts.absolute()
+	return &cfg, nil
+}
+
+// absolute returns the mounts keyed by their absolute path. Mount paths are
+// relative to the application root whether or not they start with /.
+func (m Mounts) absolute() Mounts {
+	if m == nil {
+		return nil
+	}
+
+	rv := make(Mounts, len(m))
+	for path, mount := range m {
+		if !strings.HasPrefix(path, "/") {
+			path = "/" + path
+		}
+		rv[path] = mount
+	}
+	return rv
+}
+
+// webDefaults allows the files of the locations of node which do not set
+// allow, as the web server does. Rules only override the settings they set:
+// the others are copied from their location.
+func webDefaults(node interface{}, web *Web) {
+	for path, loc := range web.Locations {
+		if _, ok := yamlLookup(node, "web", "locations", path, "allow"); !ok {
+			loc.Allow = true
+		}
+
+		for pattern, rule := range loc.Rules {
+			set := func(key string) bool {
+				_, ok := yamlLookup(node, "web", "locations", path, "rules", pattern, key)
+				return ok
+			}
+
+			if !set("allow") {
+				rule.Allow = loc.Allow
+			}
+			if !set("scripts") {
+				rule.Scripts = loc.Scripts
+			}
+			if !set("passthru") {
+				rule.Passthru = loc.Passthru
+			}
+			loc.Rules[pattern] = rule
+		}
+
+		web.Locations[path] = loc
+	}
+}
+
+// Validate checks the settings of the application that cannot be expressed by
+// the types alone.
+func (c ApplicationConfig) Validate() error {
+	var rv error
+	c.validate(func(path []string, err error) {
+		rv = multierror.Append(rv, &ConfigError{Path: formatPath(path), Err: err})
+	})
+	return rv
+}
+
+func (c ApplicationConfig) validate(fail func(path []string, err error)) {
+	failf := func(path []string, format string, args ...interface{}) {
+		fail(path, fmt.Errorf(format, args...))
+	}
+
+	if c.Name == "" {
+		failf([]string{"name"}, "name is required")
+	}
+
+	if c.Type == "" {
+		failf([]string{"type"}, "type is required")
+	} else if !isTypeVersion(c.Type) {
+		failf([]string{"type"}, "expected runtime:version, found %q", c.Type)
+	}
+
+	if !c.Size.IsValid() {
+		failf([]string{"size"}, "unknown size %d; expected one of %v", uint8(c.Size), ServiceSizeValues())
+	}
+
+	if c.Timezone != "" {
+		if _, err := time.LoadLocation(c.Timezone); err != nil {
+			failf([]string{"timezone"}, "unknown timezone %q", c.Timezone)
+		}
+	}
+
+	for _, name := range sortedKeys(c.Relationships) {
+		if !isTypeVersion(c.Relationships[name]) {
+			failf([]string{"relationships", name}, "expected service:endpoint, found %q", c.Relationships[name])
+		}
+	}
+
+	var local bool
+	for _, path := range sortedMountKeys(c.Mounts) {
+		m := c.Mounts[path]
+		if !strings.HasPrefix(path, "/") {
+			if _, ok := c.Mounts["/"+path]; ok {
+				failf([]string{"mounts", path}, "mount path duplicates %q", "/"+path)
+			}
+		}
+		switch m.Source {
+		case ApplicationMountLocal:
+			local = true
+		case ApplicationMountService:
+			if m.Service == "" {
+				failf([]string{"mounts", path, "service"}, "service is required for service mounts")
+			}
+		default:
+			if !m.Source.IsValid() {
+				failf([]string{"mounts", path, "source"}, "unknown source %q; expected one of %v", m.Source, ApplicationMountValues())
+			}
+		}
+	}
+
+	if local && c.Disk == 0 {
+		failf([]string{"disk"}, "disk is required by local mounts")
+	}
+
+	locations := make([]string, 0, len(c.Web.Locations))
+	for path := range c.Web.Locations {
+		locations = append(locations, path)
+	}
+	sort.Strings(locations)
+	for _, path := range locations {
+		if !strings.HasPrefix(path, "/") {
+			failf([]string{"web", "locations", path}, "location must start with /")
+		}
+	}
+
+	for _, name := range c.Crons.names() {
+		if c.Crons[name].Spec == "" {
+			failf([]string{"crons", name, "spec"}, "spec is required")
+		} else if _, err := ParseNamedCronSchedule(name, c.Crons[name].Spec); err != nil {
+			fail([]string{"crons", name, "spec"}, err)
+		}
+		if c.Crons[name].Cmd == "" {
+			failf([]string{"crons", name, "cmd"}, "cmd is required")
+		}
+	}
+
+	workers := make([]string, 0, len(c.Workers))
+	for name := range c.Workers {
+		workers = append(workers, name)
+	}
+	sort.Strings(workers)
+	for _, name := range workers {
+		if c.Workers[name].Commands.Start == "" {
+			failf([]string{"workers", name, "commands", "start"}, "start command is required")
+		}
+	}
+}
+
+// isTypeVersion reports whether s has the form name:version.
+func isTypeVersion(s string) bool {
+	idx := strings.Index(s, ":")
+	return idx > 0 && idx < len(s)-1 && strings.Count(s, ":") == 1
+}
+
+func sortedKeys(m StringMap) []string {
+	rv := make([]string, 0, len(m))
+	for k := range m {
+		rv = append(rv, k)
+	}
+	sort.Strings(rv)
+	return rv
+}
+
+func sortedMountKeys(m Mounts) []string {
+	rv := make([]string, 0, len(m))
+	for k := range m {
+		rv = append(rv, k)
+	}
+	sort.Strings(rv)
+	return rv
+}
+
+// sortConfigErrors orders the errors of a multierror by their position.
+func sortConfigErrors(err error) error {
+	merr, ok := err.(*multierror.Error)
+	if !ok {
+		return err
+	}
+
+	sort.SliceStable(merr.Errors, func(i, j int) bool {
+		a, aok := merr.Errors[i].(*ConfigError)
+		b, bok := merr.Errors[j].(*ConfigError)
+		if !aok || !bok {
+			return aok
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+
+	return merr
+}
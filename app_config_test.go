@@ -0,0 +1,278 @@
+package pshgo_test
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	. "github.com/demosdemon/pshgo"
+)
+
+func TestLoadApplicationConfig(t *testing.T) {
+	cfg, err := LoadApplicationConfig(AppConfigFile)
+	require.NoError(t, err)
+
+	assert.Equal(t, "app", cfg.Name)
+	assert.Equal(t, "golang:1.12", cfg.Type)
+	assert.Equal(t, uint32(128), cfg.Disk)
+	assert.Equal(t, Mount{Source: ApplicationMountTemp, SourcePath: "tmpfiles"}, cfg.Mounts["/var/tmp"])
+	assert.Equal(t, SocketFamilyUnix, cfg.Web.Upstream.SocketFamily)
+	assert.Equal(t, "./serve", cfg.Web.Commands.Start)
+	assert.Equal(t, Passthru{Enabled: true}, cfg.Web.Locations["/"].Passthru)
+	assert.Equal(t, cfg.ApplicationCore, cfg.Builder().ApplicationCore)
+}
+
+const appConfigYAML = `name: app
+type: php:7.3
+disk: 1024
+size: L
+relationships:
+  database: db:mysql
+access:
+  ssh: admin
+mounts:
+  "/web/uploads":
+    source: local
+    source_path: uploads
+  var/cache:
+    source: tmp
+dependencies:
+  php:
+    composer/composer: ^1.9
+build:
+  flavor: none
+web:
+  locations:
+    /:
+      root: public
+      passthru: /index.php
+      expires: 1h
+      rules:
+        \.css$:
+          expires: 2h
+crons:
+  cleanup:
+    spec: "*/5 * * * *"
+    cmd: |
+      php cleanup.php
+  digest:
+    spec: "H H(0-5) * * *"
+    cmd: php digest.php
+workers:
+  queue:
+    commands:
+      start: php worker.php
+`
+
+func TestLoadApplication(t *testing.T) {
+	app := base64.StdEncoding.EncodeToString([]byte(`{"name": "dotenv", "type": "golang:1.12"}`))
+	dir := writeLocalProject(t, map[string]string{
+		".env": "PLATFORM_APPLICATION=" + app + "\n",
+	})
+	defer os.RemoveAll(dir)
+
+	env, err := NewDotEnvEnvironment("PLATFORM_", filepath.Join(dir, ".env"))
+	require.NoError(t, err)
+	assert.Equal(t, os.Getenv("PATH"), env.GetEnv("PATH"))
+
+	actual, err := LoadApplication(env, "")
+	require.NoError(t, err)
+	assert.Equal(t, "dotenv", actual.Name)
+
+	actual, err = LoadApplication(env, AppConfigFile)
+	require.NoError(t, err)
+	assert.Equal(t, "app", actual.Name)
+
+	env, err = NewDotEnvEnvironment("PLATFORM_", filepath.Join(dir, "missing.env"))
+	require.NoError(t, err)
+	_, err = LoadApplication(env, "")
+	assert.EqualError(t, err, "the environment has no application")
+
+	_, err = NewDotEnvEnvironment("PLATFORM_", dir)
+	assert.Error(t, err)
+}
+
+func TestParseApplicationConfig(t *testing.T) {
+	cfg, err := ParseApplicationConfig("app.yaml", []byte(appConfigYAML))
+	require.NoError(t, err)
+
+	assert.Equal(t, ServiceSizeLarge, cfg.Size)
+	assert.Equal(t, AccessLevelAdmin, cfg.Access[AccessTypeSSH])
+	assert.Equal(t, StringMap{"database": "db:mysql"}, cfg.Relationships)
+	assert.Equal(t, Mounts{
+		"/web/uploads": {Source: ApplicationMountLocal, SourcePath: "uploads"},
+		"/var/cache":   {Source: ApplicationMountTemp},
+	}, cfg.Mounts)
+	assert.Equal(t, JSONObject{"php": JSONObject{"composer/composer": "^1.9"}}, cfg.Dependencies)
+	assert.Equal(t, "none", cfg.Builder().Build.Flavor)
+
+	loc := cfg.Web.Locations["/"]
+	assert.Equal(t, Passthru{Enabled: true, Path: "/index.php"}, loc.Passthru)
+	assert.Equal(t, time.Hour, loc.Expires.Duration)
+	assert.Equal(t, 2*time.Hour, loc.Rules[`\.css$`].Expires.Duration)
+	assert.Equal(t, Cron{Spec: "*/5 * * * *", Cmd: "php cleanup.php\n"}, cfg.Crons["cleanup"])
+	assert.Equal(t, Cron{Spec: "H H(0-5) * * *", Cmd: "php digest.php"}, cfg.Crons["digest"])
+	assert.Equal(t, "php worker.php", cfg.Workers["queue"].Commands.Start)
+}
+
+func TestParseApplicationConfig_UnknownKeys(t *testing.T) {
+	const data = `name: app
+type: php:7.3
+firewall:
+  outbound:
+    - ips: ["0.0.0.0/0"]
+web:
+  locations:
+    /:
+      alow: true
+`
+
+	cfg, err := ParseApplicationConfig("app.yaml", []byte(data))
+	require.NoError(t, err)
+	assert.Equal(t, "app", cfg.Name)
+	assert.True(t, cfg.Web.Locations["/"].Allow)
+
+	StrictConfig = true
+	defer func() { StrictConfig = false }()
+
+	_, err = ParseApplicationConfig("app.yaml", []byte(data))
+	require.Error(t, err)
+	assert.Equal(t, "2 errors occurred:\n"+
+		"\t* app.yaml:3:1: firewall: unknown field \"firewall\"\n"+
+		"\t* app.yaml:9:7: web.locations[\"/\"].alow: unknown field \"alow\"\n\n", err.Error())
+}
+
+func TestParseApplicationConfig_Errors(t *testing.T) {
+	StrictConfig = true
+	defer func() { StrictConfig = false }()
+
+	cases := []struct {
+		name string
+		data string
+		errs []string
+	}{
+		{
+			"syntax",
+			"name: app\n  type: [\n",
+			[]string{"app.yaml:2:1: yaml: line 2: mapping values are not allowed in this context"},
+		},
+		{
+			"required",
+			"disk: 128\n",
+			[]string{
+				"app.yaml:1:1: name: name is required",
+				"app.yaml:1:1: type: type is required",
+			},
+		},
+		{
+			"values",
+			`name: app
+type: golang
+disk: lots
+web:
+  upstream:
+    socket_family: pipe
+  locations:
+    /:
+      expires: soon
+`,
+			[]string{
+				"app.yaml:2:1: type: expected runtime:version, found \"golang\"",
+				"app.yaml:3:1: disk: cannot unmarshal !!str `lots` into uint32",
+				"app.yaml:6:5: web.upstream.socket_family: unknown SocketFamily name \"pipe\"",
+				"app.yaml:9:7: web.locations[\"/\"].expires: time: invalid duration \"soon\"",
+			},
+		},
+		{
+			"unknown field",
+			`name: app
+type: golang:1.12
+crons:
+  - spec: daily
+web:
+  locations:
+    "/":
+      alow: true
+`,
+			[]string{
+				"app.yaml:3:1: crons: expected a mapping, found a sequence",
+				"app.yaml:8:7: web.locations[\"/\"].alow: unknown field \"alow\"",
+			},
+		},
+		{
+			"validation",
+			`name: app
+type: golang:1.12
+timezone: Mars/Olympus
+relationships:
+  database: mysql
+mounts:
+  tmp:
+    source: local
+  /tmp:
+    source: local
+  /data:
+    source: service
+  /cache:
+    source_path: cache
+crons:
+  backup:
+    spec: "@daily"
+  report:
+    spec: "61 * * * *"
+    cmd: ./report
+workers:
+  queue:
+    commands:
+      stop: kill
+`,
+			[]string{
+				"app.yaml:1:1: disk: disk is required by local mounts",
+				"app.yaml:3:1: timezone: unknown timezone \"Mars/Olympus\"",
+				"app.yaml:5:3: relationships.database: expected service:endpoint, found \"mysql\"",
+				"app.yaml:7:3: mounts.tmp: mount path duplicates \"/tmp\"",
+				"app.yaml:11:3: mounts[\"/data\"].service: service is required for service mounts",
+				"app.yaml:13:3: mounts[\"/cache\"].source: source is required",
+				"app.yaml:16:3: crons.backup.cmd: cmd is required",
+				"app.yaml:19:5: crons.report.spec: invalid cron spec \"61 * * * *\": minute 61 out of range 0-59",
+				"app.yaml:23:5: workers.queue.commands.start: start command is required",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg, err := ParseApplicationConfig("app.yaml", []byte(tc.data))
+			assert.Nil(t, cfg)
+			require.Error(t, err)
+
+			var errs []string
+			if merr, ok := err.(*multierror.Error); ok {
+				for _, err := range merr.Errors {
+					errs = append(errs, err.Error())
+				}
+			} else {
+				errs = append(errs, err.Error())
+			}
+			assert.Equal(t, tc.errs, errs)
+		})
+	}
+}
+
+func TestApplicationConfig_Validate(t *testing.T) {
+	cfg := ApplicationConfig{}
+	cfg.Name = "app"
+	cfg.Type = "golang:1.12"
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Size = ServiceSize(42)
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `size: unknown size 42; expected one of [AUTO S M L XL 2XL 4XL]`)
+}
@@ -0,0 +1,384 @@
+package pshgo
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	XClientCertHeader   = "X-Client-Cert"
+	XClientDNHeader     = "X-Client-Dn"
+	XClientIPHeader     = "X-Client-Ip"
+	XClientVerifyHeader = "X-Client-Verify"
+)
+
+// ClientCertificate is the decoded form of the X_CLIENT_* variables set by the
+// router when a client authenticates with a TLS certificate.
+type ClientCertificate struct {
+	Certificate *Certificate
+	DN          *pkix.Name
+	IP          net.IP
+	Verify      ClientVerify
+	// VerifyError holds the reason given by the router when Verify is
+	// ClientVerifyFailed.
+	VerifyError string
+}
+
+var dnAttributeTypes = map[string]asn1.ObjectIdentifier{
+	"C":            {2, 5, 4, 6},
+	"CN":           {2, 5, 4, 3},
+	"DC":           {0, 9, 2342, 19200300, 100, 1, 25},
+	"EMAILADDRESS": {1, 2, 840, 113549, 1, 9, 1},
+	"L":            {2, 5, 4, 7},
+	"O":            {2, 5, 4, 10},
+	"OU":           {2, 5, 4, 11},
+	"POSTALCODE":   {2, 5, 4, 17},
+	"SERIALNUMBER": {2, 5, 4, 5},
+	"ST":           {2, 5, 4, 8},
+	"STREET":       {2, 5, 4, 9},
+	"UID":          {0, 9, 2342, 19200300, 100, 1, 1},
+}
+
+func LookupClientCert(p PlatformProvider) (*Certificate, bool) {
+	value, ok := LookupXClientCert(p)
+	if !ok || value == "" {
+		return nil, false
+	}
+
+	var cert Certificate
+	if err := cert.UnmarshalText([]byte(value)); err != nil {
+		logrus.WithError(err).Warn("unable to decode client certificate")
+		return nil, false
+	}
+
+	return &cert, true
+}
+
+func GetClientCert(p PlatformProvider) *Certificate {
+	v, _ := LookupClientCert(p)
+	return v
+}
+
+func LookupClientDN(p PlatformProvider) (*pkix.Name, bool) {
+	value, ok := LookupXClientDN(p)
+	if !ok || value == "" {
+		return nil, false
+	}
+
+	name, err := ParseDistinguishedName(value)
+	if err != nil {
+		logrus.WithError(err).Warn("unable to decode client DN")
+		return nil, false
+	}
+
+	return name, true
+}
+
+func GetClientDN(p PlatformProvider) *pkix.Name {
+	v, _ := LookupClientDN(p)
+	return v
+}
+
+func LookupClientIP(p PlatformProvider) (net.IP, bool) {
+	value, ok := LookupXClientIP(p)
+	value = strings.TrimSpace(value)
+	if !ok || value == "" {
+		return nil, false
+	}
+
+	ip := net.ParseIP(value)
+	if ip == nil {
+		logrus.WithField("value", value).Warn("unable to decode client IP")
+		return nil, false
+	}
+
+	return ip, true
+}
+
+func GetClientIP(p PlatformProvider) net.IP {
+	v, _ := LookupClientIP(p)
+	return v
+}
+
+func LookupClientVerify(p PlatformProvider) (ClientVerify, bool) {
+	value, ok := LookupXClientVerify(p)
+	if !ok {
+		return ClientVerifyNone, false
+	}
+
+	v, _, err := ParseClientVerify(value)
+	if err != nil {
+		logrus.WithError(err).Warn("unable to decode client verify")
+		return ClientVerifyNone, false
+	}
+
+	return v, true
+}
+
+func GetClientVerify(p PlatformProvider) ClientVerify {
+	v, _ := LookupClientVerify(p)
+	return v
+}
+
+// LookupClientCertificate decodes all of the X_CLIENT_* variables. It reports
+// false when the router did not pass any client certificate information.
+func LookupClientCertificate(p PlatformProvider) (*ClientCertificate, bool) {
+	rv := &ClientCertificate{
+		Certificate: GetClientCert(p),
+		DN:          GetClientDN(p),
+		IP:          GetClientIP(p),
+		Verify:      ClientVerifyNone,
+	}
+
+	verify, ok := LookupXClientVerify(p)
+	if ok {
+		v, reason, err := ParseClientVerify(verify)
+		if err != nil {
+			logrus.WithError(err).Warn("unable to decode client verify")
+		} else {
+			rv.Verify, rv.VerifyError = v, reason
+		}
+	}
+
+	if rv.Certificate == nil && rv.DN == nil && rv.IP == nil && !ok {
+		return nil, false
+	}
+
+	return rv, true
+}
+
+func GetClientCertificate(p PlatformProvider) *ClientCertificate {
+	v, _ := LookupClientCertificate(p)
+	return v
+}
+
+func (e *Environment) LookupClientCert() (*Certificate, bool) {
+	return LookupClientCert(e)
+}
+
+func (e *Environment) GetClientCert() *Certificate {
+	return GetClientCert(e)
+}
+
+func (e *Environment) LookupClientDN() (*pkix.Name, bool) {
+	return LookupClientDN(e)
+}
+
+func (e *Environment) GetClientDN() *pkix.Name {
+	return GetClientDN(e)
+}
+
+func (e *Environment) LookupClientIP() (net.IP, bool) {
+	return LookupClientIP(e)
+}
+
+func (e *Environment) GetClientIP() net.IP {
+	return GetClientIP(e)
+}
+
+func (e *Environment) LookupClientVerify() (ClientVerify, bool) {
+	return LookupClientVerify(e)
+}
+
+func (e *Environment) GetClientVerify() ClientVerify {
+	return GetClientVerify(e)
+}
+
+func (e *Environment) LookupClientCertificate() (*ClientCertificate, bool) {
+	return LookupClientCertificate(e)
+}
+
+func (e *Environment) GetClientCertificate() *ClientCertificate {
+	return GetClientCertificate(e)
+}
+
+// ClientCertificateFromRequest decodes the X-Client-* headers the router adds
+// to requests forwarded to the application.
+func ClientCertificateFromRequest(r *http.Request) (*ClientCertificate, bool) {
+	p := MapProvider{}
+	for k, v := range map[string]string{
+		"X_CLIENT_CERT":   XClientCertHeader,
+		"X_CLIENT_DN":     XClientDNHeader,
+		"X_CLIENT_IP":     XClientIPHeader,
+		"X_CLIENT_VERIFY": XClientVerifyHeader,
+	} {
+		if values, ok := r.Header[v]; ok && len(values) > 0 {
+			p[k] = values[0]
+		}
+	}
+
+	return LookupClientCertificate(NewEnvironmentWithProvider("", p))
+}
+
+// ParseClientVerify parses the value of X_CLIENT_VERIFY, which is one of
+// SUCCESS, NONE or FAILED:reason.
+func ParseClientVerify(s string) (ClientVerify, string, error) {
+	var reason string
+	if idx := strings.Index(s, ":"); idx >= 0 {
+		s, reason = s[:idx], s[idx+1:]
+	}
+
+	v, err := NewClientVerify(s)
+	return v, reason, err
+}
+
+// ParseDistinguishedName parses a distinguished name in either the RFC 2253
+// form (CN=foo,O=bar) or the legacy OpenSSL form (/O=bar/CN=foo).
+func ParseDistinguishedName(s string) (*pkix.Name, error) {
+	legacy := strings.HasPrefix(s, "/")
+	sep := ','
+	if legacy {
+		s = s[1:]
+		sep = '/'
+	}
+
+	rdns, err := splitDN(s, sep)
+	if err != nil {
+		return nil, err
+	}
+
+	seq := make(pkix.RDNSequence, 0, len(rdns))
+	for _, rdn := range rdns {
+		var set pkix.RelativeDistinguishedNameSET
+		for _, atv := range rdn {
+			idx := strings.Index(atv, "=")
+			if idx < 0 {
+				return nil, fmt.Errorf("invalid attribute %q", atv)
+			}
+
+			oid, err := dnAttributeType(strings.TrimSpace(atv[:idx]))
+			if err != nil {
+				return nil, err
+			}
+
+			set = append(set, pkix.AttributeTypeAndValue{Type: oid, Value: atv[idx+1:]})
+		}
+		seq = append(seq, set)
+	}
+
+	// RFC 2253 lists the most specific RDN first
+	if !legacy {
+		for a, b := 0, len(seq)-1; a < b; a, b = a+1, b-1 {
+			seq[a], seq[b] = seq[b], seq[a]
+		}
+	}
+
+	var name pkix.Name
+	name.FillFromRDNSequence(&seq)
+	return &name, nil
+}
+
+func dnAttributeType(s string) (asn1.ObjectIdentifier, error) {
+	if oid, ok := dnAttributeTypes[strings.ToUpper(s)]; ok {
+		return oid, nil
+	}
+
+	var oid asn1.ObjectIdentifier
+	for _, part := range strings.Split(s, ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("unknown attribute type %q", s)
+		}
+		oid = append(oid, n)
+	}
+
+	if len(oid) < 2 {
+		return nil, fmt.Errorf("unknown attribute type %q", s)
+	}
+
+	return oid, nil
+}
+
+// splitDN splits a DN into RDNs and each RDN into its attributes, honoring
+// backslash escapes.
+func splitDN(s string, sep rune) ([][]string, error) {
+	var (
+		rv      [][]string
+		rdn     []string
+		b       strings.Builder
+		escaped bool
+	)
+
+	for _, r := range s {
+		switch {
+		case escaped:
+			b.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '+':
+			rdn = append(rdn, b.String())
+			b.Reset()
+		case r == sep:
+			rdn = append(rdn, b.String())
+			rv = append(rv, rdn)
+			rdn = nil
+			b.Reset()
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	if escaped {
+		return nil, errors.New("trailing escape in distinguished name")
+	}
+
+	if b.Len() > 0 || len(rdn) > 0 {
+		rdn = append(rdn, b.String())
+		rv = append(rv, rdn)
+	}
+
+	return rv, nil
+}
+
+// VerifyClientCertificate verifies the chain of a client certificate against
+// the ClientCertificateAuthorities of the route. Self-signed authorities are
+// used as roots and the others as intermediates.
+func (s TLSSettings) VerifyClientCertificate(cert *Certificate, opts x509.VerifyOptions) ([][]*x509.Certificate, error) {
+	logrus.Trace("TLSSettings.VerifyClientCertificate")
+
+	if cert == nil || cert.Certificate == nil {
+		return nil, errors.New("missing client certificate")
+	}
+
+	if len(s.ClientCertificateAuthorities) == 0 {
+		return nil, errors.New("route has no client certificate authorities")
+	}
+
+	opts.Roots = x509.NewCertPool()
+	if opts.Intermediates == nil {
+		opts.Intermediates = x509.NewCertPool()
+	}
+	if len(opts.KeyUsages) == 0 {
+		opts.KeyUsages = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+	}
+
+	for _, ca := range s.ClientCertificateAuthorities {
+		if ca.Certificate == nil {
+			continue
+		}
+
+		if ca.CheckSignatureFrom(ca.Certificate) == nil {
+			opts.Roots.AddCert(ca.Certificate)
+		} else {
+			opts.Intermediates.AddCert(ca.Certificate)
+		}
+	}
+
+	return cert.Verify(opts)
+}
+
+// VerifyChain verifies the decoded client certificate against the
+// ClientCertificateAuthorities of the route serving the request.
+func (c *ClientCertificate) VerifyChain(route Route, opts x509.VerifyOptions) ([][]*x509.Certificate, error) {
+	return route.TLS.VerifyClientCertificate(c.Certificate, opts)
+}
@@ -0,0 +1,210 @@
+package pshgo_test
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	. "github.com/demosdemon/pshgo"
+)
+
+const xClientDN = `CN=brandon@leblanc.codes,OU=Security,O=LeBlanc Codes\, LLC,L=Lafayette,ST=Louisiana,C=US`
+
+func TestParseDistinguishedName(t *testing.T) {
+	want := pkix.Name{
+		Country:            []string{"US"},
+		Province:           []string{"Louisiana"},
+		Locality:           []string{"Lafayette"},
+		Organization:       []string{"LeBlanc Codes, LLC"},
+		OrganizationalUnit: []string{"Security"},
+		CommonName:         "brandon@leblanc.codes",
+	}
+
+	cases := []struct {
+		name    string
+		dn      string
+		wantErr bool
+	}{
+		{
+			name: "RFC 2253",
+			dn:   xClientDN,
+		},
+		{
+			name: "legacy",
+			dn:   "/C=US/ST=Louisiana/L=Lafayette/O=LeBlanc Codes, LLC/OU=Security/CN=brandon@leblanc.codes",
+		},
+		{
+			name:    "unknown attribute",
+			dn:      "XX=foo",
+			wantErr: true,
+		},
+		{
+			name:    "missing value",
+			dn:      "CN",
+			wantErr: true,
+		},
+		{
+			name:    "trailing escape",
+			dn:      `CN=foo\`,
+			wantErr: true,
+		},
+	}
+
+	t.Parallel()
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			got, err := ParseDistinguishedName(c.dn)
+			assert.True(t, (err != nil) == c.wantErr)
+			if !c.wantErr {
+				got.Names = nil
+				assert.Equal(t, want, *got)
+				assert.Equal(t, xClientCert.Subject.String(), got.String())
+			}
+		})
+	}
+}
+
+func TestParseClientVerify(t *testing.T) {
+	cases := []struct {
+		value   string
+		want    ClientVerify
+		reason  string
+		wantErr bool
+	}{
+		{value: "SUCCESS", want: ClientVerifySuccess},
+		{value: "NONE", want: ClientVerifyNone},
+		{value: "FAILED:certificate has expired", want: ClientVerifyFailed, reason: "certificate has expired"},
+		{value: "MAYBE", wantErr: true},
+	}
+
+	t.Parallel()
+	for _, c := range cases {
+		c := c
+		t.Run(c.value, func(t *testing.T) {
+			got, reason, err := ParseClientVerify(c.value)
+			assert.True(t, (err != nil) == c.wantErr)
+			assert.Equal(t, c.want, got)
+			assert.Equal(t, c.reason, reason)
+		})
+	}
+}
+
+func TestEnvironment_LookupClientCertificate(t *testing.T) {
+	env := NewEnvironmentWithProvider("PLATFORM_", MapProvider{
+		"X_CLIENT_CERT":   xClientCertText,
+		"X_CLIENT_DN":     xClientDN,
+		"X_CLIENT_IP":     "192.0.2.10",
+		"X_CLIENT_VERIFY": "FAILED:unable to get issuer certificate",
+	})
+
+	got, ok := env.LookupClientCertificate()
+	require.True(t, ok)
+	assert.Equal(t, xClientCert, got.Certificate.Certificate)
+	assert.Equal(t, "brandon@leblanc.codes", got.DN.CommonName)
+	assert.Equal(t, net.ParseIP("192.0.2.10"), got.IP)
+	assert.Equal(t, ClientVerifyFailed, got.Verify)
+	assert.Equal(t, "unable to get issuer certificate", got.VerifyError)
+
+	_, ok = NewEnvironmentWithProvider("PLATFORM_", MapProvider{}).LookupClientCertificate()
+	assert.False(t, ok)
+}
+
+func TestLookupClientIP(t *testing.T) {
+	hook := logtest.NewGlobal()
+	defer hook.Reset()
+
+	ip, ok := LookupClientIP(NewEnvironmentWithProvider("PLATFORM_", MapProvider{"X_CLIENT_IP": " 192.0.2.10 "}))
+	assert.True(t, ok)
+	assert.Equal(t, net.ParseIP("192.0.2.10"), ip)
+
+	_, ok = LookupClientIP(NewEnvironmentWithProvider("PLATFORM_", MapProvider{"X_CLIENT_IP": " "}))
+	assert.False(t, ok)
+	assert.Empty(t, hook.AllEntries())
+
+	_, ok = LookupClientIP(NewEnvironmentWithProvider("PLATFORM_", MapProvider{"X_CLIENT_IP": "nope"}))
+	assert.False(t, ok)
+	assert.Len(t, hook.AllEntries(), 1)
+}
+
+func TestClientCertificateFromRequest(t *testing.T) {
+	req, err := http.NewRequest("GET", "/", nil)
+	require.NoError(t, err)
+	req.Header.Set(XClientCertHeader, xClientCertText)
+	req.Header.Set(XClientVerifyHeader, "SUCCESS")
+
+	got, ok := ClientCertificateFromRequest(req)
+	require.True(t, ok)
+	assert.Equal(t, xClientCert, got.Certificate.Certificate)
+	assert.Equal(t, ClientVerifySuccess, got.Verify)
+	assert.Nil(t, got.DN)
+}
+
+func TestTLSSettings_VerifyClientCertificate(t *testing.T) {
+	valid := x509.VerifyOptions{CurrentTime: time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)}
+	expired := x509.VerifyOptions{CurrentTime: time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)}
+	leaf := &Certificate{Certificate: xClientCert}
+
+	cases := []struct {
+		name    string
+		cas     []Certificate
+		cert    *Certificate
+		opts    x509.VerifyOptions
+		wantErr bool
+	}{
+		{
+			name: "chain",
+			cas:  []Certificate{{Certificate: rootCertificate}, {Certificate: intermediateCertificate}},
+			cert: leaf,
+			opts: valid,
+		},
+		{
+			name:    "expired",
+			cas:     []Certificate{{Certificate: rootCertificate}, {Certificate: intermediateCertificate}},
+			cert:    leaf,
+			opts:    expired,
+			wantErr: true,
+		},
+		{
+			name:    "missing intermediate",
+			cas:     []Certificate{{Certificate: rootCertificate}},
+			cert:    leaf,
+			opts:    valid,
+			wantErr: true,
+		},
+		{
+			name:    "no authorities",
+			cert:    leaf,
+			opts:    valid,
+			wantErr: true,
+		},
+		{
+			name:    "no certificate",
+			cas:     []Certificate{{Certificate: rootCertificate}},
+			opts:    valid,
+			wantErr: true,
+		},
+	}
+
+	t.Parallel()
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			route := Route{TLS: TLSSettings{ClientCertificateAuthorities: c.cas}}
+			cc := ClientCertificate{Certificate: c.cert}
+			chains, err := cc.VerifyChain(route, c.opts)
+			assert.True(t, (err != nil) == c.wantErr, "%v", err)
+			if !c.wantErr {
+				require.Len(t, chains, 1)
+				assert.Len(t, chains[0], 3)
+			}
+		})
+	}
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/joho/godotenv"
+	"github.com/octago/sflags/gen/gflag"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/demosdemon/pshgo"
+)
+
+func main() {
+	Execute(os.Args[1:])
+}
+
+func Execute(args []string) {
+	cfg, err := NewConfig(args)
+	if err != nil {
+		logrus.WithError(err).Fatal()
+	}
+
+	err = cfg.Execute()
+	if err != nil {
+		logrus.WithError(err).Fatal()
+	}
+}
+
+type Config struct {
+	Prefix      string   `desc:"the Platform.sh environment prefix"`
+	Output      string   `desc:"path of the generated .env file"`
+	Root        string   `desc:"the project root containing the .platform directory"`
+	AppDir      string   `desc:"the directory containing .platform.app.yaml; defaults to the project root"`
+	Host        string   `desc:"the host used for {default} routes; defaults to localhost:PORT"`
+	Port        int      `desc:"the port the application listens on"`
+	ServiceHost string   `desc:"the host every relationship points at"`
+	ServicePort []string `desc:"override the port of a relationship or service (name=port)"`
+	Project     string   `desc:"the project ID"`
+	Environment string   `desc:"the environment name"`
+	Branch      string   `desc:"the branch name"`
+	Check       bool     `desc:"only validate .platform.app.yaml, printing every error found"`
+	Strict      bool     `desc:"report unknown configuration keys as errors instead of warnings"`
+	Routes      bool     `desc:"only print the routes generated from routes.yaml as JSON"`
+	Domain      []string `desc:"a project domain used by -routes; the first replaces {default}"`
+	Preview     string   `desc:"preview the routes of the environment under this domain (such as abc.eu-3.platformsh.site)"`
+}
+
+func NewConfig(args []string) (*Config, error) {
+	local := pshgo.DefaultLocalConfig()
+	cfg := &Config{
+		Prefix:      "PLATFORM_",
+		Output:      ".env",
+		Root:        local.Root,
+		Port:        local.Port,
+		ServiceHost: local.ServiceHost,
+		Project:     local.Project,
+		Environment: local.Environment,
+		Branch:      local.Branch,
+	}
+
+	fs := flag.NewFlagSet("localenv", flag.ContinueOnError)
+	must(gflag.ParseTo(cfg, fs))
+
+	err := fs.Parse(args)
+	if err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func (c *Config) LocalConfig() (pshgo.LocalConfig, error) {
+	ports := make(map[string]int, len(c.ServicePort))
+	for _, v := range c.ServicePort {
+		idx := strings.Index(v, "=")
+		if idx < 0 {
+			return pshgo.LocalConfig{}, errors.Errorf("invalid service port %q", v)
+		}
+
+		port, err := strconv.Atoi(v[idx+1:])
+		if err != nil {
+			return pshgo.LocalConfig{}, errors.Wrapf(err, "invalid service port %q", v)
+		}
+
+		ports[v[:idx]] = port
+	}
+
+	return pshgo.LocalConfig{
+		Root:         c.Root,
+		AppDir:       c.AppDir,
+		Host:         c.Host,
+		Port:         c.Port,
+		ServiceHost:  c.ServiceHost,
+		ServicePorts: ports,
+		Project:      c.Project,
+		Environment:  c.Environment,
+		Branch:       c.Branch,
+	}, nil
+}
+
+func (c *Config) Execute() error {
+	log := logrus.WithField("config", c)
+
+	pshgo.StrictConfig = c.Strict
+
+	if c.Check {
+		return c.check()
+	}
+
+	if c.Routes {
+		return c.routes()
+	}
+
+	local, err := c.LocalConfig()
+	if err != nil {
+		return err
+	}
+
+	// keep the entropy of a previous run so derived secrets remain stable
+	prev, err := godotenv.Read(c.Output)
+	if err == nil {
+		local.ProjectEntropy = prev[c.Prefix+"PROJECT_ENTROPY"]
+	} else if !os.IsNotExist(err) {
+		log.WithError(err).Error("unable to read existing .env file")
+		return err
+	}
+
+	env, err := pshgo.NewLocalProvider(c.Prefix, local)
+	if err != nil {
+		log.WithError(err).Error("unable to synthesize environment")
+		return err
+	}
+
+	err = godotenv.Write(env, c.Output)
+	if err != nil {
+		log.WithError(err).Error("unable to write .env file")
+		return err
+	}
+
+	log.WithField("path", c.Output).Info("wrote .env file")
+	return nil
+}
+
+func (c *Config) check() error {
+	dir := c.AppDir
+	if dir == "" {
+		dir = c.Root
+	}
+
+	app, err := pshgo.LoadApplicationConfig(filepath.Join(dir, pshgo.AppConfigFile))
+	if err != nil {
+		return report(err)
+	}
+
+	path := filepath.Join(c.Root, pshgo.ServicesConfigFile)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil
+	}
+
+	services, err := pshgo.LoadServices(path)
+	if err != nil {
+		return report(err)
+	}
+
+	_, err = services.Link(app.Relationships)
+	return report(err)
+}
+
+func (c *Config) routes() error {
+	x := pshgo.RouteExpansion{Domains: c.Domain}
+	if len(x.Domains) == 0 {
+		host := c.Host
+		if host == "" {
+			host = net.JoinHostPort("localhost", strconv.Itoa(c.Port))
+		}
+		x.Domains = []string{host}
+	}
+	if c.Preview != "" {
+		x.Environment = c.Environment
+		x.PreviewDomain = c.Preview
+	}
+
+	routes, err := pshgo.LoadRoutes(filepath.Join(c.Root, pshgo.RoutesConfigFile), x)
+	if err != nil {
+		return report(err)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(routes)
+}
+
+// report prints every error of a multierror on its own line.
+func report(err error) error {
+	if merr, ok := err.(*multierror.Error); ok {
+		for _, err := range merr.Errors {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		return errors.Errorf("found %d errors", len(merr.Errors))
+	}
+
+	return err
+}
+
+func must(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"time"
+
+	"github.com/octago/sflags/gen/gflag"
+	"github.com/sirupsen/logrus"
+
+	"github.com/demosdemon/pshgo"
+	"github.com/demosdemon/pshgo/cmd/serve/ctxutils"
+)
+
+func main() {
+	Execute(os.Args[1:])
+}
+
+func Execute(args []string) {
+	cfg, err := NewConfig(args)
+	if err != nil {
+		logrus.WithError(err).Fatal()
+	}
+
+	status, err := cfg.Execute()
+	if err != nil {
+		logrus.WithError(err).Fatal()
+	}
+	os.Exit(status)
+}
+
+type Config struct {
+	Prefix    string        `desc:"the Platform.sh environment prefix"`
+	DotEnv    string        `desc:"read the specified .env file if it exists; set to /dev/null to disable"`
+	AppConfig string        `desc:"read the crons from this .platform.app.yaml instead of the environment"`
+	Dir       string        `desc:"the working directory of the commands; defaults to the application directory"`
+	Shell     string        `desc:"the shell running the commands"`
+	Timeout   time.Duration `desc:"kill commands running longer than this; 0 disables the timeout"`
+	Once      string        `desc:"run the named cron once and exit with its status"`
+}
+
+func NewConfig(args []string) (*Config, error) {
+	cfg := &Config{
+		Prefix:  "PLATFORM_",
+		DotEnv:  ".env",
+		Shell:   "/bin/sh",
+		Timeout: pshgo.DefaultCronTimeout,
+	}
+
+	fs := flag.NewFlagSet("pshgo-crons", flag.ContinueOnError)
+	must(gflag.ParseTo(cfg, fs))
+
+	err := fs.Parse(args)
+	if err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Execute runs the crons, returning the exit status of the process.
+func (c *Config) Execute() (int, error) {
+	log := logrus.WithField("config", c)
+
+	env, err := pshgo.NewDotEnvEnvironment(c.Prefix, c.DotEnv)
+	if err != nil {
+		log.WithError(err).Error("unable to read the environment")
+		return 1, err
+	}
+
+	app, err := pshgo.LoadApplication(env, c.AppConfig)
+	if err != nil {
+		log.WithError(err).Error("unable to read the crons")
+		return 1, err
+	}
+
+	runner, err := pshgo.NewCronRunner(*app)
+	if err != nil {
+		log.WithError(err).Error("invalid crons")
+		return 1, err
+	}
+
+	runner.Shell = c.Shell
+	runner.Timeout = c.Timeout
+	runner.Env = env.Environ()
+	runner.Dir = c.Dir
+	if runner.Dir == "" {
+		runner.Dir = env.GetAppDir()
+	}
+
+	ctx, cancel := ctxutils.CancelContextWithSignal(context.Background(), os.Interrupt, os.Kill)
+	defer cancel()
+
+	if c.Once != "" {
+		res, err := runner.Run(ctx, c.Once)
+		if err != nil {
+			return 1, err
+		}
+		if res.ExitStatus < 0 {
+			return 1, nil
+		}
+		return res.ExitStatus, nil
+	}
+
+	if len(runner.Schedules) == 0 {
+		log.Warn("no crons to run")
+		return 0, nil
+	}
+
+	runner.Start(ctx)
+	return 0, nil
+}
+
+func must(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,13 @@
+// pshgo-gen renders typed LookupX and GetX accessors for the variables and
+// enums declared in a schema file. See the gen package for the schema format.
+package main
+
+import (
+	"os"
+
+	"github.com/demosdemon/pshgo/gen"
+)
+
+func main() {
+	gen.Execute(os.Args[1:], nil)
+}
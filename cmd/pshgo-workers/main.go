@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"time"
+
+	"github.com/octago/sflags/gen/gflag"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/demosdemon/pshgo"
+	"github.com/demosdemon/pshgo/cmd/serve/ctxutils"
+)
+
+func main() {
+	Execute(os.Args[1:])
+}
+
+func Execute(args []string) {
+	cfg, err := NewConfig(args)
+	if err != nil {
+		logrus.WithError(err).Fatal()
+	}
+
+	err = cfg.Execute()
+	if err != nil {
+		logrus.WithError(err).Fatal()
+	}
+}
+
+type Config struct {
+	Prefix      string        `desc:"the Platform.sh environment prefix"`
+	DotEnv      string        `desc:"read the specified .env file if it exists; set to /dev/null to disable"`
+	AppConfig   string        `desc:"read the workers from this .platform.app.yaml instead of the environment"`
+	Dir         string        `desc:"the working directory of the commands; defaults to the application directory"`
+	Shell       string        `desc:"the shell running the commands"`
+	StopTimeout time.Duration `desc:"kill workers still running this long after being stopped"`
+	Worker      []string      `desc:"only run the named worker; may be repeated"`
+}
+
+func NewConfig(args []string) (*Config, error) {
+	cfg := &Config{
+		Prefix:      "PLATFORM_",
+		DotEnv:      ".env",
+		Shell:       "/bin/sh",
+		StopTimeout: pshgo.DefaultWorkerStopTimeout,
+	}
+
+	fs := flag.NewFlagSet("pshgo-workers", flag.ContinueOnError)
+	must(gflag.ParseTo(cfg, fs))
+
+	err := fs.Parse(args)
+	if err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Execute supervises the workers until the process is interrupted.
+func (c *Config) Execute() error {
+	log := logrus.WithField("config", c)
+
+	env, err := pshgo.NewDotEnvEnvironment(c.Prefix, c.DotEnv)
+	if err != nil {
+		log.WithError(err).Error("unable to read the environment")
+		return err
+	}
+
+	app, err := pshgo.LoadApplication(env, c.AppConfig)
+	if err != nil {
+		log.WithError(err).Error("unable to read the workers")
+		return err
+	}
+
+	supervisor := pshgo.NewWorkerSupervisor(*app)
+	if len(c.Worker) > 0 {
+		workers := make(pshgo.Workers, len(c.Worker))
+		for _, name := range c.Worker {
+			worker, ok := app.Workers[name]
+			if !ok {
+				return errors.Errorf("unknown worker %q", name)
+			}
+			workers[name] = worker
+		}
+		supervisor.Workers = workers
+	}
+
+	if len(supervisor.Workers) == 0 {
+		log.Warn("no workers to run")
+		return nil
+	}
+
+	supervisor.Shell = c.Shell
+	supervisor.StopTimeout = c.StopTimeout
+	supervisor.Env = pshgo.WorkerEnviron(env.Environ())
+	supervisor.Dir = c.Dir
+	if supervisor.Dir == "" {
+		supervisor.Dir = env.GetAppDir()
+	}
+
+	ctx, cancel := ctxutils.CancelContextWithSignal(context.Background(), os.Interrupt, os.Kill)
+	defer cancel()
+
+	supervisor.Run(ctx)
+	return nil
+}
+
+func must(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
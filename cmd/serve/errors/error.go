@@ -1,6 +1,7 @@
 package errors
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"strings"
@@ -343,3 +344,16 @@ func NetworkAuthenticationRequired(msg string, err error) error {
 		Err:        err,
 	}
 }
+
+// ServeHTTP writes the error as the JSON response of an http.Handler.
+func (e HTTPError) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		http.Error(w, e.Error(), e.StatusCode)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(e.StatusCode)
+	_, _ = w.Write(data)
+}
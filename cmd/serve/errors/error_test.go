@@ -2,6 +2,7 @@ package errors_test
 
 import (
 	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -428,3 +429,12 @@ func TestNetworkAuthenticationRequired(t *testing.T) {
 	assert.Equal(t, "msg", httpError.Message)
 	assert.NoError(t, httpError.Err)
 }
+
+func TestHTTPError_ServeHTTP(t *testing.T) {
+	w := httptest.NewRecorder()
+	Forbidden("access denied", nil).(HTTPError).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"status":403,"message":"access denied"}`, w.Body.String())
+}
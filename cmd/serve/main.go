@@ -2,19 +2,21 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"flag"
 	"io"
 	"math/rand"
 	"os"
 	"time"
 
-	"github.com/joho/godotenv"
 	"github.com/octago/sflags/gen/gflag"
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/crypto/ssh/terminal"
 
 	"github.com/demosdemon/pshgo"
 	"github.com/demosdemon/pshgo/cmd/serve/ctxutils"
+	"github.com/demosdemon/pshgo/cmd/serve/middleware"
 	_ "github.com/demosdemon/pshgo/cmd/serve/routes"
 	_ "github.com/demosdemon/pshgo/cmd/serve/routes/api"
 	_ "github.com/demosdemon/pshgo/cmd/serve/routes/env"
@@ -52,6 +54,15 @@ type Config struct {
 	Prefix          string        `desc:"the Platform.sh environment prefix"`
 	DotEnv          string        `desc:"read the specified .env file if it exists; set to /dev/null to disable"`
 	ShutdownTimeout time.Duration `desc:"the amount of time to wait before forcefully terminating the server upon request"`
+	Access          bool          `desc:"enforce the http_access settings of the route matching each request"`
+	Redirects       bool          `desc:"apply the redirects of the route matching each request, as the router would"`
+	Cache           bool          `desc:"cache the responses of the routes with caching enabled in memory, as the router would"`
+	CacheSize       int64         `desc:"the maximum size in bytes of the bodies kept by the response cache"`
+	SSI             bool          `desc:"process the server side includes of the routes with ssi enabled, as the router would"`
+	Static          bool          `desc:"serve the static files of the web locations of the application in front of the app"`
+	TLSCert         string        `flag:"tls-cert" desc:"serve TLS with the PEM certificate chain in this file, applying the tls settings of the routes"`
+	TLSKey          string        `flag:"tls-key" desc:"the PEM private key of the TLS certificate"`
+	Workers         bool          `desc:"run the workers of the application alongside the server, restarting them when they exit"`
 }
 
 func NewConfig(args []string) (*Config, error) {
@@ -59,6 +70,7 @@ func NewConfig(args []string) (*Config, error) {
 		Prefix:          "PLATFORM_",
 		DotEnv:          ".env",
 		ShutdownTimeout: server.DefaultShutdownTimeout,
+		CacheSize:       middleware.DefaultCacheSize,
 	}
 
 	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
@@ -74,38 +86,104 @@ func NewConfig(args []string) (*Config, error) {
 func (c *Config) Execute() error {
 	log := logrus.WithField("config", c)
 
-	dotenv, err := godotenv.Read(c.DotEnv)
-
-	if os.IsNotExist(err) {
-		log.Info(".env file not found")
-		err = nil
-	}
-
+	env, err := pshgo.NewDotEnvEnvironment(c.Prefix, c.DotEnv)
 	if err != nil {
-		log.WithError(err).Error("unable to read .env file")
+		log.WithError(err).Error("unable to read the environment")
 		return err
 	}
 
-	environ := pshgo.LayeredProvider{
-		pshgo.MapProvider(dotenv),
-		pshgo.DefaultProvider,
+	var workers *pshgo.WorkerSupervisor
+	if c.Workers {
+		app := env.GetApplication()
+		if app == nil {
+			err := errors.New("workers require the application configuration")
+			log.WithError(err).Error("unable to run workers")
+			return err
+		}
+
+		workers = pshgo.NewWorkerSupervisor(*app)
+		workers.Env = pshgo.WorkerEnviron(env.Environ())
+		workers.Dir = env.GetAppDir()
 	}
 
-	env := pshgo.NewEnvironmentWithProvider(c.Prefix, environ)
-
 	s := server.New(&server.Globals{
 		Environment: env,
+		Workers:     workers,
 	})
 
+	var tlsConfig *tls.Config
+	if c.TLSCert != "" || c.TLSKey != "" {
+		cert, err := tls.LoadX509KeyPair(c.TLSCert, c.TLSKey)
+		if err != nil {
+			log.WithError(err).Error("unable to load TLS certificate")
+			return err
+		}
+
+		tlsConfig, err = env.GetRoutes().TLSConfig([]tls.Certificate{cert})
+		if err != nil {
+			log.WithError(err).Error("unable to configure TLS")
+			return err
+		}
+
+		s.Wrap(middleware.HSTS(env.GetRoutes()))
+	}
+
+	if c.Access {
+		s.Wrap(middleware.HTTPAccess(env.GetRoutes()))
+	}
+
+	if c.Redirects {
+		s.Wrap(middleware.Redirects(env.GetRoutes()))
+	}
+
+	if c.Cache {
+		s.Wrap(middleware.Cache(env.GetRoutes(), middleware.NewHTTPCache(c.CacheSize)))
+	}
+
+	if c.SSI {
+		s.Wrap(middleware.SSI(env.GetRoutes(), s.Subrequests()))
+	}
+
+	if c.Static {
+		app := env.GetApplication()
+		if app == nil {
+			err := errors.New("static files require the application configuration")
+			log.WithError(err).Error("unable to serve static files")
+			return err
+		}
+
+		dir := env.GetAppDir()
+		if dir == "" {
+			dir = "."
+		}
+		s.Wrap(middleware.Web(dir, app.Web))
+	}
+
 	l, err := env.Listener()
 	if err != nil {
 		log.WithError(err).Error("unable to bind listener")
 		return err
 	}
 
+	if tlsConfig != nil {
+		l = tls.NewListener(l, tlsConfig)
+	}
+
 	ctx, cancel := ctxutils.CancelContextWithSignal(context.Background(), os.Interrupt, os.Kill)
 	defer cancel()
 
+	if workers != nil {
+		done := make(chan struct{})
+		go func() {
+			workers.Run(ctx)
+			close(done)
+		}()
+		defer func() {
+			cancel()
+			<-done
+		}()
+	}
+
 	server.DefaultShutdownTimeout = c.ShutdownTimeout
 	return s.Serve(ctx, l)
 }
@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/demosdemon/pshgo"
+	"github.com/demosdemon/pshgo/cmd/serve/errors"
+)
+
+// HTTPAccess enforces the http_access settings of the route matching each
+// request. Clients are identified by the address of the connection; headers
+// such as X-Forwarded-For are not trusted.
+func HTTPAccess(routes pshgo.Routes) func(http.Handler) http.Handler {
+	var (
+		mu       sync.Mutex
+		policies = make(map[pshgo.RouteIdentification]*pshgo.AccessPolicy)
+	)
+
+	policy := func(id pshgo.RouteIdentification, route pshgo.Route) *pshgo.AccessPolicy {
+		mu.Lock()
+		defer mu.Unlock()
+
+		p, ok := policies[id]
+		if !ok {
+			p = pshgo.NewAccessPolicy(route.HTTPAccess)
+			policies[id] = p
+		}
+		return p
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route, repr, ok := routes.MatchRequest(r)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			p := policy(repr.Route, route)
+			if len(p.Rules) == 0 && !p.RequiresAuth() {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			log := logrus.WithFields(logrus.Fields{
+				"url":    r.URL.String(),
+				"route":  route.OriginalURL,
+				"client": r.RemoteAddr,
+			})
+
+			host, _, err := net.SplitHostPort(r.RemoteAddr)
+			if err != nil {
+				host = r.RemoteAddr
+			}
+
+			ip := net.ParseIP(host)
+			if ip == nil {
+				log.Info("access denied: unknown client address")
+				writeError(w, r, errors.Forbidden("access denied", nil))
+				return
+			}
+
+			allowed, rule := p.Allowed(ip)
+			if rule != nil {
+				log = log.WithField("rule", rule.String())
+			}
+			if !allowed {
+				log.Info("access denied")
+				writeError(w, r, errors.Forbidden("access denied", nil))
+				return
+			}
+
+			if p.RequiresAuth() {
+				user, password, ok := r.BasicAuth()
+				if !ok || !p.Authorized(user, password) {
+					log.WithField("user", user).Info("access denied: authentication required")
+					w.Header().Set("WWW-Authenticate", `Basic realm="Restricted", charset="UTF-8"`)
+					writeError(w, r, errors.Unauthorized("authentication required", nil))
+					return
+				}
+				log = log.WithField("user", user)
+			}
+
+			log.Debug("access allowed")
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// writeError answers r with err the way the router answers handler errors.
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	if httpError, ok := err.(errors.HTTPError); ok {
+		httpError.ServeHTTP(w, r)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
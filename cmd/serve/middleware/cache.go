@@ -0,0 +1,468 @@
+package middleware
+
+import (
+	"bytes"
+	"container/list"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/demosdemon/pshgo"
+)
+
+// CacheStatusHeader is the response header telling whether a response came
+// from the cache: HIT, MISS or BYPASS.
+const CacheStatusHeader = "X-Platform-Cache"
+
+// DefaultCacheSize is the size of the bodies an HTTPCache keeps when MaxSize
+// is not set.
+const DefaultCacheSize = 64 << 20
+
+// HTTPCache keeps the responses of the routes with caching enabled in memory,
+// the way the router does.
+//
+// Responses are keyed by their URL and the request headers and cookies the
+// route is configured with, then by the request headers they Vary on. Their
+// lifetime is given by the Cache-Control or Expires headers of the response,
+// or by the default_ttl of the route when the response has neither. The least
+// recently used responses are evicted once their bodies exceed MaxSize bytes.
+type HTTPCache struct {
+	MaxSize int64
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+	vary    map[string]*cacheVary
+	size    int64
+	now     func() time.Time
+}
+
+// cacheVary are the request headers the responses of a key vary on.
+type cacheVary struct {
+	headers []string
+	entries int
+}
+
+type cacheEntry struct {
+	base    string
+	key     string
+	status  int
+	header  http.Header
+	body    []byte
+	stored  time.Time
+	expires time.Time
+}
+
+// NewHTTPCache returns an empty cache keeping up to maxSize bytes of bodies.
+func NewHTTPCache(maxSize int64) *HTTPCache {
+	if maxSize <= 0 {
+		maxSize = DefaultCacheSize
+	}
+
+	return &HTTPCache{
+		MaxSize: maxSize,
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+		vary:    make(map[string]*cacheVary),
+		now:     time.Now,
+	}
+}
+
+// Cache caches the responses of the route matching each request in c. The
+// sub-requests of SSI are not cached, as their responses are unprocessed.
+func Cache(routes pshgo.Routes, c *HTTPCache) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route, repr, ok := routes.MatchRequest(r)
+			if !ok || !route.Cache.Enabled || isSSISubrequest(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			c.Serve(w, r, route.Cache, repr.Route, next)
+		})
+	}
+}
+
+// Serve answers r from the cache, or with next, caching its response, when
+// the settings allow it.
+func (c *HTTPCache) Serve(w http.ResponseWriter, r *http.Request, settings pshgo.Cache, id pshgo.RouteIdentification, next http.Handler) {
+	log := logrus.WithField("url", r.URL.String())
+
+	if !cacheableRequest(r) {
+		log.Trace("cache bypass")
+		w.Header().Set(CacheStatusHeader, "BYPASS")
+		next.ServeHTTP(w, r)
+		return
+	}
+
+	base := cacheKey(r, settings, id)
+	if e, ok := c.get(base, r); ok {
+		log.Debug("cache hit")
+		e.write(w, c.now())
+		return
+	}
+
+	log.Debug("cache miss")
+	w.Header().Set(CacheStatusHeader, "MISS")
+
+	rec := cacheRecorder{ResponseWriter: w, status: http.StatusOK, limit: c.MaxSize}
+	next.ServeHTTP(&rec, r)
+
+	if rec.overflow || rec.streamed {
+		return
+	}
+
+	ttl, ok := cacheTTL(rec.status, w.Header(), settings, c.now())
+	if !ok {
+		return
+	}
+
+	c.put(base, r, &cacheEntry{
+		status:  rec.status,
+		header:  cloneHeader(w.Header()),
+		body:    rec.body.Bytes(),
+		stored:  c.now(),
+		expires: c.now().Add(ttl),
+	})
+}
+
+func (c *HTTPCache) get(base string, r *http.Request) (*cacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	vary, ok := c.vary[base]
+	if !ok {
+		return nil, false
+	}
+
+	elem, ok := c.entries[varyKey(base, vary.headers, r)]
+	if !ok {
+		return nil, false
+	}
+
+	e := elem.Value.(*cacheEntry)
+	if !c.now().Before(e.expires) {
+		c.remove(elem)
+		return nil, false
+	}
+
+	c.lru.MoveToFront(elem)
+	return e, true
+}
+
+func (c *HTTPCache) put(base string, r *http.Request, e *cacheEntry) {
+	vary := varyHeaders(e.header)
+	for _, name := range vary {
+		if name == "*" {
+			return
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e.base = base
+	e.key = varyKey(base, vary, r)
+
+	if elem, ok := c.entries[e.key]; ok {
+		c.remove(elem)
+	}
+
+	v, ok := c.vary[base]
+	if !ok || !equalStrings(v.headers, vary) {
+		// responses varying on other headers can no longer be found
+		for key, elem := range c.entries {
+			if elem.Value.(*cacheEntry).base == base {
+				c.remove(c.entries[key])
+			}
+		}
+		v = &cacheVary{headers: vary}
+		c.vary[base] = v
+	}
+
+	v.entries++
+	c.entries[e.key] = c.lru.PushFront(e)
+	c.size += int64(len(e.body))
+
+	for c.size > c.MaxSize {
+		oldest := c.lru.Back()
+		logrus.WithField("key", oldest.Value.(*cacheEntry).key).Trace("cache eviction")
+		c.remove(oldest)
+	}
+}
+
+func (c *HTTPCache) remove(elem *list.Element) {
+	e := c.lru.Remove(elem).(*cacheEntry)
+	delete(c.entries, e.key)
+	c.size -= int64(len(e.body))
+
+	if v, ok := c.vary[e.base]; ok {
+		if v.entries--; v.entries <= 0 {
+			delete(c.vary, e.base)
+		}
+	}
+}
+
+// Len returns the number of responses in the cache.
+func (c *HTTPCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+func (e *cacheEntry) write(w http.ResponseWriter, now time.Time) {
+	for k, v := range e.header {
+		w.Header()[k] = v
+	}
+	w.Header().Set("Age", strconv.FormatInt(int64(now.Sub(e.stored)/time.Second), 10))
+	w.Header().Set(CacheStatusHeader, "HIT")
+	w.WriteHeader(e.status)
+	_, _ = w.Write(e.body)
+}
+
+// cacheRecorder passes a response through while keeping a copy of it.
+// Responses flushed by the handler are streamed to the client, and not cached.
+type cacheRecorder struct {
+	http.ResponseWriter
+
+	status   int
+	body     bytes.Buffer
+	limit    int64
+	overflow bool
+	streamed bool
+	wrote    bool
+}
+
+func (r *cacheRecorder) WriteHeader(status int) {
+	if !r.wrote {
+		r.status = status
+		r.wrote = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *cacheRecorder) Write(p []byte) (int, error) {
+	r.wrote = true
+	if !r.overflow && !r.streamed {
+		if int64(r.body.Len()+len(p)) > r.limit {
+			r.overflow = true
+			r.body = bytes.Buffer{}
+		} else {
+			r.body.Write(p)
+		}
+	}
+	return r.ResponseWriter.Write(p)
+}
+
+func (r *cacheRecorder) Flush() {
+	r.streamed = true
+	r.body = bytes.Buffer{}
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func cacheableRequest(r *http.Request) bool {
+	if r.Method != http.MethodGet {
+		return false
+	}
+	if r.Header.Get("Authorization") != "" || r.Header.Get("Range") != "" {
+		return false
+	}
+	return true
+}
+
+// cacheableStatus are the statuses cached without explicit freshness.
+var cacheableStatus = map[int]bool{
+	http.StatusOK:                   true,
+	http.StatusNonAuthoritativeInfo: true,
+	http.StatusNoContent:            true,
+	http.StatusMultipleChoices:      true,
+	http.StatusMovedPermanently:     true,
+	http.StatusNotFound:             true,
+	http.StatusMethodNotAllowed:     true,
+	http.StatusGone:                 true,
+	http.StatusRequestURITooLong:    true,
+	http.StatusNotImplemented:       true,
+}
+
+// cacheTTL returns how long a response may be cached.
+func cacheTTL(status int, h http.Header, settings pshgo.Cache, now time.Time) (time.Duration, bool) {
+	if h.Get("Set-Cookie") != "" {
+		return 0, false
+	}
+
+	if cc := h.Get("Cache-Control"); cc != "" {
+		directives := parseCacheControl(cc)
+		for _, d := range []string{"no-store", "no-cache", "private"} {
+			if _, ok := directives[d]; ok {
+				return 0, false
+			}
+		}
+
+		for _, d := range []string{"s-maxage", "max-age"} {
+			if v, ok := directives[d]; ok {
+				seconds, err := strconv.Atoi(v)
+				if err != nil || seconds <= 0 {
+					return 0, false
+				}
+				return time.Duration(seconds) * time.Second, true
+			}
+		}
+	}
+
+	if v := h.Get("Expires"); v != "" {
+		expires, err := http.ParseTime(v)
+		if err != nil {
+			return 0, false
+		}
+		date, err := http.ParseTime(h.Get("Date"))
+		if err != nil {
+			date = now
+		}
+		ttl := expires.Sub(date)
+		return ttl, ttl > 0
+	}
+
+	if h.Get("Cache-Control") != "" || !cacheableStatus[status] || settings.DefaultTTL <= 0 {
+		return 0, false
+	}
+
+	return time.Duration(settings.DefaultTTL) * time.Second, true
+}
+
+func parseCacheControl(v string) map[string]string {
+	rv := make(map[string]string)
+	for _, part := range strings.Split(v, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value := part, ""
+		if idx := strings.Index(part, "="); idx >= 0 {
+			name, value = part[:idx], strings.Trim(part[idx+1:], `"`)
+		}
+		rv[strings.ToLower(name)] = value
+	}
+	return rv
+}
+
+// cacheKey returns the key of r without the headers its response varies on.
+func cacheKey(r *http.Request, settings pshgo.Cache, id pshgo.RouteIdentification) string {
+	var b strings.Builder
+
+	u := url.URL{Scheme: id.Scheme, Host: r.Host, Path: r.URL.Path, RawQuery: r.URL.RawQuery}
+	b.WriteString(u.String())
+
+	for _, name := range settings.Headers {
+		b.WriteString("\nheader:")
+		b.WriteString(http.CanonicalHeaderKey(name))
+		b.WriteString("=")
+		b.WriteString(strings.Join(r.Header[http.CanonicalHeaderKey(name)], ", "))
+	}
+
+	for _, cookie := range cacheCookies(r, settings.Cookies) {
+		b.WriteString("\ncookie:")
+		b.WriteString(cookie.Name)
+		b.WriteString("=")
+		b.WriteString(cookie.Value)
+	}
+
+	return b.String()
+}
+
+// cacheCookies returns the cookies of r named by patterns, sorted by name.
+// A pattern is either a name, * for every cookie, or a regular expression
+// between slashes.
+func cacheCookies(r *http.Request, patterns []string) []*http.Cookie {
+	var (
+		names = make(map[string]bool, len(patterns))
+		res   []*regexp.Regexp
+		all   bool
+	)
+
+	for _, pattern := range patterns {
+		switch {
+		case pattern == "*":
+			all = true
+		case len(pattern) > 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/"):
+			re, err := regexp.Compile(pattern[1 : len(pattern)-1])
+			if err != nil {
+				logrus.WithError(err).WithField("cookie", pattern).Warn("ignoring invalid cookie pattern")
+				continue
+			}
+			res = append(res, re)
+		default:
+			names[pattern] = true
+		}
+	}
+
+	var rv []*http.Cookie
+	for _, cookie := range r.Cookies() {
+		match := all || names[cookie.Name]
+		for _, re := range res {
+			match = match || re.MatchString(cookie.Name)
+		}
+		if match {
+			rv = append(rv, cookie)
+		}
+	}
+
+	sort.SliceStable(rv, func(i, j int) bool {
+		return rv[i].Name < rv[j].Name
+	})
+	return rv
+}
+
+func varyHeaders(h http.Header) []string {
+	var rv []string
+	for _, v := range h["Vary"] {
+		for _, name := range strings.Split(v, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				rv = append(rv, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+	sort.Strings(rv)
+	return rv
+}
+
+func varyKey(base string, vary []string, r *http.Request) string {
+	var b strings.Builder
+	b.WriteString(base)
+	for _, name := range vary {
+		b.WriteString("\nvary:")
+		b.WriteString(name)
+		b.WriteString("=")
+		b.WriteString(strings.Join(r.Header[name], ", "))
+	}
+	return b.String()
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for idx := range a {
+		if a[idx] != b[idx] {
+			return false
+		}
+	}
+	return true
+}
+
+func cloneHeader(h http.Header) http.Header {
+	rv := make(http.Header, len(h))
+	for k, v := range h {
+		rv[k] = append([]string(nil), v...)
+	}
+	return rv
+}
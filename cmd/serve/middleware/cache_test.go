@@ -0,0 +1,167 @@
+package middleware_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/demosdemon/pshgo/cmd/serve/middleware"
+)
+
+// cacheApp answers every request with a new body, setting the headers given
+// by the query of the request.
+func cacheApp() (http.Handler, *int) {
+	calls := 0
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		for k, v := range r.URL.Query() {
+			w.Header().Set(k, v[0])
+		}
+		_, _ = fmt.Fprintf(w, "response %d", calls)
+	}), &calls
+}
+
+func TestCache(t *testing.T) {
+	routes := testRoutes(t)
+	for u, r := range routes {
+		r.Cache.DefaultTTL = 60
+		r.Cache.Cookies = []string{"session", "/^pref_/"}
+		routes[u] = r
+	}
+
+	app, calls := cacheApp()
+	h := Cache(routes, NewHTTPCache(0))(app)
+
+	get := func(target string, header ...string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("GET", target, nil)
+		for idx := 0; idx+1 < len(header); idx += 2 {
+			r.Header.Add(header[idx], header[idx+1])
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	type step struct {
+		target string
+		header []string
+		status string
+		body   string
+	}
+
+	steps := []step{
+		{"https://example.com/", nil, "MISS", "response 1"},
+		{"https://example.com/", nil, "HIT", "response 1"},
+		{"https://example.com/?a=1", nil, "MISS", "response 2"},
+		{"https://example.com/", []string{"Accept-Language", "fr"}, "MISS", "response 3"},
+		{"https://example.com/", []string{"Accept-Language", "fr"}, "HIT", "response 3"},
+		{"https://example.com/", []string{"Cookie", "session=abc"}, "MISS", "response 4"},
+		{"https://example.com/", []string{"Cookie", "session=abc; tracking=1"}, "HIT", "response 4"},
+		{"https://example.com/", []string{"Cookie", "tracking=1; session=abc; pref_theme=dark"}, "MISS", "response 5"},
+		{"https://example.com/", []string{"Authorization", "Basic Zm9vOmJhcg=="}, "BYPASS", "response 6"},
+		{"https://example.com/?Cache-Control=no-store", nil, "MISS", "response 7"},
+		{"https://example.com/?Cache-Control=no-store", nil, "MISS", "response 8"},
+		{"https://example.com/?Cache-Control=max-age%3D60", nil, "MISS", "response 9"},
+		{"https://example.com/?Cache-Control=max-age%3D60", nil, "HIT", "response 9"},
+		{"https://example.com/?Set-Cookie=a%3Db", nil, "MISS", "response 10"},
+		{"https://example.com/?Set-Cookie=a%3Db", nil, "MISS", "response 11"},
+		{"https://example.com/?Vary=X-Device", []string{"X-Device", "mobile"}, "MISS", "response 12"},
+		{"https://example.com/?Vary=X-Device", []string{"X-Device", "desktop"}, "MISS", "response 13"},
+		{"https://example.com/?Vary=X-Device", []string{"X-Device", "mobile"}, "HIT", "response 12"},
+		{"https://example.com/?Vary=X-Device", []string{"X-Device", "desktop"}, "HIT", "response 13"},
+		{"https://example.com/?Vary=%2A", nil, "MISS", "response 14"},
+		{"https://example.com/?Vary=%2A", nil, "MISS", "response 15"},
+	}
+
+	for idx, s := range steps {
+		w := get(s.target, s.header...)
+		assert.Equal(t, s.status, w.Header().Get(CacheStatusHeader), "step %d", idx)
+		assert.Equal(t, s.body, w.Body.String(), "step %d", idx)
+		if s.status == "HIT" {
+			assert.NotEmpty(t, w.Header().Get("Age"), "step %d", idx)
+		}
+	}
+	assert.Equal(t, 15, *calls)
+
+	r := httptest.NewRequest("POST", "https://example.com/", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	assert.Equal(t, "BYPASS", w.Header().Get(CacheStatusHeader))
+}
+
+func TestCache_DefaultTTL(t *testing.T) {
+	routes := testRoutes(t)
+
+	app, calls := cacheApp()
+	h := Cache(routes, NewHTTPCache(0))(app)
+
+	for range []int{1, 2} {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", "https://example.com/", nil))
+		assert.Equal(t, "MISS", w.Header().Get(CacheStatusHeader))
+	}
+	assert.Equal(t, 2, *calls)
+}
+
+func TestCache_Streamed(t *testing.T) {
+	routes := testRoutes(t)
+	for u, r := range routes {
+		r.Cache.DefaultTTL = 60
+		routes[u] = r
+	}
+
+	calls := 0
+	app := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		_, _ = fmt.Fprint(w, "event 1\n")
+		w.(http.Flusher).Flush()
+		_, _ = fmt.Fprint(w, "event 2\n")
+	})
+	h := Cache(routes, NewHTTPCache(0))(app)
+
+	for range []int{1, 2} {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", "https://example.com/", nil))
+		assert.Equal(t, "MISS", w.Header().Get(CacheStatusHeader))
+		assert.True(t, w.Flushed)
+		assert.Equal(t, "event 1\nevent 2\n", w.Body.String())
+	}
+	assert.Equal(t, 2, calls)
+}
+
+func TestHTTPCache_Eviction(t *testing.T) {
+	routes := testRoutes(t)
+	for u, r := range routes {
+		r.Cache.DefaultTTL = 60
+		routes[u] = r
+	}
+
+	body := strings.Repeat("x", 40)
+	app := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(body))
+	})
+
+	c := NewHTTPCache(100)
+	h := Cache(routes, c)(app)
+
+	status := func(target string) string {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", target, nil))
+		return w.Header().Get(CacheStatusHeader)
+	}
+
+	assert.Equal(t, "MISS", status("https://example.com/a"))
+	assert.Equal(t, "MISS", status("https://example.com/b"))
+	assert.Equal(t, "HIT", status("https://example.com/a"))
+	assert.Equal(t, "MISS", status("https://example.com/c"))
+	assert.Equal(t, 2, c.Len())
+
+	// b was the least recently used
+	assert.Equal(t, "HIT", status("https://example.com/a"))
+	assert.Equal(t, "HIT", status("https://example.com/c"))
+	assert.Equal(t, "MISS", status("https://example.com/b"))
+}
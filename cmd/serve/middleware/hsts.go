@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/demosdemon/pshgo"
+)
+
+// HSTS adds the Strict-Transport-Security header configured by the https
+// route matching each request.
+func HSTS(routes pshgo.Routes) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route, repr, ok := routes.MatchRequest(r)
+			if ok && repr.Route.Scheme == "https" {
+				if v := route.TLS.StrictTransportSecurity.Header(); v != "" {
+					w.Header().Set("Strict-Transport-Security", v)
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
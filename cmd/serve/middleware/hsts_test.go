@@ -0,0 +1,48 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/demosdemon/pshgo"
+	. "github.com/demosdemon/pshgo/cmd/serve/middleware"
+)
+
+func TestHSTS(t *testing.T) {
+	const data = `"https://{default}/":
+  type: upstream
+  upstream: app:http
+  tls:
+    strict_transport_security:
+      enabled: true
+      include_subdomains: true
+"https://plain.{default}/":
+  type: upstream
+  upstream: app:http
+`
+
+	routes, err := pshgo.ParseRoutes("routes.yaml", []byte(data), pshgo.RouteExpansion{Domains: []string{"example.com"}})
+	require.NoError(t, err)
+
+	h := HSTS(routes)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	cases := map[string]string{
+		"https://example.com/":       "max-age=31536000; includeSubDomains",
+		"https://plain.example.com/": "",
+		"http://example.com/":        "",
+	}
+
+	for target, expected := range cases {
+		r := httptest.NewRequest("GET", target, nil)
+		if r.URL.Scheme == "https" {
+			r.Header.Set("X-Forwarded-Proto", "https")
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		assert.Equal(t, expected, w.Header().Get("Strict-Transport-Security"), target)
+	}
+}
@@ -0,0 +1,199 @@
+package middleware
+
+import (
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/demosdemon/pshgo"
+)
+
+// RedirectHandler applies the redirects of a route the way the router does.
+// Requests matching none of them are passed to Next, or answered with 404
+// when Next is nil.
+type RedirectHandler struct {
+	Route pshgo.Route
+	// Path is the path of the URL of the route. Redirect routes send the rest
+	// of the request path to the end of Route.To.
+	Path string
+	Next http.Handler
+
+	rules []redirectRule
+	now   func() time.Time
+}
+
+type redirectRule struct {
+	path   string
+	re     *regexp.Regexp
+	target pshgo.RedirectPath
+}
+
+// NewRedirectHandler returns the handler of the redirects of the route with
+// the URL path.
+func NewRedirectHandler(path string, route pshgo.Route, next http.Handler) *RedirectHandler {
+	h := RedirectHandler{
+		Route: route,
+		Path:  path,
+		Next:  next,
+		now:   time.Now,
+	}
+
+	// exact and prefix paths come first, longest first, then the regular
+	// expressions in a stable order
+	paths := make([]string, 0, len(route.Redirects.Paths))
+	for p := range route.Redirects.Paths {
+		paths = append(paths, p)
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		a, b := route.Redirects.Paths[paths[i]], route.Redirects.Paths[paths[j]]
+		if a.Regexp != b.Regexp {
+			return b.Regexp
+		}
+		if len(paths[i]) != len(paths[j]) && !a.Regexp {
+			return len(paths[i]) > len(paths[j])
+		}
+		return paths[i] < paths[j]
+	})
+
+	for _, p := range paths {
+		rule := redirectRule{path: p, target: route.Redirects.Paths[p]}
+		if rule.target.Regexp {
+			re, err := regexp.Compile(p)
+			if err != nil {
+				logrus.WithError(err).WithField("route", route.OriginalURL).WithField("path", p).Warn("ignoring invalid redirect")
+				continue
+			}
+			rule.re = re
+		}
+		h.rules = append(h.rules, rule)
+	}
+
+	return &h
+}
+
+// Redirects applies the redirects of the route matching each request. The
+// handlers of the routes are built once, when the middleware wraps the next
+// handler.
+func Redirects(routes pshgo.Routes) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		handlers := make(map[pshgo.RouteIdentification]*RedirectHandler, len(routes))
+		for u, route := range routes {
+			id := pshgo.RouteIdentification{Scheme: u.Scheme, Host: u.Host, Path: u.Path}
+			handlers[id] = NewRedirectHandler(u.Path, route, next)
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, repr, ok := routes.MatchRequest(r)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			handlers[repr.Route].ServeHTTP(w, r)
+		})
+	}
+}
+
+func (h *RedirectHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if location, target, ok := h.Redirect(r); ok {
+		log := logrus.WithField("url", r.URL.String()).WithField("location", location)
+		log.Debug("redirect")
+
+		expires := target.Expires.Duration
+		if expires == 0 {
+			expires = h.Route.Redirects.Expires.Duration
+		}
+		h.writeCacheHeaders(w, expires)
+
+		w.Header().Set("Location", location)
+		w.WriteHeader(target.Code)
+		return
+	}
+
+	if h.Next == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	h.Next.ServeHTTP(w, r)
+}
+
+// Redirect returns the location r is redirected to and the rule used.
+func (h *RedirectHandler) Redirect(r *http.Request) (string, pshgo.RedirectPath, bool) {
+	path := r.URL.Path
+	if path == "" {
+		path = "/"
+	}
+
+	if h.Route.IsRedirect() {
+		suffix := strings.TrimPrefix(path, strings.TrimSuffix(h.Path, "/"))
+		location := strings.TrimSuffix(h.Route.To, "/") + "/" + strings.TrimPrefix(suffix, "/")
+		return withQuery(location, r.URL.RawQuery), pshgo.RedirectPath{Code: http.StatusMovedPermanently}, true
+	}
+
+	for _, rule := range h.rules {
+		location, ok := rule.match(path)
+		if !ok {
+			continue
+		}
+
+		target := rule.target
+		if target.Code == 0 {
+			target.Code = http.StatusMovedPermanently
+		}
+		return withQuery(location, r.URL.RawQuery), target, true
+	}
+
+	return "", pshgo.RedirectPath{}, false
+}
+
+func (rule redirectRule) match(path string) (string, bool) {
+	if rule.re != nil {
+		m := rule.re.FindStringSubmatchIndex(path)
+		if m == nil {
+			return "", false
+		}
+		return string(rule.re.ExpandString(nil, rule.target.To, path, m)), true
+	}
+
+	if path == rule.path {
+		return rule.target.To, true
+	}
+
+	if !rule.target.Prefix {
+		return "", false
+	}
+
+	prefix := strings.TrimSuffix(rule.path, "/")
+	if !strings.HasPrefix(path, prefix+"/") {
+		return "", false
+	}
+
+	if !rule.target.AppendSuffix {
+		return rule.target.To, true
+	}
+
+	return strings.TrimSuffix(rule.target.To, "/") + path[len(prefix):], true
+}
+
+func withQuery(location, query string) string {
+	if query == "" || strings.Contains(location, "?") {
+		return location
+	}
+	return location + "?" + query
+}
+
+func (h *RedirectHandler) writeCacheHeaders(w http.ResponseWriter, expires time.Duration) {
+	if expires <= 0 {
+		return
+	}
+
+	seconds := int64(expires / time.Second)
+	w.Header().Set("Cache-Control", "max-age="+strconv.FormatInt(seconds, 10))
+	w.Header().Set("Expires", h.now().Add(expires).UTC().Format(http.TimeFormat))
+}
@@ -0,0 +1,168 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/demosdemon/pshgo"
+	. "github.com/demosdemon/pshgo/cmd/serve/middleware"
+)
+
+func testRoutes(t *testing.T) pshgo.Routes {
+	const data = `"https://{default}/":
+  type: upstream
+  upstream: app:http
+  id: main
+"https://{default}/api":
+  type: upstream
+  upstream: api:http
+"https://*.{default}/":
+  type: upstream
+  upstream: tenant:http
+"https://admin.{default}/":
+  type: upstream
+  upstream: admin:http
+"https://www.{default}/":
+  type: redirect
+  to: https://{default}/
+`
+
+	routes, err := pshgo.ParseRoutes("routes.yaml", []byte(data), pshgo.RouteExpansion{Domains: []string{"example.com"}})
+	require.NoError(t, err)
+	return routes
+}
+
+func TestRedirectHandler(t *testing.T) {
+	route := pshgo.Route{
+		Type:     "upstream",
+		Upstream: "app:http",
+		Redirects: pshgo.Redirects{
+			Expires: pshgo.Duration{Duration: time.Hour},
+			Paths: pshgo.RedirectPaths{
+				"/exact":        {To: "/target"},
+				"/docs":         {To: "https://docs.example.com/", Prefix: true, AppendSuffix: true, Code: 302},
+				"/docs/v1":      {To: "/archive", Prefix: true},
+				"/blog/":        {To: "/news/", Prefix: true, AppendSuffix: true, Expires: pshgo.Duration{Duration: time.Minute}},
+				`^/u/(\d+)$`:    {To: "/users/$1", Regexp: true, Code: 308},
+				`^/(\w+)\.php$`: {To: "/${1}/", Regexp: true},
+			},
+		},
+	}
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	h := NewRedirectHandler("/", route, next)
+
+	cases := []struct {
+		target   string
+		code     int
+		location string
+		maxAge   string
+	}{
+		{"/exact", 301, "/target", "max-age=3600"},
+		{"/exact/child", 418, "", ""},
+		{"/docs", 302, "https://docs.example.com/", "max-age=3600"},
+		{"/docs/intro?lang=en", 302, "https://docs.example.com/intro?lang=en", "max-age=3600"},
+		{"/documents", 418, "", ""},
+		{"/docs/v1/page", 301, "/archive", "max-age=3600"},
+		{"/blog/2019/post", 301, "/news/2019/post", "max-age=60"},
+		{"/u/42", 308, "/users/42", "max-age=3600"},
+		{"/u/abc", 418, "", ""},
+		{"/index.php", 301, "/index/", "max-age=3600"},
+		{"/", 418, "", ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.target, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest("GET", tc.target, nil))
+
+			assert.Equal(t, tc.code, w.Code)
+			assert.Equal(t, tc.location, w.Header().Get("Location"))
+			assert.Equal(t, tc.maxAge, w.Header().Get("Cache-Control"))
+			if tc.maxAge != "" {
+				expires, err := http.ParseTime(w.Header().Get("Expires"))
+				require.NoError(t, err)
+				assert.True(t, expires.After(time.Now()))
+			}
+		})
+	}
+}
+
+func TestRedirectHandler_RedirectRoute(t *testing.T) {
+	route := pshgo.Route{Type: "redirect", To: "https://www.example.com/"}
+
+	w := httptest.NewRecorder()
+	NewRedirectHandler("/", route, nil).ServeHTTP(w, httptest.NewRequest("GET", "http://example.com/a/b?c=d", nil))
+	assert.Equal(t, 301, w.Code)
+	assert.Equal(t, "https://www.example.com/a/b?c=d", w.Header().Get("Location"))
+
+	w = httptest.NewRecorder()
+	NewRedirectHandler("/old", route, nil).ServeHTTP(w, httptest.NewRequest("GET", "http://example.com/old/page", nil))
+	assert.Equal(t, "https://www.example.com/page", w.Header().Get("Location"))
+}
+
+func TestRedirects(t *testing.T) {
+	routes := testRoutes(t)
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	h := Redirects(routes)(next)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "http://example.com/about", nil))
+	assert.Equal(t, 301, w.Code)
+	assert.Equal(t, "https://example.com/about", w.Header().Get("Location"))
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "https://example.com/about", nil))
+	assert.Equal(t, 204, w.Code)
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "https://unknown.org/", nil))
+	assert.Equal(t, 204, w.Code)
+}
+
+func TestRedirects_InvalidRegexp(t *testing.T) {
+	hook := logtest.NewGlobal()
+	defer hook.Reset()
+
+	routes := pshgo.Routes{
+		url.URL{Scheme: "https", Host: "example.com", Path: "/"}: {
+			OriginalURL: "https://{default}/",
+			Type:        "upstream",
+			Redirects: pshgo.Redirects{Paths: pshgo.RedirectPaths{
+				"^/old/(": {To: "/new", Regexp: true, Code: 301},
+				"/legacy": {To: "/modern", Code: 302},
+			}},
+		},
+	}
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	h := Redirects(routes)(next)
+
+	for i := 0; i < 3; i++ {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", "https://example.com/legacy", nil))
+		assert.Equal(t, 302, w.Code)
+		assert.Equal(t, "/modern", w.Header().Get("Location"))
+	}
+
+	var warnings int
+	for _, entry := range hook.AllEntries() {
+		if entry.Message == "ignoring invalid redirect" {
+			warnings++
+		}
+	}
+	assert.Equal(t, 1, warnings)
+}
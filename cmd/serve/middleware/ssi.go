@@ -0,0 +1,514 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/demosdemon/pshgo"
+)
+
+const (
+	// SSIMaxDepth is how deep includes may be nested.
+	SSIMaxDepth = 10
+	// SSIMaxDirective is the longest directive recognized; longer comments
+	// are passed through as they are.
+	SSIMaxDirective = 4096
+
+	ssiError = "[an error occurred while processing the directive]"
+)
+
+var (
+	ssiStart = []byte("<!--#")
+	ssiEnd   = []byte("-->")
+
+	ssiParam    = regexp.MustCompile(`(\w+)\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+	ssiVariable = regexp.MustCompile(`\\\$|\$\{(\w+)\}|\$(\w+)`)
+	ssiCompare  = regexp.MustCompile(`^(.*?)\s*(!=|=)\s*(.*)$`)
+)
+
+// SSI processes the server side includes of the HTML responses of the routes
+// with ssi enabled. Responses are processed as they are written, so streamed
+// responses stay streamed.
+//
+// The include, echo, set, if, elif, else and endif directives are supported.
+// Included documents are requested from handler, which should serve them
+// through the same middleware as the including document, and are themselves
+// processed up to SSIMaxDepth levels deep. The middleware passes its own
+// sub-requests through, as the processor of the including document processes
+// their responses.
+func SSI(routes pshgo.Routes, handler http.Handler) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isSSISubrequest(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			route, _, ok := routes.MatchRequest(r)
+			if !ok || !route.SSI.Enabled {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			vars := make(map[string]string)
+			sw := ssiWriter{
+				ResponseWriter: w,
+				newProcessor: func(out io.Writer) *ssiProcessor {
+					return &ssiProcessor{out: out, req: r, handler: handler, vars: vars}
+				},
+			}
+			next.ServeHTTP(&sw, ssiRequest(r, r.URL))
+			sw.Close()
+		})
+	}
+}
+
+// ssiSubrequestKey marks the context of the sub-requests of includes.
+type ssiSubrequestKey struct{}
+
+// isSSISubrequest tells whether r was made for an include.
+func isSSISubrequest(r *http.Request) bool {
+	v, _ := r.Context().Value(ssiSubrequestKey{}).(bool)
+	return v
+}
+
+// ssiRequest returns a copy of r for u whose response is not compressed.
+func ssiRequest(r *http.Request, u *url.URL) *http.Request {
+	rv := r.WithContext(r.Context())
+	rv.URL = u
+	rv.RequestURI = u.RequestURI()
+	rv.Header = make(http.Header, len(r.Header))
+	for k, v := range r.Header {
+		rv.Header[k] = v
+	}
+	rv.Header.Del("Accept-Encoding")
+	rv.Header.Del("Range")
+	return rv
+}
+
+// ssiWriter processes a response once its headers show it is HTML.
+type ssiWriter struct {
+	http.ResponseWriter
+
+	newProcessor func(io.Writer) *ssiProcessor
+	p            *ssiProcessor
+	wroteHeader  bool
+}
+
+func (w *ssiWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+
+	h := w.Header()
+	if isHTML(h) && h.Get("Content-Encoding") == "" {
+		h.Del("Content-Length")
+		w.p = w.newProcessor(w.ResponseWriter)
+	}
+
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *ssiWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		if w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", http.DetectContentType(p))
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+
+	if w.p == nil {
+		return w.ResponseWriter.Write(p)
+	}
+	return w.p.Write(p)
+}
+
+func (w *ssiWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Close writes what remains of the response.
+func (w *ssiWriter) Close() {
+	if w.p != nil {
+		w.p.Close()
+	}
+}
+
+func isHTML(h http.Header) bool {
+	return strings.HasPrefix(strings.ToLower(h.Get("Content-Type")), "text/html")
+}
+
+// ssiCondition is the state of an if directive.
+type ssiCondition struct {
+	// parent tells whether the if directive itself is output.
+	parent bool
+	// active tells whether the current branch is output.
+	active bool
+	// taken tells whether a branch was output.
+	taken bool
+}
+
+// ssiProcessor interprets the directives of a document written to it.
+type ssiProcessor struct {
+	out     io.Writer
+	req     *http.Request
+	handler http.Handler
+	vars    map[string]string
+	depth   int
+
+	buf   []byte
+	conds []ssiCondition
+	err   error
+}
+
+func (p *ssiProcessor) Write(data []byte) (int, error) {
+	if p.err != nil {
+		return 0, p.err
+	}
+
+	p.buf = append(p.buf, data...)
+
+	for p.err == nil {
+		idx := bytes.Index(p.buf, ssiStart)
+		if idx < 0 {
+			// keep what could be the start of a directive
+			keep := 0
+			for n := len(ssiStart) - 1; n > 0; n-- {
+				if bytes.HasSuffix(p.buf, ssiStart[:n]) {
+					keep = n
+					break
+				}
+			}
+			p.emit(p.buf[:len(p.buf)-keep])
+			p.buf = append(p.buf[:0], p.buf[len(p.buf)-keep:]...)
+			break
+		}
+
+		p.emit(p.buf[:idx])
+		p.buf = p.buf[idx:]
+
+		end := bytes.Index(p.buf, ssiEnd)
+		if end < 0 {
+			if len(p.buf) > SSIMaxDirective {
+				p.emit(p.buf[:len(ssiStart)])
+				p.buf = p.buf[len(ssiStart):]
+				continue
+			}
+			break
+		}
+
+		directive := string(p.buf[len(ssiStart):end])
+		p.buf = p.buf[end+len(ssiEnd):]
+		p.directive(strings.TrimSpace(directive))
+	}
+
+	return len(data), p.err
+}
+
+// Close writes the incomplete directive left, if any, as text.
+func (p *ssiProcessor) Close() {
+	p.emit(p.buf)
+	p.buf = nil
+	if len(p.conds) > 0 {
+		logrus.WithField("url", p.req.URL.String()).Warn("ssi: missing endif")
+	}
+}
+
+func (p *ssiProcessor) active() bool {
+	return len(p.conds) == 0 || p.conds[len(p.conds)-1].active
+}
+
+func (p *ssiProcessor) emit(data []byte) {
+	if len(data) == 0 || !p.active() || p.err != nil {
+		return
+	}
+	_, p.err = p.out.Write(data)
+}
+
+func (p *ssiProcessor) fail(command string, msg string) {
+	logrus.WithFields(logrus.Fields{
+		"url":       p.req.URL.String(),
+		"directive": command,
+	}).Warn("ssi: " + msg)
+	p.emit([]byte(ssiError))
+}
+
+func (p *ssiProcessor) directive(directive string) {
+	command, rest := directive, ""
+	if idx := strings.IndexAny(directive, " \t\r\n"); idx >= 0 {
+		command, rest = directive[:idx], directive[idx+1:]
+	}
+
+	params := make(map[string]string)
+	for _, m := range ssiParam.FindAllStringSubmatch(rest, -1) {
+		params[m[1]] = m[2] + m[3]
+	}
+
+	switch command {
+	case "if":
+		parent := p.active()
+		ok := parent && p.eval(params["expr"])
+		p.conds = append(p.conds, ssiCondition{parent: parent, active: ok, taken: ok})
+		return
+	case "elif", "else", "endif":
+		if len(p.conds) == 0 {
+			p.fail(command, "no matching if")
+			return
+		}
+		c := &p.conds[len(p.conds)-1]
+		switch command {
+		case "elif":
+			c.active = c.parent && !c.taken && p.eval(params["expr"])
+			c.taken = c.taken || c.active
+		case "else":
+			c.active = c.parent && !c.taken
+			c.taken = true
+		case "endif":
+			p.conds = p.conds[:len(p.conds)-1]
+		}
+		return
+	}
+
+	if !p.active() {
+		return
+	}
+
+	switch command {
+	case "include":
+		target := params["virtual"]
+		if target == "" {
+			target = params["file"]
+		}
+		if target == "" {
+			p.fail(command, "include requires virtual or file")
+			return
+		}
+		p.include(p.substitute(target))
+	case "echo":
+		value, ok := p.lookup(params["var"])
+		if !ok {
+			value, ok = params["default"]
+		}
+		if !ok {
+			value = "(none)"
+		}
+		switch params["encoding"] {
+		case "", "entity":
+			value = html.EscapeString(value)
+		case "url":
+			value = url.QueryEscape(value)
+		case "none":
+		default:
+			p.fail(command, "unknown encoding "+params["encoding"])
+			return
+		}
+		p.emit([]byte(value))
+	case "set":
+		if params["var"] == "" {
+			p.fail(command, "set requires var")
+			return
+		}
+		p.vars[params["var"]] = p.substitute(params["value"])
+	default:
+		p.fail(command, "unknown directive")
+	}
+}
+
+// include writes the response of the document at target.
+func (p *ssiProcessor) include(target string) {
+	log := logrus.WithFields(logrus.Fields{
+		"url":     p.req.URL.String(),
+		"include": target,
+		"depth":   p.depth + 1,
+	})
+
+	if p.depth+1 >= SSIMaxDepth {
+		p.fail("include", "includes are nested too deeply")
+		return
+	}
+
+	ref, err := url.Parse(target)
+	if err != nil {
+		p.fail("include", err.Error())
+		return
+	}
+
+	u := p.req.URL.ResolveReference(ref)
+	u.Scheme, u.Host = p.req.URL.Scheme, p.req.URL.Host
+	req := ssiRequest(p.req, u)
+	req = req.WithContext(context.WithValue(req.Context(), ssiSubrequestKey{}, true))
+	req.Method = http.MethodGet
+	req.Body = http.NoBody
+	req.ContentLength = 0
+
+	log.Debug("ssi: include")
+
+	child := &ssiProcessor{
+		out:     p.out,
+		req:     req,
+		handler: p.handler,
+		vars:    p.vars,
+		depth:   p.depth + 1,
+	}
+	sub := ssiSubResponse{header: make(http.Header), p: child}
+	p.handler.ServeHTTP(&sub, req)
+	sub.close()
+
+	if sub.err != nil {
+		p.err = sub.err
+		return
+	}
+
+	if sub.status >= http.StatusBadRequest {
+		log.WithField("status", sub.status).Warn("ssi: include failed")
+		p.emit([]byte(ssiError))
+	}
+}
+
+// eval evaluates the expression of an if or elif directive: a value, true
+// when it is not empty, or the comparison of a value with a text or a
+// regular expression between slashes.
+func (p *ssiProcessor) eval(expr string) bool {
+	m := ssiCompare.FindStringSubmatch(expr)
+	if m == nil {
+		return p.substitute(strings.TrimSpace(expr)) != ""
+	}
+
+	left, op, right := p.substitute(m[1]), m[2], strings.TrimSpace(m[3])
+
+	var match bool
+	if len(right) >= 2 && strings.HasPrefix(right, "/") && strings.HasSuffix(right, "/") {
+		re, err := regexp.Compile(right[1 : len(right)-1])
+		if err != nil {
+			p.fail("if", err.Error())
+			return false
+		}
+		match = re.MatchString(left)
+	} else {
+		match = left == p.substitute(right)
+	}
+
+	return match == (op == "=")
+}
+
+// substitute replaces the variables of s by their values.
+func (p *ssiProcessor) substitute(s string) string {
+	return ssiVariable.ReplaceAllStringFunc(s, func(m string) string {
+		if m == `\$` {
+			return "$"
+		}
+		name := strings.Trim(m, "${}")
+		v, _ := p.lookup(name)
+		return v
+	})
+}
+
+// lookup returns the value of the variable name: a variable set by a set
+// directive or one describing the request.
+func (p *ssiProcessor) lookup(name string) (string, bool) {
+	if v, ok := p.vars[name]; ok {
+		return v, true
+	}
+
+	now := time.Now()
+	switch name {
+	case "DOCUMENT_URI":
+		return p.req.URL.Path, true
+	case "DOCUMENT_NAME":
+		return path.Base(p.req.URL.Path), true
+	case "REQUEST_URI":
+		return p.req.URL.RequestURI(), true
+	case "QUERY_STRING":
+		return p.req.URL.RawQuery, true
+	case "REQUEST_METHOD":
+		return p.req.Method, true
+	case "REMOTE_ADDR":
+		return p.req.RemoteAddr, true
+	case "DATE_LOCAL":
+		return now.Format(time.RFC1123), true
+	case "DATE_GMT":
+		return now.UTC().Format(http.TimeFormat), true
+	}
+
+	if strings.HasPrefix(name, "HTTP_") {
+		header := strings.Replace(strings.TrimPrefix(name, "HTTP_"), "_", "-", -1)
+		if strings.EqualFold(header, "host") {
+			return p.req.Host, true
+		}
+		if values, ok := p.req.Header[http.CanonicalHeaderKey(header)]; ok {
+			return strings.Join(values, ", "), true
+		}
+	}
+
+	return "", false
+}
+
+// ssiSubResponse receives the response of an included document.
+type ssiSubResponse struct {
+	header      http.Header
+	status      int
+	p           *ssiProcessor
+	raw         bool
+	wroteHeader bool
+	err         error
+}
+
+func (w *ssiSubResponse) Header() http.Header {
+	return w.header
+}
+
+func (w *ssiSubResponse) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	w.status = status
+	w.raw = !isHTML(w.header)
+}
+
+func (w *ssiSubResponse) Write(data []byte) (int, error) {
+	if !w.wroteHeader {
+		if w.header.Get("Content-Type") == "" {
+			w.header.Set("Content-Type", http.DetectContentType(data))
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+
+	if w.status >= http.StatusBadRequest {
+		// the error is reported instead
+		return len(data), nil
+	}
+
+	if w.raw {
+		w.p.emit(data)
+		w.err = w.p.err
+		return len(data), w.err
+	}
+
+	n, err := w.p.Write(data)
+	w.err = err
+	return n, err
+}
+
+func (w *ssiSubResponse) close() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if !w.raw && w.status < http.StatusBadRequest {
+		w.p.Close()
+		w.err = w.p.err
+	}
+}
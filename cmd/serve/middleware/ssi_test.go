@@ -0,0 +1,174 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/demosdemon/pshgo"
+	. "github.com/demosdemon/pshgo/cmd/serve/middleware"
+)
+
+func ssiRoutes(t *testing.T) pshgo.Routes {
+	const data = `"https://{default}/":
+  type: upstream
+  upstream: app:http
+  ssi:
+    enabled: true
+"https://plain.{default}/":
+  type: upstream
+  upstream: app:http
+`
+
+	routes, err := pshgo.ParseRoutes("routes.yaml", []byte(data), pshgo.RouteExpansion{Domains: []string{"example.com"}})
+	require.NoError(t, err)
+	return routes
+}
+
+func TestSSI(t *testing.T) {
+	pages := map[string]string{
+		"/header.html": `<h1><!--# echo var="title" default="untitled" --></h1>`,
+		"/nested.html": `[<!--# include virtual="header.html" -->]`,
+		"/loop.html":   `<!--# include virtual="/loop.html" -->`,
+		"/text":        `<!--# echo var="title" -->`,
+	}
+
+	router := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get("Accept-Encoding"))
+		page, ok := pages[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if strings.HasSuffix(r.URL.Path, ".html") {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		} else {
+			w.Header().Set("Content-Type", "text/plain")
+		}
+		_, _ = w.Write([]byte(page))
+	})
+
+	cases := []struct {
+		name     string
+		document string
+		expected string
+	}{
+		{"text", "<p>hello</p>", "<p>hello</p>"},
+		{"include", `<!--# include virtual="/header.html" --><p>`, "<h1>untitled</h1><p>"},
+		{"set", `<!--# set var="title" value="A & B" --><!--# include virtual="/header.html" -->`, "<h1>A &amp; B</h1>"},
+		{"nested", `<!--# set var="title" value="x" --><!--# include virtual="/nested.html" -->`, "[<h1>x</h1>]"},
+		{"raw include", `<!--# set var="title" value="x" --><!--# include virtual="/text" -->`, `<!--# echo var="title" -->`},
+		{"missing include", `<!--# include virtual="/missing" -->`, "[an error occurred while processing the directive]"},
+		{"loop", `<!--# include virtual="/loop.html" -->`, "[an error occurred while processing the directive]"},
+		{"echo encodings", `<!--# set var="v" value="a b&c" --><!--# echo var="v" encoding="url" -->|<!--# echo var="v" encoding="none" -->`, "a+b%26c|a b&c"},
+		{"echo request", `<!--# echo var="DOCUMENT_URI" -->?<!--# echo var="QUERY_STRING" --> <!--# echo var="HTTP_X_TEST" -->`, "/page?q=1 yes"},
+		{"substitution", `<!--# set var="a" value="1" --><!--# set var="b" value="${a}2\$a" --><!--# echo var="b" -->`, "12$a"},
+		{"if", `<!--# set var="a" value="yes" --><!--# if expr="$a" -->A<!--# else -->B<!--# endif -->`, "A"},
+		{"else", `<!--# if expr="$missing" -->A<!--# else -->B<!--# endif -->`, "B"},
+		{"elif", `<!--# set var="a" value="2" --><!--# if expr="$a = 1" -->one<!--# elif expr="$a = /^[0-9]$/" -->digit<!--# else -->other<!--# endif -->`, "digit"},
+		{"not equal", `<!--# if expr="$HTTP_X_TEST != no" -->yes<!--# endif -->`, "yes"},
+		{"nested if", `<!--# if expr="$missing" --><!--# if expr="1" -->A<!--# else -->B<!--# endif --><!--# else -->C<!--# endif -->`, "C"},
+		{"inactive directives", `<!--# if expr="" --><!--# set var="a" value="1" --><!--# include virtual="/missing" --><!--# endif --><!--# echo var="a" -->`, "(none)"},
+		{"unknown", `<!--# exec cmd="ls" -->`, "[an error occurred while processing the directive]"},
+		{"comment", `<!-- comment --><!--#`, `<!-- comment --><!--#`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			app := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/html")
+				w.Header().Set("Content-Length", "1")
+				// write in small pieces to split the directives
+				for idx := 0; idx < len(c.document); idx += 3 {
+					end := idx + 3
+					if end > len(c.document) {
+						end = len(c.document)
+					}
+					_, _ = w.Write([]byte(c.document[idx:end]))
+				}
+			})
+
+			r := httptest.NewRequest("GET", "https://example.com/page?q=1", nil)
+			r.Header.Set("Accept-Encoding", "gzip")
+			r.Header.Set("X-Test", "yes")
+			w := httptest.NewRecorder()
+			SSI(ssiRoutes(t), router)(app).ServeHTTP(w, r)
+
+			assert.Equal(t, c.expected, w.Body.String())
+			assert.Empty(t, w.Header().Get("Content-Length"))
+		})
+	}
+}
+
+func TestSSI_Disabled(t *testing.T) {
+	const document = `<!--# echo var="DOCUMENT_URI" -->`
+	app := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(document))
+	})
+
+	for _, target := range []string{"https://plain.example.com/", "https://unknown.org/"} {
+		w := httptest.NewRecorder()
+		SSI(ssiRoutes(t), http.NotFoundHandler())(app).ServeHTTP(w, httptest.NewRequest("GET", target, nil))
+		assert.Equal(t, document, w.Body.String(), target)
+	}
+
+	app = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(document))
+	})
+	w := httptest.NewRecorder()
+	SSI(ssiRoutes(t), http.NotFoundHandler())(app).ServeHTTP(w, httptest.NewRequest("GET", "https://example.com/", nil))
+	assert.Equal(t, document, w.Body.String())
+}
+
+func TestSSI_Middleware(t *testing.T) {
+	pages := map[string]string{
+		"/":             `<!--# include virtual="/nested.html" -->|<!--# include virtual="/secret.html" -->`,
+		"/nested.html":  `[<!--# include virtual="/header.html" -->]`,
+		"/header.html":  `<h1>title</h1>`,
+		"/secret.html":  `secret`,
+		"/private.html": `<!--# include virtual="/secret.html" -->`,
+	}
+
+	app := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, ok := pages[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(page))
+	})
+
+	deny := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/secret.html" {
+				http.Error(w, "forbidden", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+
+	var handler http.Handler
+	sub := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler.ServeHTTP(w, r)
+	})
+	handler = deny(SSI(ssiRoutes(t), sub)(app))
+
+	cases := map[string]string{
+		"https://example.com/":             "[<h1>title</h1>]|[an error occurred while processing the directive]",
+		"https://example.com/private.html": "[an error occurred while processing the directive]",
+	}
+
+	for target, expected := range cases {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", target, nil))
+		assert.Equal(t, expected, w.Body.String(), target)
+	}
+}
@@ -0,0 +1,262 @@
+package middleware
+
+import (
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/demosdemon/pshgo"
+)
+
+// WebHandler serves the static files of a Web configuration from disk the
+// way the web server of the platform does, passing requests through to App.
+//
+// The location with the longest matching prefix serves a request, its path
+// being mapped onto Root. The first rule of the location whose pattern
+// matches the path overrides the passthru, scripts and allow settings of the
+// location; its expires applies when set and its headers are added to those
+// of the location. Rules are mappings, which keep no order once decoded, so
+// the longest pattern is tried first, and patterns of the same length in
+// lexical order.
+type WebHandler struct {
+	// Dir is the application directory the roots are relative to.
+	Dir string
+	App http.Handler
+
+	locations []webLocation
+	now       func() time.Time
+}
+
+type webLocation struct {
+	path  string
+	loc   pshgo.WebLocation
+	rules []webRule
+}
+
+type webRule struct {
+	re   *regexp.Regexp
+	rule pshgo.WebRule
+}
+
+// webSettings are the settings applying to a single request.
+type webSettings struct {
+	root     string
+	index    []string
+	expires  time.Duration
+	passthru pshgo.Passthru
+	scripts  bool
+	allow    bool
+	headers  pshgo.StringMap
+}
+
+// NewWebHandler returns the handler serving web from dir.
+func NewWebHandler(dir string, web pshgo.Web, app http.Handler) *WebHandler {
+	h := WebHandler{
+		Dir: dir,
+		App: app,
+		now: time.Now,
+	}
+
+	web, warnings := web.Normalize()
+	for _, warning := range warnings {
+		logrus.Warn(warning)
+	}
+
+	for p, loc := range web.Locations {
+		l := webLocation{path: p, loc: loc}
+
+		patterns := make([]string, 0, len(loc.Rules))
+		for pattern := range loc.Rules {
+			patterns = append(patterns, pattern)
+		}
+		sort.Slice(patterns, func(i, j int) bool {
+			if len(patterns[i]) != len(patterns[j]) {
+				return len(patterns[i]) > len(patterns[j])
+			}
+			return patterns[i] < patterns[j]
+		})
+
+		for _, pattern := range patterns {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				logrus.WithError(err).WithField("location", p).WithField("rule", pattern).Warn("ignoring invalid rule")
+				continue
+			}
+			l.rules = append(l.rules, webRule{re: re, rule: loc.Rules[pattern]})
+		}
+
+		h.locations = append(h.locations, l)
+	}
+
+	sort.Slice(h.locations, func(i, j int) bool {
+		return len(h.locations[i].path) > len(h.locations[j].path)
+	})
+
+	return &h
+}
+
+// Web fronts an application with the static files of web.
+func Web(dir string, web pshgo.Web) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return NewWebHandler(dir, web, next)
+	}
+}
+
+func (h *WebHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p := path.Clean("/" + r.URL.Path)
+	log := logrus.WithField("path", p)
+
+	loc, ok := h.location(p)
+	if !ok {
+		log.Debug("no location")
+		http.NotFound(w, r)
+		return
+	}
+
+	s := loc.settings(p)
+	log = log.WithField("location", loc.path)
+
+	if s.root != "" {
+		root := filepath.Join(h.Dir, s.root)
+		name := filepath.Join(root, filepath.FromSlash(strings.TrimPrefix(p, strings.TrimSuffix(loc.path, "/"))))
+		if name != root && !strings.HasPrefix(name, root+string(filepath.Separator)) {
+			log.WithField("file", name).Warn("path outside of the root")
+			http.NotFound(w, r)
+			return
+		}
+
+		if file, index, ok := h.find(name, s.index); ok {
+			if index != "" {
+				// rules apply to the index file the request is served by
+				s = loc.settings(path.Join(p, index))
+			}
+
+			switch {
+			case s.scripts && strings.HasSuffix(file, ".php"):
+				log.Debug("script")
+				h.passthru(w, r, s)
+			case !s.allow:
+				log.WithField("file", file).Debug("denied")
+				http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			default:
+				log.WithField("file", file).Debug("static")
+				h.serveFile(w, r, file, s)
+			}
+			return
+		}
+	}
+
+	h.passthru(w, r, s)
+}
+
+func (h *WebHandler) location(p string) (webLocation, bool) {
+	for _, loc := range h.locations {
+		if pshgo.MatchRoutePath(loc.path, p) || p+"/" == loc.path {
+			return loc, true
+		}
+	}
+	return webLocation{}, false
+}
+
+func (l webLocation) settings(p string) webSettings {
+	s := webSettings{
+		root:     l.loc.Root,
+		index:    l.loc.Index,
+		expires:  l.loc.Expires.Duration,
+		passthru: l.loc.Passthru,
+		scripts:  l.loc.Scripts,
+		allow:    l.loc.Allow,
+		headers:  l.loc.Headers,
+	}
+
+	for _, r := range l.rules {
+		if !r.re.MatchString(p) {
+			continue
+		}
+
+		s.passthru = r.rule.Passthru
+		s.scripts = r.rule.Scripts
+		s.allow = r.rule.Allow
+		if r.rule.Expires.Duration != 0 {
+			s.expires = r.rule.Expires.Duration
+		}
+		if len(r.rule.Headers) > 0 {
+			headers := make(pshgo.StringMap, len(s.headers)+len(r.rule.Headers))
+			for k, v := range s.headers {
+				headers[k] = v
+			}
+			for k, v := range r.rule.Headers {
+				headers[k] = v
+			}
+			s.headers = headers
+		}
+		break
+	}
+
+	return s
+}
+
+// find returns the regular file at name, or the first index file of the
+// directory at name along with its name.
+func (h *WebHandler) find(name string, index []string) (string, string, bool) {
+	fi, err := os.Stat(name)
+	if err != nil {
+		return "", "", false
+	}
+
+	if !fi.IsDir() {
+		return name, "", true
+	}
+
+	for _, idx := range index {
+		file := filepath.Join(name, idx)
+		if fi, err := os.Stat(file); err == nil && !fi.IsDir() {
+			return file, idx, true
+		}
+	}
+
+	return "", "", false
+}
+
+func (h *WebHandler) passthru(w http.ResponseWriter, r *http.Request, s webSettings) {
+	if !s.passthru.Enabled || h.App == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	h.App.ServeHTTP(w, r)
+}
+
+func (h *WebHandler) serveFile(w http.ResponseWriter, r *http.Request, name string, s webSettings) {
+	f, err := os.Open(name)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	for k, v := range s.headers {
+		w.Header().Set(k, v)
+	}
+
+	if s.expires > 0 {
+		w.Header().Set("Cache-Control", "max-age="+strconv.FormatInt(int64(s.expires/time.Second), 10))
+		w.Header().Set("Expires", h.now().Add(s.expires).UTC().Format(http.TimeFormat))
+	}
+
+	http.ServeContent(w, r, fi.Name(), fi.ModTime(), f)
+}
@@ -0,0 +1,251 @@
+package middleware_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/demosdemon/pshgo"
+	. "github.com/demosdemon/pshgo/cmd/serve/middleware"
+)
+
+func writeWebDir(tb testing.TB, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "pshgo")
+	require.NoError(tb, err)
+
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(tb, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(tb, ioutil.WriteFile(path, []byte(data), 0644))
+	}
+
+	return dir
+}
+
+func testWebDir(t *testing.T) string {
+	return writeWebDir(t, map[string]string{
+		"public/index.html":     "home",
+		"public/style.css":      "body {}",
+		"public/secret.txt":     "secret",
+		"public/docs/index.htm": "docs",
+		"public/app.php":        "<?php",
+		"assets/img/logo.png":   "png",
+	})
+}
+
+func serveWeb(h http.Handler, target string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", target, nil))
+	return w
+}
+
+func TestWebHandler(t *testing.T) {
+	dir := testWebDir(t)
+	defer os.RemoveAll(dir)
+
+	web := pshgo.Web{
+		Locations: pshgo.WebLocations{
+			"/": {
+				Root:     "public",
+				Index:    []string{"index.html", "index.htm"},
+				Passthru: pshgo.Passthru{Enabled: true, Path: "/app.php"},
+				Scripts:  true,
+				Allow:    true,
+				Expires:  pshgo.Duration{Duration: time.Hour},
+				Headers:  pshgo.StringMap{"X-Frame-Options": "DENY"},
+				Rules: pshgo.WebRules{
+					`\.txt$`: {Allow: false},
+					`\.css$`: {Allow: true, Expires: pshgo.Duration{Duration: 24 * time.Hour}, Headers: pshgo.StringMap{"X-Rule": "css"}},
+				},
+			},
+			"/images": {
+				Root:  "assets/img",
+				Allow: true,
+			},
+			"/api": {
+				Passthru: pshgo.Passthru{Enabled: true},
+			},
+		},
+	}
+
+	app := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-App", r.URL.Path)
+		w.WriteHeader(http.StatusTeapot)
+	})
+	h := NewWebHandler(dir, web, app)
+
+	cases := []struct {
+		target  string
+		code    int
+		body    string
+		app     string
+		maxAge  string
+		headers pshgo.StringMap
+	}{
+		{target: "/", code: 200, body: "home", maxAge: "max-age=3600", headers: pshgo.StringMap{"X-Frame-Options": "DENY"}},
+		{target: "/style.css", code: 200, body: "body {}", maxAge: "max-age=86400", headers: pshgo.StringMap{"X-Frame-Options": "DENY", "X-Rule": "css"}},
+		{target: "/docs/", code: 200, body: "docs", maxAge: "max-age=3600"},
+		{target: "/secret.txt", code: 403},
+		{target: "/missing.txt", code: 404},
+		{target: "/app.php", code: 418, app: "/app.php"},
+		{target: "/blog/post", code: 418, app: "/blog/post"},
+		{target: "/images/logo.png", code: 200, body: "png"},
+		{target: "/images/../secret.txt", code: 403},
+		{target: "/images/missing.png", code: 404},
+		{target: "/api/users", code: 418, app: "/api/users"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.target, func(t *testing.T) {
+			w := serveWeb(h, tc.target)
+			assert.Equal(t, tc.code, w.Code)
+			assert.Equal(t, tc.app, w.Header().Get("X-App"))
+			assert.Equal(t, tc.maxAge, w.Header().Get("Cache-Control"))
+			if tc.body != "" {
+				assert.Equal(t, tc.body, w.Body.String())
+			}
+			for k, v := range tc.headers {
+				assert.Equal(t, v, w.Header().Get(k))
+			}
+		})
+	}
+}
+
+func TestWebHandler_Traversal(t *testing.T) {
+	dir := writeWebDir(t, map[string]string{
+		".env":              "SECRET=1",
+		"assets/logo.png":   "png",
+		"public/index.html": "home",
+	})
+	defer os.RemoveAll(dir)
+
+	h := NewWebHandler(dir, pshgo.Web{
+		Locations: pshgo.WebLocations{
+			"/":       {Root: "public", Allow: true},
+			"/assets": {Root: "assets", Allow: true},
+		},
+	}, nil)
+
+	assert.Equal(t, 200, serveWeb(h, "/assets/logo.png").Code)
+
+	for _, target := range []string{"/assets../.env", "/assets/../.env", "/assets..%2f.env", "/assets/..%2f..%2f.env"} {
+		w := serveWeb(h, target)
+		assert.Equal(t, 404, w.Code, target)
+		assert.NotContains(t, w.Body.String(), "SECRET", target)
+	}
+}
+
+func TestWebHandler_Deprecated(t *testing.T) {
+	dir := testWebDir(t)
+	defer os.RemoveAll(dir)
+
+	root, passthru := "/public", "/app.php"
+	web := pshgo.Web{
+		DocumentRoot: &root,
+		Passthru:     &passthru,
+		IndexFiles:   []string{"index.html"},
+		Whitelist:    []string{`\.(css|html)$`},
+	}
+
+	app := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	h := NewWebHandler(dir, web, app)
+
+	assert.Equal(t, 200, serveWeb(h, "/style.css").Code)
+	assert.Equal(t, 200, serveWeb(h, "/").Code)
+	assert.Equal(t, 403, serveWeb(h, "/secret.txt").Code)
+	assert.Equal(t, 418, serveWeb(h, "/missing").Code)
+
+	web = pshgo.Web{DocumentRoot: &root, Blacklist: []string{`\.txt$`}}
+	h = NewWebHandler(dir, web, nil)
+	assert.Equal(t, 200, serveWeb(h, "/style.css").Code)
+	assert.Equal(t, 403, serveWeb(h, "/secret.txt").Code)
+	assert.Equal(t, 404, serveWeb(h, "/missing").Code)
+
+	data, err := ioutil.ReadAll(serveWeb(h, "/style.css").Body)
+	assert.NoError(t, err)
+	assert.Equal(t, "body {}", string(data))
+}
+
+func TestWebHandler_RuleDefaults(t *testing.T) {
+	dir := writeWebDir(t, map[string]string{
+		"public/admin/x.php":      "<?php echo 'secret';",
+		"public/admin/readme.txt": "readme",
+	})
+	defer os.RemoveAll(dir)
+
+	const data = `name: app
+type: php:7.3
+web:
+  locations:
+    /:
+      root: public
+      scripts: true
+      passthru: /index.php
+      rules:
+        '^/admin/':
+          expires: 1h
+`
+
+	cfg, err := pshgo.ParseApplicationConfig("app.yaml", []byte(data))
+	require.NoError(t, err)
+
+	rule := cfg.Web.Locations["/"].Rules["^/admin/"]
+	assert.Equal(t, pshgo.WebRule{
+		Expires:  pshgo.Duration{Duration: time.Hour},
+		Passthru: pshgo.Passthru{Enabled: true, Path: "/index.php"},
+		Scripts:  true,
+		Allow:    true,
+	}, rule)
+
+	app := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	h := NewWebHandler(dir, cfg.Web, app)
+
+	w := serveWeb(h, "/admin/x.php")
+	assert.Equal(t, 418, w.Code)
+	assert.NotContains(t, w.Body.String(), "<?php")
+
+	assert.Equal(t, 418, serveWeb(h, "/admin/missing").Code)
+
+	w = serveWeb(h, "/admin/readme.txt")
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "max-age=3600", w.Header().Get("Cache-Control"))
+}
+
+func TestWebHandler_RulePrecedence(t *testing.T) {
+	dir := writeWebDir(t, map[string]string{
+		"public/notes.txt":     "notes",
+		"public/docs/help.txt": "help",
+	})
+	defer os.RemoveAll(dir)
+
+	web := pshgo.Web{
+		Locations: pshgo.WebLocations{
+			"/": {
+				Root:  "public",
+				Allow: true,
+				Rules: pshgo.WebRules{
+					// the longest pattern comes first
+					`^/docs/.*\.txt$`: {Allow: true},
+					// then, at the same length, the lowest
+					`\.tx.$`: {Allow: false},
+					`\.txt$`: {Allow: true},
+				},
+			},
+		},
+	}
+
+	h := NewWebHandler(dir, web, nil)
+	assert.Equal(t, 200, serveWeb(h, "/docs/help.txt").Code)
+	assert.Equal(t, 403, serveWeb(h, "/notes.txt").Code)
+}
@@ -1,8 +1,13 @@
 package env
 
 import (
+	"net/url"
+	"strconv"
+	"time"
+
 	"github.com/go-playground/lars"
 	"github.com/joho/godotenv"
+	pkgerrors "github.com/pkg/errors"
 
 	"github.com/demosdemon/pshgo"
 	"github.com/demosdemon/pshgo/cmd/serve/errors"
@@ -15,6 +20,9 @@ func init() {
 		g.Get("/export", GetExport)
 		g.Get("/application", GetApplication)
 		g.Get("/routes", GetRoutes)
+		g.Get("/routes/resolve", ResolveRoute)
+		g.Get("/crons", GetCrons)
+		g.Get("/workers", GetWorkers)
 	})
 }
 
@@ -48,3 +56,85 @@ func GetRoutes(c *server.Context) error {
 	}
 	return c.JSON(200, routes)
 }
+
+// ResolveRoute returns the URL of ?path=&query= on the route with the id, or
+// served by the upstream, ?target=.
+func ResolveRoute(c *server.Context) error {
+	params := c.Request().URL.Query()
+
+	target := params.Get("target")
+	if target == "" {
+		return errors.BadRequest("missing target", nil)
+	}
+
+	query, err := url.ParseQuery(params.Get("query"))
+	if err != nil {
+		return errors.BadRequest("invalid query", err)
+	}
+
+	u, err := c.GetRoutes().URLFor(target, params.Get("path"), query)
+	switch pkgerrors.Cause(err) {
+	case nil:
+	case pshgo.ErrRouteNotFound:
+		return errors.NotFound("route not found", err)
+	default:
+		return errors.UnprocessableEntity("unable to resolve route", err)
+	}
+
+	return c.JSON(200, map[string]string{"url": u.String()})
+}
+
+// MaxCronRuns is the most upcoming runs GetCrons lists for each cron.
+const MaxCronRuns = 100
+
+type cronRuns struct {
+	Spec string      `json:"spec"`
+	Cmd  string      `json:"cmd"`
+	Next []time.Time `json:"next"`
+}
+
+// GetCrons lists the next ?n= runs, 5 by default, of every cron of the
+// application, in its time zone.
+func GetCrons(c *server.Context) error {
+	app := c.GetApplication()
+	if app == nil {
+		return errors.NotFound("Not Found", nil)
+	}
+
+	n := 5
+	if v := c.Request().URL.Query().Get("n"); v != "" {
+		var err error
+		n, err = strconv.Atoi(v)
+		if err != nil || n < 1 || n > MaxCronRuns {
+			return errors.BadRequest("n must be between 1 and "+strconv.Itoa(MaxCronRuns), err)
+		}
+	}
+
+	schedules, err := app.CronSchedules()
+	if err != nil {
+		return errors.UnprocessableEntity("invalid crons", err)
+	}
+
+	crons := make(map[string]cronRuns, len(schedules))
+	for name, schedule := range schedules {
+		crons[name] = cronRuns{
+			Spec: app.Crons[name].Spec,
+			Cmd:  app.Crons[name].Cmd,
+			Next: schedule.Schedule(n),
+		}
+	}
+
+	loc, _ := app.Location()
+	return c.JSON(200, map[string]interface{}{
+		"timezone": loc.String(),
+		"crons":    crons,
+	})
+}
+
+// GetWorkers returns the status of the workers supervised by serve.
+func GetWorkers(c *server.Context) error {
+	if c.Workers == nil {
+		return errors.NotFound("Not Found", nil)
+	}
+	return c.JSON(200, c.Workers.Status())
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"net"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/go-playground/lars"
@@ -22,6 +23,10 @@ var (
 type (
 	Globals struct {
 		*pshgo.Environment
+
+		// Workers supervises the workers of the application, when serve runs
+		// them.
+		Workers *pshgo.WorkerSupervisor
 	}
 
 	Context struct {
@@ -31,6 +36,10 @@ type (
 
 	Server struct {
 		*lars.LARS
+
+		wrappers []func(http.Handler) http.Handler
+		once     sync.Once
+		handler  http.Handler
 	}
 
 	Handler = func(ctx *Context) error
@@ -54,7 +63,7 @@ func New(g *Globals) *Server {
 		middleware.Recover,
 	)
 
-	configurators.Configure(s)
+	configurators.Configure(s.LARS)
 
 	return &s
 }
@@ -68,10 +77,38 @@ func (c *Context) HandlePanic(p *cpanic.Panic) {
 	_ = c.Text(500, p.String())
 }
 
+// Wrap adds http middleware in front of the router; the first one added sees
+// the request first.
+func (s *Server) Wrap(mw ...func(http.Handler) http.Handler) {
+	s.wrappers = append(s.wrappers, mw...)
+}
+
+// Handler returns the router wrapped by the middleware added with Wrap. It is
+// built on the first call, so middleware must be added before.
+func (s *Server) Handler() http.Handler {
+	s.once.Do(func() {
+		h := s.LARS.Serve()
+		for idx := len(s.wrappers) - 1; idx >= 0; idx-- {
+			h = s.wrappers[idx](h)
+		}
+		s.handler = h
+	})
+	return s.handler
+}
+
+// Subrequests returns a handler serving requests with Handler, for the
+// sub-requests of the middleware added with Wrap, which is added before the
+// handler can be built.
+func (s *Server) Subrequests() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.Handler().ServeHTTP(w, r)
+	})
+}
+
 func (s *Server) Serve(ctx context.Context, l net.Listener) error {
 	done := make(chan error)
 
-	srv := http.Server{Handler: s.LARS.Serve()}
+	srv := http.Server{Handler: s.Handler()}
 
 	go func() {
 		done <- srv.Serve(l)
@@ -0,0 +1,356 @@
+package pshgo
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// CronSchedule is a parsed cron spec: the minutes, hours, days of the month,
+// months and days of the week it runs at, in Location.
+type CronSchedule struct {
+	Spec string
+	// Location is the time zone the spec is evaluated in; the location of
+	// the times given to Next when nil.
+	Location *time.Location
+
+	minute, hour, dom, month, dow uint64
+	// domStar and dowStar tell whether the days of the month and of the week
+	// are unrestricted: when both are restricted, a day matching either runs.
+	domStar, dowStar bool
+}
+
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+type cronField struct {
+	name     string
+	min, max int
+	names    []string
+	// hashMax is the highest value an unbounded H picks, max when zero.
+	hashMax int
+}
+
+var cronFields = []cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	// H picks a day every month has
+	{name: "day of month", min: 1, max: 31, hashMax: 28},
+	{name: "month", min: 1, max: 12, names: []string{"", "jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}},
+	// 7 is sunday, like 0
+	{name: "day of week", min: 0, max: 7, names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}, hashMax: 6},
+}
+
+// cronHorizon is how far ahead Next looks for a matching time, such as for
+// specs running on February 30 only.
+const cronHorizon = 5
+
+// ParseCronSchedule parses a cron spec: either five fields, the minute,
+// hour, day of the month, month and day of the week, or a macro such as
+// @daily. Fields are lists of values, names of months or days, ranges and
+// steps, such as */15, 1-5 or mon,wed,fri, or hashes, such as H, H/15 or
+// H(0-29), standing for a value picked by the name of the cron, here empty.
+func ParseCronSchedule(spec string) (*CronSchedule, error) {
+	return ParseNamedCronSchedule("", spec)
+}
+
+// ParseNamedCronSchedule parses the cron spec of the named cron. Its H fields
+// pick the same value every time for a given name, spreading the crons of an
+// application sharing a spec over the range of the field.
+func ParseNamedCronSchedule(name, spec string) (*CronSchedule, error) {
+	logrus.WithField("name", name).WithField("spec", spec).Trace("ParseNamedCronSchedule")
+
+	expr := strings.TrimSpace(spec)
+	if strings.HasPrefix(expr, "@") {
+		expanded, ok := cronMacros[strings.ToLower(expr)]
+		if !ok {
+			return nil, errors.Errorf("unknown cron macro %q", expr)
+		}
+		expr = expanded
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != len(cronFields) {
+		return nil, errors.Errorf("expected %d fields in cron spec %q, found %d", len(cronFields), spec, len(fields))
+	}
+
+	s := CronSchedule{Spec: spec}
+	bits := []*uint64{&s.minute, &s.hour, &s.dom, &s.month, &s.dow}
+	for idx, field := range fields {
+		v, err := cronFields[idx].parse(field, name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid cron spec %q", spec)
+		}
+		*bits[idx] = v
+	}
+
+	// sunday may be given as 7
+	if s.dow&(1<<7) != 0 {
+		s.dow = s.dow&^(1<<7) | 1
+	}
+
+	s.domStar = fields[2] == "*" || fields[2] == "?"
+	s.dowStar = fields[4] == "*" || fields[4] == "?"
+
+	return &s, nil
+}
+
+func (f cronField) parse(s, key string) (uint64, error) {
+	var rv uint64
+
+	for _, part := range strings.Split(s, ",") {
+		expr, step, stepped := part, 1, false
+		if idx := strings.Index(part, "/"); idx >= 0 {
+			n, err := strconv.Atoi(part[idx+1:])
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step %q in %s %q", part[idx+1:], f.name, part)
+			}
+			expr, step, stepped = part[:idx], n, true
+		}
+
+		lo, hi := f.min, f.max
+		switch {
+		case expr == "H" || strings.HasPrefix(expr, "H("):
+			var err error
+			if lo, hi, err = f.hashRange(expr); err != nil {
+				return 0, err
+			}
+			// H/n starts within the first step, H within the range
+			span := hi - lo + 1
+			if stepped && step < span {
+				span = step
+			}
+			lo += int(f.hash(key) % uint32(span))
+			if !stepped {
+				hi = lo
+			}
+		case expr == "*" || expr == "?":
+			if f.name == "day of week" {
+				hi = 6
+			}
+		case strings.Contains(expr, "-"):
+			idx := strings.Index(expr, "-")
+			var err error
+			if lo, err = f.value(expr[:idx]); err != nil {
+				return 0, err
+			}
+			if hi, err = f.value(expr[idx+1:]); err != nil {
+				return 0, err
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("invalid range %q in %s", expr, f.name)
+			}
+		default:
+			var err error
+			if lo, err = f.value(expr); err != nil {
+				return 0, err
+			}
+			hi = lo
+			if stepped {
+				// a/n runs from a to the end
+				hi = f.max
+			}
+		}
+
+		for v := lo; v <= hi; v += step {
+			rv |= 1 << uint(v)
+		}
+	}
+
+	return rv, nil
+}
+
+// hashRange returns the range of an H expression: the bounds of H(lo-hi), or
+// those of the field.
+func (f cronField) hashRange(expr string) (int, int, error) {
+	if expr == "H" {
+		if f.hashMax != 0 {
+			return f.min, f.hashMax, nil
+		}
+		return f.min, f.max, nil
+	}
+
+	inner := strings.TrimSuffix(strings.TrimPrefix(expr, "H("), ")")
+	idx := strings.Index(inner, "-")
+	if !strings.HasSuffix(expr, ")") || idx < 0 {
+		return 0, 0, fmt.Errorf("invalid hash %q in %s", expr, f.name)
+	}
+
+	lo, err := f.value(inner[:idx])
+	if err != nil {
+		return 0, 0, err
+	}
+	hi, err := f.value(inner[idx+1:])
+	if err != nil {
+		return 0, 0, err
+	}
+	if lo > hi {
+		return 0, 0, fmt.Errorf("invalid range %q in %s", expr, f.name)
+	}
+	return lo, hi, nil
+}
+
+// hash returns the hash of key for the field, so that the fields of a spec
+// pick unrelated values.
+func (f cronField) hash(key string) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(f.name))
+	return h.Sum32()
+}
+
+func (f cronField) value(s string) (int, error) {
+	for idx, name := range f.names {
+		if name != "" && strings.EqualFold(s, name) {
+			return idx, nil
+		}
+	}
+
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q", f.name, s)
+	}
+	if v < f.min || v > f.max {
+		return 0, fmt.Errorf("%s %d out of range %d-%d", f.name, v, f.min, f.max)
+	}
+	return v, nil
+}
+
+func (s *CronSchedule) String() string {
+	return s.Spec
+}
+
+// Next returns the first time after the given one the schedule runs at, or
+// the zero time when it never runs. Wall clock times skipped by a change of
+// the offset of the location never match, and repeated ones match each time.
+func (s *CronSchedule) Next(after time.Time) time.Time {
+	loc := s.Location
+	if loc == nil {
+		loc = after.Location()
+	}
+
+	t := after.In(loc).Truncate(time.Minute).Add(time.Minute)
+	limit := t.Year() + cronHorizon
+
+	for t.Year() <= limit {
+		var next time.Time
+		switch {
+		case s.month&(1<<uint(t.Month())) == 0:
+			next = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+		case !s.matchDay(t):
+			next = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+		case s.hour&(1<<uint(t.Hour())) == 0:
+			next = cronNextHour(t)
+		case s.minute&(1<<uint(t.Minute())) == 0:
+			next = t.Add(time.Minute)
+		default:
+			return t
+		}
+
+		// time.Date can go back around a change of the offset, unlike
+		// stepping hours as elapsed time
+		if !next.After(t) {
+			next = cronNextHour(t)
+		}
+		t = next
+	}
+
+	return time.Time{}
+}
+
+// cronNextHour returns the start of the hour after the one of t.
+func cronNextHour(t time.Time) time.Time {
+	return t.Add(time.Duration(60-t.Minute()) * time.Minute)
+}
+
+func (s *CronSchedule) matchDay(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+
+	if s.domStar || s.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// Schedule returns the next n times the schedule runs at.
+func (s *CronSchedule) Schedule(n int) []time.Time {
+	return s.ScheduleAfter(time.Now(), n)
+}
+
+// ScheduleAfter returns the n times the schedule runs at after the given one.
+func (s *CronSchedule) ScheduleAfter(after time.Time, n int) []time.Time {
+	rv := make([]time.Time, 0, n)
+	for len(rv) < n {
+		after = s.Next(after)
+		if after.IsZero() {
+			break
+		}
+		rv = append(rv, after)
+	}
+	return rv
+}
+
+// Location returns the time zone of the application, UTC unless configured.
+func (a ApplicationBase) Location() (*time.Location, error) {
+	if a.Timezone == "" {
+		return time.UTC, nil
+	}
+
+	loc, err := time.LoadLocation(a.Timezone)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unknown timezone %q", a.Timezone)
+	}
+	return loc, nil
+}
+
+// CronSchedules returns the schedules of the crons of the application, in
+// its time zone.
+func (a Application) CronSchedules() (map[string]*CronSchedule, error) {
+	loc, err := a.Location()
+	if err != nil {
+		return nil, err
+	}
+
+	var errs error
+	rv := make(map[string]*CronSchedule, len(a.Crons))
+	for _, name := range a.Crons.names() {
+		s, err := ParseNamedCronSchedule(name, a.Crons[name].Spec)
+		if err != nil {
+			errs = multierror.Append(errs, errors.Wrapf(err, "cron %s", name))
+			continue
+		}
+		s.Location = loc
+		rv[name] = s
+	}
+
+	if errs != nil {
+		return nil, errs
+	}
+
+	return rv, nil
+}
+
+func (c Crons) names() []string {
+	rv := make([]string, 0, len(c))
+	for name := range c {
+		rv = append(rv, name)
+	}
+	sort.Strings(rv)
+	return rv
+}
@@ -0,0 +1,219 @@
+package pshgo
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultCronTimeout is how long a cron may run by default, as on the
+// platform.
+const DefaultCronTimeout = 24 * time.Hour
+
+// ErrCronRunning is returned when a cron is started while it still runs.
+var ErrCronRunning = errors.New("cron is already running")
+
+// CronRunner runs the commands of crons on their schedules through the
+// shell, the way the platform does. A cron never runs twice at the same time:
+// runs due while the previous one is still going are skipped.
+type CronRunner struct {
+	Crons     Crons
+	Schedules map[string]*CronSchedule
+
+	// Shell runs the commands, as Shell -c Cmd.
+	Shell string
+	// Dir is the working directory of the commands.
+	Dir string
+	// Env is the environment of the commands; that of the process when nil.
+	Env []string
+	// Timeout is how long a command may run before it is killed; commands
+	// run until they exit when it is zero.
+	Timeout time.Duration
+
+	Stdout io.Writer
+	Stderr io.Writer
+
+	mu      sync.Mutex
+	running map[string]bool
+}
+
+// CronResult describes a run of a cron.
+type CronResult struct {
+	Name     string
+	Start    time.Time
+	Duration time.Duration
+	// ExitStatus is the exit status of the command, or -1 when it did not
+	// exit by itself.
+	ExitStatus int
+	Err        error
+}
+
+// NewCronRunner returns the runner of the crons of app, scheduled in its time
+// zone.
+func NewCronRunner(app Application) (*CronRunner, error) {
+	schedules, err := app.CronSchedules()
+	if err != nil {
+		return nil, err
+	}
+
+	return &CronRunner{
+		Crons:     app.Crons,
+		Schedules: schedules,
+		Shell:     "/bin/sh",
+		Timeout:   DefaultCronTimeout,
+		Stdout:    os.Stdout,
+		Stderr:    os.Stderr,
+	}, nil
+}
+
+// Start runs every cron on its schedule until ctx is done, then waits for the
+// runs in progress to be stopped.
+func (r *CronRunner) Start(ctx context.Context) {
+	var wg sync.WaitGroup
+
+	for _, name := range r.Crons.names() {
+		schedule, ok := r.Schedules[name]
+		if !ok {
+			continue
+		}
+
+		wg.Add(1)
+		go func(name string, schedule *CronSchedule) {
+			defer wg.Done()
+			r.schedule(ctx, &wg, name, schedule)
+		}(name, schedule)
+	}
+
+	wg.Wait()
+}
+
+func (r *CronRunner) schedule(ctx context.Context, wg *sync.WaitGroup, name string, schedule *CronSchedule) {
+	log := logrus.WithField("cron", name).WithField("spec", schedule.Spec)
+
+	for {
+		now := time.Now()
+		next := schedule.Next(now)
+		if next.IsZero() {
+			log.Warn("cron never runs")
+			return
+		}
+		if !next.After(now) {
+			// never run twice in the same minute, whatever the schedule says
+			log.WithField("next", next).Warn("cron scheduled in the past")
+			next = now.Truncate(time.Minute).Add(time.Minute)
+		}
+		log.WithField("next", next).Debug("cron scheduled")
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := r.Run(ctx, name); err == ErrCronRunning {
+				log.Warn("skipping cron: the previous run is still running")
+			}
+		}()
+	}
+}
+
+// Run runs the named cron once, waiting for its command to exit. The error
+// is ErrCronRunning when the cron is already running; the failures of the
+// command are reported by the result.
+func (r *CronRunner) Run(ctx context.Context, name string) (CronResult, error) {
+	cron, ok := r.Crons[name]
+	if !ok {
+		return CronResult{}, errors.Errorf("unknown cron %q", name)
+	}
+
+	r.mu.Lock()
+	if r.running == nil {
+		r.running = make(map[string]bool)
+	}
+	if r.running[name] {
+		r.mu.Unlock()
+		return CronResult{}, ErrCronRunning
+	}
+	r.running[name] = true
+	r.mu.Unlock()
+
+	defer func() {
+		r.mu.Lock()
+		delete(r.running, name)
+		r.mu.Unlock()
+	}()
+
+	if r.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.Timeout)
+		defer cancel()
+	}
+
+	log := logrus.WithField("cron", name).WithField("cmd", cron.Cmd)
+	log.Info("cron started")
+
+	cmd := exec.Command(r.Shell, "-c", cron.Cmd)
+	cmd.Dir = r.Dir
+	cmd.Env = r.Env
+	cmd.Stdout = r.Stdout
+	cmd.Stderr = r.Stderr
+	setProcessGroup(cmd)
+
+	res := CronResult{Name: name, Start: time.Now()}
+	err := runCommand(ctx, cmd)
+	res.Duration = time.Since(res.Start)
+	res.ExitStatus = -1
+	if cmd.ProcessState != nil {
+		res.ExitStatus = cmd.ProcessState.ExitCode()
+	}
+
+	if ctx.Err() == context.DeadlineExceeded {
+		err = errors.Errorf("timed out after %s", r.Timeout)
+	}
+	res.Err = err
+
+	log = log.WithFields(logrus.Fields{
+		"exit_status": res.ExitStatus,
+		"duration":    res.Duration.String(),
+	})
+	if err != nil {
+		log.WithError(err).Warn("cron failed")
+	} else {
+		log.Info("cron finished")
+	}
+
+	return res, nil
+}
+
+// runCommand runs cmd, killing it along with the processes it started once
+// ctx is done.
+func runCommand(ctx context.Context, cmd *exec.Cmd) error {
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = signalProcessGroup(cmd, syscall.SIGKILL)
+		case <-done:
+		}
+	}()
+
+	return cmd.Wait()
+}
@@ -0,0 +1,108 @@
+package pshgo_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	. "github.com/demosdemon/pshgo"
+)
+
+func testCronRunner(t *testing.T, crons Crons) (*CronRunner, *bytes.Buffer) {
+	runner, err := NewCronRunner(Application{Crons: crons})
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	runner.Stdout = &out
+	runner.Stderr = &out
+	return runner, &out
+}
+
+func TestCronRunner_Run(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pshgo-crons")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	runner, out := testCronRunner(t, Crons{
+		"hello": {Spec: "@daily", Cmd: `echo "hello $GREETING from $(pwd)"`},
+		"fail":  {Spec: "@daily", Cmd: "echo oops >&2; exit 3"},
+		"slow":  {Spec: "@daily", Cmd: "sleep 5"},
+	})
+	runner.Dir = dir
+	runner.Env = []string{"GREETING=world"}
+
+	res, err := runner.Run(context.Background(), "hello")
+	require.NoError(t, err)
+	assert.Equal(t, "hello", res.Name)
+	assert.Equal(t, 0, res.ExitStatus)
+	assert.NoError(t, res.Err)
+	assert.Contains(t, out.String(), "hello world from ")
+
+	out.Reset()
+	res, err = runner.Run(context.Background(), "fail")
+	require.NoError(t, err)
+	assert.Equal(t, 3, res.ExitStatus)
+	assert.Error(t, res.Err)
+	assert.Equal(t, "oops\n", out.String())
+
+	runner.Timeout = 100 * time.Millisecond
+	res, err = runner.Run(context.Background(), "slow")
+	require.NoError(t, err)
+	assert.Equal(t, -1, res.ExitStatus)
+	assert.EqualError(t, res.Err, "timed out after 100ms")
+	assert.True(t, res.Duration < 5*time.Second)
+
+	_, err = runner.Run(context.Background(), "missing")
+	assert.EqualError(t, err, `unknown cron "missing"`)
+}
+
+func TestCronRunner_Overlap(t *testing.T) {
+	runner, _ := testCronRunner(t, Crons{
+		"slow": {Spec: "@daily", Cmd: "sleep 1"},
+	})
+
+	done := make(chan CronResult)
+	go func() {
+		res, err := runner.Run(context.Background(), "slow")
+		assert.NoError(t, err)
+		done <- res
+	}()
+
+	// wait for the first run to start
+	time.Sleep(200 * time.Millisecond)
+	_, err := runner.Run(context.Background(), "slow")
+	assert.Equal(t, ErrCronRunning, err)
+
+	res := <-done
+	assert.Equal(t, 0, res.ExitStatus)
+
+	res, err = runner.Run(context.Background(), "slow")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, res.ExitStatus)
+}
+
+func TestCronRunner_Start(t *testing.T) {
+	runner, _ := testCronRunner(t, Crons{
+		"hourly": {Spec: "@hourly", Cmd: "true"},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		runner.Start(ctx)
+		close(done)
+	}()
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Start did not return once its context was done")
+	}
+}
@@ -0,0 +1,206 @@
+package pshgo_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	. "github.com/demosdemon/pshgo"
+)
+
+func TestParseCronSchedule(t *testing.T) {
+	valid := []string{
+		"* * * * *",
+		"*/5 * * * *",
+		"0 0 * * *",
+		"@daily",
+		"@HOURLY",
+		"15,45 8-18/2 1,15 jan-jun MON-FRI",
+		"0 12 * * 7",
+		"5/10 * * * ?",
+		"H * * * *",
+		"H/15 H(9-17) * * H",
+	}
+
+	for _, spec := range valid {
+		s, err := ParseCronSchedule(spec)
+		if assert.NoError(t, err, spec) {
+			assert.Equal(t, spec, s.String())
+		}
+	}
+
+	invalid := map[string]string{
+		"":                "expected 5 fields in cron spec \"\", found 0",
+		"* * * *":         "expected 5 fields in cron spec \"* * * *\", found 4",
+		"@reboot":         "unknown cron macro \"@reboot\"",
+		"60 * * * *":      "invalid cron spec \"60 * * * *\": minute 60 out of range 0-59",
+		"* 24 * * *":      "invalid cron spec \"* 24 * * *\": hour 24 out of range 0-23",
+		"* * 0 * *":       "invalid cron spec \"* * 0 * *\": day of month 0 out of range 1-31",
+		"* * * foo * ":    "invalid cron spec \"* * * foo * \": invalid month \"foo\"",
+		"* * * * 5-1":     "invalid cron spec \"* * * * 5-1\": invalid range \"5-1\" in day of week",
+		"*/0 * * * *":     "invalid cron spec \"*/0 * * * *\": invalid step \"0\" in minute \"*/0\"",
+		"* * * * * * cmd": "expected 5 fields in cron spec \"* * * * * * cmd\", found 7",
+		"H(5) * * * *":    "invalid cron spec \"H(5) * * * *\": invalid hash \"H(5)\" in minute",
+		"* H(9-30) * * *": "invalid cron spec \"* H(9-30) * * *\": hour 30 out of range 0-23",
+		"* H(9-1) * * *":  "invalid cron spec \"* H(9-1) * * *\": invalid range \"H(9-1)\" in hour",
+	}
+
+	for spec, msg := range invalid {
+		_, err := ParseCronSchedule(spec)
+		assert.EqualError(t, err, msg, spec)
+	}
+}
+
+func TestParseNamedCronSchedule(t *testing.T) {
+	start := time.Date(2019, 6, 14, 10, 7, 30, 0, time.UTC)
+
+	runs := func(name, spec string, n int) []time.Time {
+		s, err := ParseNamedCronSchedule(name, spec)
+		require.NoError(t, err, spec)
+		return s.ScheduleAfter(start, n)
+	}
+
+	// the same name always picks the same time
+	assert.Equal(t, runs("backup", "H H * * *", 3), runs("backup", "H H * * *", 3))
+	assert.NotEqual(t, runs("backup", "H H * * *", 3), runs("report", "H H * * *", 3))
+
+	for _, name := range []string{"backup", "report", "digest", "cleanup"} {
+		daily := runs(name, "H H(2-4) * * *", 3)
+		require.Len(t, daily, 3, name)
+		for idx, next := range daily {
+			assert.True(t, next.Hour() >= 2 && next.Hour() <= 4, "%s runs at %s", name, next)
+			if idx > 0 {
+				assert.Equal(t, 24*time.Hour, next.Sub(daily[idx-1]), name)
+			}
+		}
+
+		quarters := runs(name, "H/15 * * * *", 8)
+		for idx, next := range quarters {
+			assert.True(t, next.Minute()%15 == quarters[0].Minute()%15, "%s runs at %s", name, next)
+			if idx > 0 {
+				assert.Equal(t, 15*time.Minute, next.Sub(quarters[idx-1]), name)
+			}
+		}
+
+		monthly := runs(name, "0 0 H * *", 2)
+		require.Len(t, monthly, 2, name)
+		assert.True(t, monthly[0].Day() <= 28, "%s runs at %s", name, monthly[0])
+		assert.Equal(t, monthly[0].Day(), monthly[1].Day(), name)
+	}
+}
+
+func TestCronSchedule_Next(t *testing.T) {
+	start := time.Date(2019, 6, 14, 10, 7, 30, 0, time.UTC) // a friday
+
+	cases := []struct {
+		spec     string
+		expected []string
+	}{
+		{"* * * * *", []string{"2019-06-14T10:08:00Z", "2019-06-14T10:09:00Z"}},
+		{"*/20 * * * *", []string{"2019-06-14T10:20:00Z", "2019-06-14T10:40:00Z", "2019-06-14T11:00:00Z"}},
+		{"@hourly", []string{"2019-06-14T11:00:00Z", "2019-06-14T12:00:00Z"}},
+		{"@daily", []string{"2019-06-15T00:00:00Z", "2019-06-16T00:00:00Z"}},
+		{"@weekly", []string{"2019-06-16T00:00:00Z", "2019-06-23T00:00:00Z"}},
+		{"@monthly", []string{"2019-07-01T00:00:00Z", "2019-08-01T00:00:00Z"}},
+		{"@yearly", []string{"2020-01-01T00:00:00Z", "2021-01-01T00:00:00Z"}},
+		{"30 9 * * mon-fri", []string{"2019-06-17T09:30:00Z", "2019-06-18T09:30:00Z"}},
+		{"0 12 * * 7", []string{"2019-06-16T12:00:00Z", "2019-06-23T12:00:00Z"}},
+		// both days restricted: either runs
+		{"0 0 1 * sat", []string{"2019-06-15T00:00:00Z", "2019-06-22T00:00:00Z", "2019-06-29T00:00:00Z", "2019-07-01T00:00:00Z"}},
+		{"0 0 29 2 *", []string{"2020-02-29T00:00:00Z", "2024-02-29T00:00:00Z"}},
+		{"0 0 30 2 *", []string{}},
+	}
+
+	for _, c := range cases {
+		s, err := ParseCronSchedule(c.spec)
+		require.NoError(t, err, c.spec)
+
+		actual := []string{}
+		for _, next := range s.ScheduleAfter(start, len(c.expected)+1)[:len(c.expected)] {
+			actual = append(actual, next.Format(time.RFC3339))
+		}
+		assert.Equal(t, c.expected, actual, c.spec)
+	}
+}
+
+func TestCronSchedule_DaylightSaving(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	// 2026-03-08 02:00 EST is skipped to 03:00 EDT and 2026-11-01 02:00 EDT
+	// is repeated as 01:00 EST
+	cases := []struct {
+		spec     string
+		start    time.Time
+		expected []string
+	}{
+		{"30 2 * * *", time.Date(2026, 3, 7, 12, 0, 0, 0, loc), []string{"2026-03-09T02:30:00-04:00", "2026-03-10T02:30:00-04:00"}},
+		{"* * * * *", time.Date(2026, 3, 8, 1, 59, 0, 0, loc), []string{"2026-03-08T03:00:00-04:00", "2026-03-08T03:01:00-04:00"}},
+		{"0 * * * *", time.Date(2026, 3, 8, 0, 30, 0, 0, loc), []string{"2026-03-08T01:00:00-05:00", "2026-03-08T03:00:00-04:00"}},
+		{"30 1 * * *", time.Date(2026, 11, 1, 6, 30, 0, 0, time.UTC), []string{"2026-11-02T01:30:00-05:00", "2026-11-03T01:30:00-05:00"}},
+		{"* * * * *", time.Date(2026, 11, 1, 6, 0, 0, 0, time.UTC), []string{"2026-11-01T01:01:00-05:00", "2026-11-01T01:02:00-05:00"}},
+		{"0 * * * *", time.Date(2026, 11, 1, 0, 30, 0, 0, loc), []string{"2026-11-01T01:00:00-04:00", "2026-11-01T01:00:00-05:00", "2026-11-01T02:00:00-05:00"}},
+	}
+
+	for _, c := range cases {
+		s, err := ParseCronSchedule(c.spec)
+		require.NoError(t, err, c.spec)
+		s.Location = loc
+
+		actual := []string{}
+		after := c.start
+		for range c.expected {
+			next := s.Next(after)
+			require.True(t, next.After(after), "%s: %s is not after %s", c.spec, next, after)
+			actual = append(actual, next.Format(time.RFC3339))
+			after = next
+		}
+		assert.Equal(t, c.expected, actual, c.spec)
+	}
+}
+
+func TestCronSchedule_Location(t *testing.T) {
+	app := Application{
+		ApplicationCore: ApplicationCore{
+			ApplicationBase: ApplicationBase{Timezone: "Europe/Paris"},
+		},
+		Crons: Crons{
+			"report": {Spec: "0 9 * * *", Cmd: "./report"},
+		},
+	}
+
+	schedules, err := app.CronSchedules()
+	require.NoError(t, err)
+
+	s := schedules["report"]
+	require.NotNil(t, s)
+
+	start := time.Date(2019, 3, 30, 12, 0, 0, 0, time.UTC)
+	var actual []string
+	for _, next := range s.ScheduleAfter(start, 2) {
+		actual = append(actual, next.UTC().Format(time.RFC3339))
+	}
+	// daylight saving time starts on 2019-03-31 in Paris
+	assert.Equal(t, []string{"2019-03-31T07:00:00Z", "2019-04-01T07:00:00Z"}, actual)
+	assert.Equal(t, "Europe/Paris", s.Next(start).Location().String())
+
+	app.Crons["hashed"] = Cron{Spec: "H H * * *"}
+	schedules, err = app.CronSchedules()
+	require.NoError(t, err)
+	hashed, err := ParseNamedCronSchedule("hashed", "H H * * *")
+	require.NoError(t, err)
+	hashed.Location = s.Location
+	assert.Equal(t, hashed.ScheduleAfter(start, 2), schedules["hashed"].ScheduleAfter(start, 2))
+
+	app.Crons["broken"] = Cron{Spec: "@sometimes"}
+	_, err = app.CronSchedules()
+	require.IsType(t, &multierror.Error{}, err)
+	assert.EqualError(t, err.(*multierror.Error).Errors[0], `cron broken: unknown cron macro "@sometimes"`)
+
+	app.Timezone = "Mars/Olympus"
+	_, err = app.CronSchedules()
+	assert.Error(t, err)
+}
@@ -1,4 +1,6 @@
-//go:generate go run ./internal/gen -output generated.go
+//go:generate go run ./cmd/pshgo-gen -schema schema.yaml -local github.com/demosdemon -path generated.go -write
+//go:generate go run ./cmd/pshgo-gen -schema schema.yaml -target typescript -types . -path typescript/pshgo.ts -write
+//go:generate go run ./cmd/pshgo-gen -schema schema.yaml -target jsonschema -types . -path jsonschema/pshgo.schema.json -write
 
 // pshgo provides strongly typed models for the Platform.sh environment
 package pshgo
@@ -25,6 +25,22 @@ func NewEnvironmentWithProvider(prefix string, p Provider) *Environment {
 	}
 }
 
+// NewDotEnvEnvironment returns the environment of the process, the variables
+// of the .env file at path taking precedence.
+func NewDotEnvEnvironment(prefix, path string) (*Environment, error) {
+	dotenv, err := ReadDotEnv(path)
+	if err != nil {
+		return nil, err
+	}
+
+	environ := LayeredProvider{
+		dotenv,
+		DefaultProvider,
+	}
+
+	return NewEnvironmentWithProvider(prefix, environ), nil
+}
+
 func (e *Environment) Lookup(key string) (string, bool) {
 	return e.provider().Lookup(key)
 }
@@ -84,3 +100,19 @@ func (e *Environment) Variable(key string) (interface{}, bool) {
 	}
 	return nil, false
 }
+
+// LookupVariable returns the PLATFORM_VARIABLES entry with the given key.
+// Values that are not strings are returned as JSON.
+func LookupVariable(p PlatformProvider, key string) (string, bool) {
+	vars, ok := LookupVariables(p)
+	if !ok {
+		return "", false
+	}
+
+	v, ok := vars[key]
+	if !ok {
+		return "", false
+	}
+
+	return variableString(v), true
+}
@@ -0,0 +1,234 @@
+package gen
+
+import (
+	"fmt"
+
+	. "github.com/dave/jennifer/jen"
+	"github.com/iancoleman/strcase"
+)
+
+const syncPkg = "sync"
+
+// accessorNames returns the names of the accessor interface and its fake.
+func (s Schema) accessorNames() (iface, fake string) {
+	return s.Accessor, "Fake" + s.Accessor
+}
+
+// renderAccessor renders an interface listing every LookupX and GetX method
+// of the receiver along with a fake implementation for tests. Nothing is
+// rendered unless the schema names both the receiver and the accessor.
+func (s Schema) renderAccessor(g *Group) {
+	if s.Receiver == "" || s.Accessor == "" {
+		return
+	}
+
+	iface, fake := s.accessorNames()
+
+	/*
+		type PlatformAccessor interface {
+			LookupApplication() (*Application, bool)
+			GetApplication() *Application
+			...
+		}
+	*/
+	g.Comment(fmt.Sprintf("%s lists the generated accessors of %s so code can", iface, s.Receiver))
+	g.Comment(fmt.Sprintf("depend on an interface and be tested with %s.", fake))
+	g.Type().Id(iface).InterfaceFunc(func(g *Group) {
+		for _, v := range s.Variables {
+			rType := v.returnType()
+			for _, name := range append([]string{v.Name}, v.Aliases...) {
+				g.Id("Lookup"+strcase.ToCamel(name)).Params().Params(rType, Bool())
+				g.Id("Get" + strcase.ToCamel(name)).Params().Add(rType)
+			}
+		}
+	})
+
+	/*
+		type FakePlatformAccessor struct {
+			Provider PlatformProvider
+
+			LookupApplicationFunc func() (*Application, bool)
+			GetApplicationFunc    func() *Application
+			...
+
+			mu    sync.Mutex
+			calls []string
+		}
+	*/
+	g.Comment(fmt.Sprintf("%s implements %s for tests.", fake, iface))
+	g.Comment("")
+	g.Comment("Each method calls its override when set, then the LookupX override for")
+	g.Comment("GetX methods, then reads Provider when set, and otherwise returns the zero")
+	g.Comment("value. Aliases share the overrides of the variable they alias. Every call")
+	g.Comment("is recorded.")
+	g.Type().Id(fake).StructFunc(func(g *Group) {
+		g.Id("Provider").Qual(pshgoPkg, "PlatformProvider")
+		g.Line()
+		for _, v := range s.Variables {
+			rType := v.returnType()
+			camel := strcase.ToCamel(v.Name)
+			g.Id("Lookup"+camel+"Func").Func().Params().Params(rType, Bool())
+			g.Id("Get" + camel + "Func").Func().Params().Add(rType)
+		}
+		g.Line()
+		g.Id("mu").Qual(syncPkg, "Mutex")
+		g.Id("calls").Index().String()
+	})
+
+	g.Var().Defs(
+		Id("_").Id(iface).Op("=").Parens(Op("*").Id(s.Receiver)).Call(Nil()),
+		Id("_").Id(iface).Op("=").Parens(Op("*").Id(fake)).Call(Nil()),
+	)
+
+	recv := Id("f").Op("*").Id(fake)
+
+	/*
+		func (f *FakePlatformAccessor) record(method string) {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			f.calls = append(f.calls, method)
+		}
+	*/
+	g.Func().Params(recv).Id("record").Params(Id("method").String()).Block(
+		Id("f").Dot("mu").Dot("Lock").Call(),
+		Defer().Id("f").Dot("mu").Dot("Unlock").Call(),
+		Id("f").Dot("calls").Op("=").Append(Id("f").Dot("calls"), Id("method")),
+	).Line()
+
+	/*
+		func (f *FakePlatformAccessor) Calls() []string {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			return append([]string(nil), f.calls...)
+		}
+	*/
+	g.Comment("Calls returns the names of the methods called so far, in order.")
+	g.Func().Params(recv).Id("Calls").Params().Index().String().Block(
+		Id("f").Dot("mu").Dot("Lock").Call(),
+		Defer().Id("f").Dot("mu").Dot("Unlock").Call(),
+		Return(Append(Index().String().Call(Nil()), Id("f").Dot("calls").Op("..."))),
+	).Line()
+
+	/*
+		func (f *FakePlatformAccessor) CallCount(method string) int {
+			...
+		}
+	*/
+	g.Comment("CallCount returns how many times the named method was called.")
+	g.Func().Params(recv).Id("CallCount").Params(Id("method").String()).Int().Block(
+		Id("f").Dot("mu").Dot("Lock").Call(),
+		Defer().Id("f").Dot("mu").Dot("Unlock").Call(),
+		Var().Id("rv").Int(),
+		For(List(Id("_"), Id("call")).Op(":=").Range().Id("f").Dot("calls")).Block(
+			If(Id("call").Op("==").Id("method")).Block(
+				Id("rv").Op("++"),
+			),
+		),
+		Return(Id("rv")),
+	).Line()
+
+	/*
+		func (f *FakePlatformAccessor) Reset() {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			f.calls = nil
+		}
+	*/
+	g.Comment("Reset forgets the recorded calls.")
+	g.Func().Params(recv).Id("Reset").Params().Block(
+		Id("f").Dot("mu").Dot("Lock").Call(),
+		Defer().Id("f").Dot("mu").Dot("Unlock").Call(),
+		Id("f").Dot("calls").Op("=").Nil(),
+	).Line()
+
+	for _, v := range s.Variables {
+		v.renderFake(g, recv)
+	}
+}
+
+func (v Variable) renderFake(g *Group, recv *Statement) {
+	rType := v.returnType()
+	camel := strcase.ToCamel(v.Name)
+	lookupName, getName := "Lookup"+camel, "Get"+camel
+	lookupFunc, getFunc := Id("f").Dot(lookupName+"Func"), Id("f").Dot(getName+"Func")
+
+	/*
+		func (f *FakePlatformAccessor) LookupApplication() (*Application, bool) {
+			f.record("LookupApplication")
+			if f.LookupApplicationFunc != nil {
+				return f.LookupApplicationFunc()
+			}
+			if f.Provider != nil {
+				return LookupApplication(f.Provider)
+			}
+			var v *Application
+			return v, false
+		}
+	*/
+	g.Func().Params(recv).Id(lookupName).Params().Params(rType, Bool()).Block(
+		Id("f").Dot("record").Call(Lit(lookupName)),
+		If(lookupFunc.Clone().Op("!=").Nil()).Block(
+			Return(lookupFunc.Clone().Call()),
+		),
+		If(Id("f").Dot("Provider").Op("!=").Nil()).Block(
+			Return(Id(lookupName).Call(Id("f").Dot("Provider"))),
+		),
+		Var().Id("v").Add(rType),
+		Return(Id("v"), False()),
+	).Line()
+
+	/*
+		func (f *FakePlatformAccessor) GetApplication() *Application {
+			f.record("GetApplication")
+			if f.GetApplicationFunc != nil {
+				return f.GetApplicationFunc()
+			}
+			if f.LookupApplicationFunc != nil {
+				v, _ := f.LookupApplicationFunc()
+				return v
+			}
+			if f.Provider != nil {
+				return GetApplication(f.Provider)
+			}
+			var v *Application
+			return v
+		}
+	*/
+	g.Func().Params(recv).Id(getName).Params().Add(rType).Block(
+		Id("f").Dot("record").Call(Lit(getName)),
+		If(getFunc.Clone().Op("!=").Nil()).Block(
+			Return(getFunc.Clone().Call()),
+		),
+		If(lookupFunc.Clone().Op("!=").Nil()).Block(
+			List(Id("v"), Id("_")).Op(":=").Add(lookupFunc.Clone()).Call(),
+			Return(Id("v")),
+		),
+		If(Id("f").Dot("Provider").Op("!=").Nil()).Block(
+			Return(Id(getName).Call(Id("f").Dot("Provider"))),
+		),
+		Var().Id("v").Add(rType),
+		Return(Id("v")),
+	).Line()
+
+	for _, a := range v.Aliases {
+		lookupAlias, getAlias := "Lookup"+strcase.ToCamel(a), "Get"+strcase.ToCamel(a)
+
+		/*
+			func (f *FakePlatformAccessor) LookupApp() (*Application, bool) {
+				return f.LookupApplication()
+			}
+		*/
+		g.Func().Params(recv).Id(lookupAlias).Params().Params(rType, Bool()).Block(
+			Return(Id("f").Dot(lookupName).Call()),
+		).Line()
+
+		/*
+			func (f *FakePlatformAccessor) GetApp() *Application {
+				return f.GetApplication()
+			}
+		*/
+		g.Func().Params(recv).Id(getAlias).Params().Add(rType).Block(
+			Return(Id("f").Dot(getName).Call()),
+		).Line()
+	}
+}
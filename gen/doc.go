@@ -0,0 +1,10 @@
+// Package gen renders typed accessors for Platform.sh environment variables.
+//
+// A Schema lists enums and variables. Every variable produces a
+// LookupX(p pshgo.PlatformProvider) and GetX(p pshgo.PlatformProvider)
+// function decoding the value according to its kind, plus forwarding methods
+// when the schema names a receiver type. Schemas are usually loaded from a
+// YAML or JSON file with LoadSchema and rendered with Execute, which runs
+// goimports and goreturns on the result and either prints a diff or writes
+// the file.
+package gen
@@ -0,0 +1,52 @@
+package gen
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/iancoleman/strcase"
+)
+
+const jsonSchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// JSONSchema renders a JSON Schema document describing the decoded values of
+// the variables, with a definition for every enum and decoded type.
+type JSONSchema struct {
+	Schema *Schema
+	Types  *TypeSet
+}
+
+func (j JSONSchema) Render(w io.Writer) error {
+	sh := newShapes(j.Schema, j.Types)
+	sh.build()
+
+	root := struct {
+		Schema      string            `json:"$schema"`
+		Title       string            `json:"title"`
+		Type        string            `json:"type"`
+		Properties  map[string]*Shape `json:"properties,omitempty"`
+		Definitions map[string]*Shape `json:"definitions"`
+	}{
+		Schema:      jsonSchemaDraft,
+		Title:       j.Schema.Package,
+		Type:        "object",
+		Properties:  make(map[string]*Shape),
+		Definitions: sh.definitions,
+	}
+
+	for _, v := range j.Schema.Variables {
+		switch v.kind() {
+		case KindJSON:
+			root.Properties[strcase.ToScreamingSnake(v.Name)] = &Shape{Ref: definitionsRef + v.decodedName()}
+		case KindText:
+			if _, ok := sh.enum(v.DecodedType); ok {
+				root.Properties[strcase.ToScreamingSnake(v.Name)] = &Shape{Ref: definitionsRef + v.DecodedType}
+			}
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(root)
+}
@@ -1,4 +1,4 @@
-package main
+package gen
 
 import (
 	"bytes"
@@ -35,20 +35,27 @@ type Config struct {
 	FormatOnly        bool   `desc:"disable the insertion and deletions of imports"`
 	PrintErrors       bool   `desc:"print non-fatal typechecking errors to stderr"`
 	RemoveBareReturns bool   `desc:"remove bare returns"`
+	Schema            string `desc:"path of the YAML or JSON schema to render"`
+	Package           string `desc:"name of the generated package; overrides the schema"`
+	ImportPath        string `desc:"import path of the generated package; overrides the schema"`
+	Prefix            string `desc:"environment variable prefix used instead of the provider prefix; overrides the schema"`
+	Target            string `desc:"output to render: go, typescript or jsonschema"`
+	Types             string `desc:"directory of the Go package declaring the decoded types (typescript and jsonschema only)"`
 }
 
 func NewConfig(args []string) (*Config, error) {
 	cfg := &Config{
 		Path:      "/dev/stdout",
+		Target:    TargetGo,
+		Types:     ".",
 		Imports:   true,
 		Returns:   true,
-		Local:     "github.com/demosdemon",
 		Comments:  true,
 		TabIndent: true,
 		TabWidth:  8,
 	}
 
-	fs := flag.NewFlagSet("gen", flag.ContinueOnError)
+	fs := flag.NewFlagSet("pshgo-gen", flag.ContinueOnError)
 	must(gflag.ParseTo(cfg, fs))
 
 	err := fs.Parse(args)
@@ -62,28 +69,75 @@ func NewConfig(args []string) (*Config, error) {
 	return cfg, nil
 }
 
-func main() {
-	Execute(os.Args[1:], data)
-}
-
 func must(err error) {
 	if err != nil {
 		panic(err)
 	}
 }
 
+// Execute parses the command line arguments and renders either the schema
+// given with -schema or data.
 func Execute(args []string, data Render) {
 	cfg, err := NewConfig(args)
 	if err != nil {
 		logrus.WithError(err).Fatal()
 	}
 
+	if cfg.Schema != "" {
+		schema, err := LoadSchema(cfg.Schema)
+		if err != nil {
+			logrus.WithError(err).Fatal()
+		}
+		if err := cfg.Apply(schema); err != nil {
+			logrus.WithError(err).Fatal()
+		}
+
+		data, err = cfg.Renderer(schema)
+		if err != nil {
+			logrus.WithError(err).Fatal()
+		}
+	}
+
 	err = cfg.Execute(data)
 	if err != nil {
 		logrus.WithError(err).Fatal()
 	}
 }
 
+// Apply overrides the package and prefix of the schema with the ones given on
+// the command line and validates the result.
+func (c *Config) Apply(s *Schema) error {
+	if c.Package != "" {
+		s.Package = c.Package
+	}
+	if c.ImportPath != "" {
+		s.ImportPath = c.ImportPath
+	}
+	if c.Prefix != "" {
+		s.Prefix = c.Prefix
+	}
+	return s.Validate()
+}
+
+// Renderer returns the renderer of the -target output, loading the Go types
+// from -types when the target needs them.
+func (c *Config) Renderer(s *Schema) (Render, error) {
+	var types *TypeSet
+	if !c.isGo() {
+		var err error
+		types, err = LoadTypes(c.Types)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return s.Renderer(c.Target, types)
+}
+
+func (c *Config) isGo() bool {
+	return c.Target == "" || c.Target == TargetGo
+}
+
 func (c *Config) ImportsOptions() *imports.Options {
 	return &imports.Options{
 		Fragment:   false,
@@ -127,14 +181,14 @@ func (c *Config) Render(r Render) ([]byte, error) {
 }
 
 func (c *Config) GoImports(data []byte) ([]byte, error) {
-	if c.Imports {
+	if c.Imports && c.isGo() {
 		return imports.Process(c.Path, data, c.ImportsOptions())
 	}
 	return data, nil
 }
 
 func (c *Config) GoReturns(data []byte) ([]byte, error) {
-	if c.Returns {
+	if c.Returns && c.isGo() {
 		return returns.Process("", c.Path, data, c.ReturnsOptions())
 	}
 	return data, nil
@@ -180,6 +234,10 @@ func (c *Config) WriteFile(prev, current []byte) error {
 }
 
 func (c *Config) Execute(r Render) error {
+	if r == nil {
+		return ErrNoSchema
+	}
+
 	prev, err := c.ReadPrevious()
 	if err != nil {
 		return errors.Wrap(err, "error reading existing file")
@@ -1,4 +1,4 @@
-package main
+package gen
 
 import (
 	"bytes"
@@ -17,9 +17,10 @@ import (
 func defaultConfig(fns ...func(*Config)) *Config {
 	cfg := &Config{
 		Path:      "/dev/stdout",
+		Target:    "go",
+		Types:     ".",
 		Imports:   true,
 		Returns:   true,
-		Local:     "github.com/demosdemon",
 		Comments:  true,
 		TabIndent: true,
 		TabWidth:  8,
@@ -78,7 +79,9 @@ func captureOutput(fn func()) (stdout string, stderr string, err error) {
 	}
 	defer cancel()
 
+	done := make(chan struct{})
 	go func() {
+		defer close(done)
 		defer os.Stdout.Close()
 		defer os.Stderr.Close()
 		defer func() {
@@ -108,6 +111,8 @@ func captureOutput(fn func()) (stdout string, stderr string, err error) {
 	}()
 
 	wg.Wait()
+	// err is set by fn's goroutine
+	<-done
 
 	return stdout, stderr, err
 }
@@ -223,7 +228,7 @@ func TestNewConfig(t *testing.T) {
 			name:         "unknown-flag",
 			args:         []string{"-unknown-flag"},
 			expectErr:    true,
-			expectStderr: "flag provided but not defined: -unknown-flag\nUsage of gen:\n  -all-errors\n    \treport all errors (not just the first 10 on different lines) (default false)\n  -comments\n    \tkeep comments (default true)\n  -diff\n    \tdisplay a diff instead of rewriting files (default false)\n  -exit-code\n    \texit with a failure code if no change was detected (default false)\n  -format-only\n    \tdisable the insertion and deletions of imports (default false)\n  -imports\n    \trun goimports on the file post generation (default true)\n  -local value\n    \tput imports beginning with this string after 3rd-party packages (see goimports) (default github.com/demosdemon)\n  -no-clobber\n    \tfail to write a file if it already exists (default false)\n  -path value\n    \tpath of the generated file (default /dev/stdout)\n  -print-errors\n    \tprint non-fatal typechecking errors to stderr (default false)\n  -remove-bare-returns\n    \tremove bare returns (default false)\n  -returns\n    \trun goreturns on the file post generation (default true)\n  -tab-indent\n    \tuse tabs for indent (default true)\n  -tab-width value\n    \tset tab width (default 8)\n  -write\n    \twrite the generated file (default false)\n",
+			expectStderr: "flag provided but not defined: -unknown-flag\nUsage of pshgo-gen:\n  -all-errors\n    \treport all errors (not just the first 10 on different lines) (default false)\n  -comments\n    \tkeep comments (default true)\n  -diff\n    \tdisplay a diff instead of rewriting files (default false)\n  -exit-code\n    \texit with a failure code if no change was detected (default false)\n  -format-only\n    \tdisable the insertion and deletions of imports (default false)\n  -import-path value\n    \timport path of the generated package; overrides the schema\n  -imports\n    \trun goimports on the file post generation (default true)\n  -local value\n    \tput imports beginning with this string after 3rd-party packages (see goimports)\n  -no-clobber\n    \tfail to write a file if it already exists (default false)\n  -package value\n    \tname of the generated package; overrides the schema\n  -path value\n    \tpath of the generated file (default /dev/stdout)\n  -prefix value\n    \tenvironment variable prefix used instead of the provider prefix; overrides the schema\n  -print-errors\n    \tprint non-fatal typechecking errors to stderr (default false)\n  -remove-bare-returns\n    \tremove bare returns (default false)\n  -returns\n    \trun goreturns on the file post generation (default true)\n  -schema value\n    \tpath of the YAML or JSON schema to render\n  -tab-indent\n    \tuse tabs for indent (default true)\n  -tab-width value\n    \tset tab width (default 8)\n  -target value\n    \toutput to render: go, typescript or jsonschema (default go)\n  -types value\n    \tdirectory of the Go package declaring the decoded types (typescript and jsonschema only) (default .)\n  -write\n    \twrite the generated file (default false)\n",
 		},
 	}
 
@@ -244,12 +249,7 @@ func TestNewConfig(t *testing.T) {
 	}
 }
 
-func testMain(tb testing.TB) {
-	prevArgs := append(([]string)(nil), os.Args...)
-	defer func() {
-		os.Args = prevArgs
-	}()
-
+func testExecute(tb testing.TB) {
 	tmp, err := ioutil.TempFile("", "*.go")
 	require.NoError(tb, err)
 	require.NoError(tb, tmp.Close())
@@ -260,33 +260,38 @@ func testMain(tb testing.TB) {
 		_ = os.Remove(tmpName)
 	}()
 
-	os.Args = []string{
-		os.Args[0],
+	args := []string{
 		"-path",
 		tmpName,
 		"-write",
+		"-local",
+		"github.com/demosdemon",
+		"-schema",
+		"../schema.yaml",
 	}
 
-	stdout, stderr, err := captureOutput(main)
+	stdout, stderr, err := captureOutput(func() {
+		Execute(args, nil)
+	})
 	assert.Equal(tb, "", stdout)
 	assert.Equal(tb, "", stderr)
 
 	got, err := ioutil.ReadFile(tmpName)
 	require.NoError(tb, err)
 
-	expected, err := ioutil.ReadFile("../../generated.go")
+	expected, err := ioutil.ReadFile("../generated.go")
 	require.NoError(tb, err)
 
 	require.Equal(tb, expected, got)
 }
 
-func Test_main(t *testing.T) {
-	testMain(t)
+func TestExecute_Generated(t *testing.T) {
+	testExecute(t)
 }
 
-func Benchmark_main(b *testing.B) {
+func BenchmarkExecute_Generated(b *testing.B) {
 	for idx := 0; idx < b.N; idx++ {
-		testMain(b)
+		testExecute(b)
 	}
 }
 
@@ -372,7 +377,7 @@ func TestExecute(t *testing.T) {
 			args:   []string{"-help"},
 			data:   testRender{},
 			exit:   1,
-			stderr: "Usage of gen:\n  -all-errors\n    \treport all errors (not just the first 10 on different lines) (default false)\n  -comments\n    \tkeep comments (default true)\n  -diff\n    \tdisplay a diff instead of rewriting files (default false)\n  -exit-code\n    \texit with a failure code if no change was detected (default false)\n  -format-only\n    \tdisable the insertion and deletions of imports (default false)\n  -imports\n    \trun goimports on the file post generation (default true)\n  -local value\n    \tput imports beginning with this string after 3rd-party packages (see goimports) (default github.com/demosdemon)\n  -no-clobber\n    \tfail to write a file if it already exists (default false)\n  -path value\n    \tpath of the generated file (default /dev/stdout)\n  -print-errors\n    \tprint non-fatal typechecking errors to stderr (default false)\n  -remove-bare-returns\n    \tremove bare returns (default false)\n  -returns\n    \trun goreturns on the file post generation (default true)\n  -tab-indent\n    \tuse tabs for indent (default true)\n  -tab-width value\n    \tset tab width (default 8)\n  -write\n    \twrite the generated file (default false)\n  -all-errors\n    \treport all errors (not just the first 10 on different lines) (default false)\n  -comments\n    \tkeep comments (default true)\n  -diff\n    \tdisplay a diff instead of rewriting files (default false)\n  -exit-code\n    \texit with a failure code if no change was detected (default false)\n  -format-only\n    \tdisable the insertion and deletions of imports (default false)\n  -imports\n    \trun goimports on the file post generation (default true)\n  -local value\n    \tput imports beginning with this string after 3rd-party packages (see goimports) (default github.com/demosdemon)\n  -no-clobber\n    \tfail to write a file if it already exists (default false)\n  -path value\n    \tpath of the generated file (default /dev/stdout)\n  -print-errors\n    \tprint non-fatal typechecking errors to stderr (default false)\n  -remove-bare-returns\n    \tremove bare returns (default false)\n  -returns\n    \trun goreturns on the file post generation (default true)\n  -tab-indent\n    \tuse tabs for indent (default true)\n  -tab-width value\n    \tset tab width (default 8)\n  -write\n    \twrite the generated file (default false)\n",
+			stderr: "Usage of pshgo-gen:\n  -all-errors\n    \treport all errors (not just the first 10 on different lines) (default false)\n  -comments\n    \tkeep comments (default true)\n  -diff\n    \tdisplay a diff instead of rewriting files (default false)\n  -exit-code\n    \texit with a failure code if no change was detected (default false)\n  -format-only\n    \tdisable the insertion and deletions of imports (default false)\n  -import-path value\n    \timport path of the generated package; overrides the schema\n  -imports\n    \trun goimports on the file post generation (default true)\n  -local value\n    \tput imports beginning with this string after 3rd-party packages (see goimports)\n  -no-clobber\n    \tfail to write a file if it already exists (default false)\n  -package value\n    \tname of the generated package; overrides the schema\n  -path value\n    \tpath of the generated file (default /dev/stdout)\n  -prefix value\n    \tenvironment variable prefix used instead of the provider prefix; overrides the schema\n  -print-errors\n    \tprint non-fatal typechecking errors to stderr (default false)\n  -remove-bare-returns\n    \tremove bare returns (default false)\n  -returns\n    \trun goreturns on the file post generation (default true)\n  -schema value\n    \tpath of the YAML or JSON schema to render\n  -tab-indent\n    \tuse tabs for indent (default true)\n  -tab-width value\n    \tset tab width (default 8)\n  -target value\n    \toutput to render: go, typescript or jsonschema (default go)\n  -types value\n    \tdirectory of the Go package declaring the decoded types (typescript and jsonschema only) (default .)\n  -write\n    \twrite the generated file (default false)\n  -all-errors\n    \treport all errors (not just the first 10 on different lines) (default false)\n  -comments\n    \tkeep comments (default true)\n  -diff\n    \tdisplay a diff instead of rewriting files (default false)\n  -exit-code\n    \texit with a failure code if no change was detected (default false)\n  -format-only\n    \tdisable the insertion and deletions of imports (default false)\n  -import-path value\n    \timport path of the generated package; overrides the schema\n  -imports\n    \trun goimports on the file post generation (default true)\n  -local value\n    \tput imports beginning with this string after 3rd-party packages (see goimports)\n  -no-clobber\n    \tfail to write a file if it already exists (default false)\n  -package value\n    \tname of the generated package; overrides the schema\n  -path value\n    \tpath of the generated file (default /dev/stdout)\n  -prefix value\n    \tenvironment variable prefix used instead of the provider prefix; overrides the schema\n  -print-errors\n    \tprint non-fatal typechecking errors to stderr (default false)\n  -remove-bare-returns\n    \tremove bare returns (default false)\n  -returns\n    \trun goreturns on the file post generation (default true)\n  -schema value\n    \tpath of the YAML or JSON schema to render\n  -tab-indent\n    \tuse tabs for indent (default true)\n  -tab-width value\n    \tset tab width (default 8)\n  -target value\n    \toutput to render: go, typescript or jsonschema (default go)\n  -types value\n    \tdirectory of the Go package declaring the decoded types (typescript and jsonschema only) (default .)\n  -write\n    \twrite the generated file (default false)\n",
 		},
 		{
 			name: "render error",
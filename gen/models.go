@@ -0,0 +1,1035 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	. "github.com/dave/jennifer/jen"
+	"github.com/go-openapi/inflect"
+	"github.com/iancoleman/strcase"
+)
+
+type Render interface {
+	Render(w io.Writer) error
+}
+
+const (
+	base64Pkg   = "encoding/base64"
+	driverPkg   = "database/sql/driver"
+	errorsPkg   = "github.com/pkg/errors"
+	filepathPkg = "path/filepath"
+	fmtPkg      = "fmt"
+	jsonPkg     = "encoding/json"
+	logrusPkg   = "github.com/sirupsen/logrus"
+	pshgoPkg    = "github.com/demosdemon/pshgo"
+	strconvPkg  = "strconv"
+	stringsPkg  = "strings"
+	timePkg     = "time"
+)
+
+type Schema struct {
+	Package string `json:"package" yaml:"package"`
+	// ImportPath is the import path of the generated package. Types of the
+	// pshgo package are qualified unless it is the pshgo package itself.
+	ImportPath string `json:"import_path,omitempty" yaml:"import_path,omitempty"`
+	// Prefix is prepended to the name of every variable instead of the
+	// prefix of the provider.
+	Prefix string `json:"prefix,omitempty" yaml:"prefix,omitempty"`
+	// Receiver names a type of the generated package implementing
+	// PlatformProvider; LookupX and GetX methods are generated for it.
+	Receiver string `json:"receiver,omitempty" yaml:"receiver,omitempty"`
+	// Accessor names an interface listing the methods generated for the
+	// receiver; a Fake implementation is generated along with it.
+	Accessor  string    `json:"accessor,omitempty" yaml:"accessor,omitempty"`
+	Enums     Enums     `json:"enums,omitempty" yaml:"enums,omitempty"`
+	Variables Variables `json:"variables,omitempty" yaml:"variables,omitempty"`
+	// Shapes overrides the JSON Schema of Go types, keyed by type name, for
+	// types with a custom JSON encoding.
+	Shapes map[string]*Shape `json:"shapes,omitempty" yaml:"shapes,omitempty"`
+}
+
+const (
+	TargetGo         = "go"
+	TargetTypeScript = "typescript"
+	TargetJSONSchema = "jsonschema"
+)
+
+// Renderer returns the renderer of an output target. types holds the Go
+// declarations of the decoded types and is only used by the non Go targets.
+func (s *Schema) Renderer(target string, types *TypeSet) (Render, error) {
+	switch target {
+	case "", TargetGo:
+		return s, nil
+	case TargetTypeScript:
+		return TypeScript{Schema: s, Types: types}, nil
+	case TargetJSONSchema:
+		return JSONSchema{Schema: s, Types: types}, nil
+	default:
+		return nil, fmt.Errorf("unknown target %q", target)
+	}
+}
+
+func (s Schema) Render(w io.Writer) error {
+	file := NewFile(s.Package)
+	if s.ImportPath != "" {
+		file = NewFilePathName(s.ImportPath, s.Package)
+	}
+	file.ImportName(pshgoPkg, "pshgo")
+	file.HeaderComment("Code generated by pshgo-gen. DO NOT EDIT.")
+
+	s.Enums.Render(file.Group)
+	s.Variables.Render(file.Group, s)
+	s.renderAccessor(file.Group)
+
+	var buf bytes.Buffer
+	err := file.Render(&buf)
+	if err != nil {
+		return err
+	}
+
+	_, err = buf.WriteTo(w)
+	return err
+}
+
+type Enums []Enum
+
+func (e Enums) Len() int {
+	return len(e)
+}
+
+func (e Enums) Less(i, j int) bool {
+	return strings.Compare(e[i].Name, e[j].Name) < 0
+}
+
+func (e Enums) Swap(i, j int) {
+	e[i], e[j] = e[j], e[i]
+}
+
+func (e Enums) Render(g *Group) {
+	sort.Stable(e)
+	for _, e := range e {
+		e.Render(g)
+	}
+}
+
+type Enum struct {
+	Name   string     `json:"name" yaml:"name"`
+	Values EnumValues `json:"values" yaml:"values"`
+	// PreserveUnknown generates a string based enum which keeps values it
+	// does not know instead of failing to decode them.
+	PreserveUnknown bool `json:"preserve_unknown,omitempty" yaml:"preserve_unknown,omitempty"`
+}
+
+// names returns the identifiers of the unexported total constant, the value
+// slice, the value map and the constructor generated for the enum.
+func (v Enum) names() (totalName, sliceName, mapName, newFuncName string) {
+	totalName = "total" + inflect.Pluralize(v.Name)
+	sliceName = inflect.CamelizeDownFirst(inflect.Pluralize(v.Name))
+	mapName = sliceName + "Map"
+	newFuncName = "New" + v.Name
+	return totalName, sliceName, mapName, newFuncName
+}
+
+// identifiers returns every package level identifier generated for the enum.
+func (v Enum) identifiers() []string {
+	totalName, sliceName, mapName, newFuncName := v.names()
+	rv := []string{v.Name, totalName, sliceName, mapName, newFuncName, v.Name + "Values"}
+	for _, val := range v.Values {
+		rv = append(rv, v.Name+val.Name)
+	}
+	return rv
+}
+
+func (v Enum) Render(g *Group) {
+	if v.PreserveUnknown {
+		v.renderString(g)
+	} else {
+		v.renderUint8(g)
+	}
+
+	v.renderCodecs(g)
+}
+
+// renderMap renders the map used to parse names, keyed by the lower case
+// value and aliases.
+func (v Enum) renderMap(g *Group) {
+	_, _, mapName, _ := v.names()
+
+	/*
+		accessLevelsMap = map[string]AccessLevel{
+			"viewer":      AccessLevelViewer,
+			"contributor": AccessLevelContributor,
+			"admin":       AccessLevelAdmin,
+		}
+	*/
+	g.Id(mapName).
+		Op("=").
+		Map(String()).
+		Id(v.Name).
+		ValuesFunc(func(g *Group) {
+			for _, val := range v.Values {
+				for _, key := range val.keys() {
+					g.Line().
+						Lit(key).
+						Op(":").
+						Id(v.Name + val.Name)
+				}
+			}
+			g.Line()
+		})
+}
+
+// renderNew renders the constructor parsing a name case-insensitively.
+func (v Enum) renderNew(g *Group, unknown Code) {
+	_, _, mapName, newFuncName := v.names()
+
+	/*
+		func NewAccessLevel(name string) (AccessLevel, error) {
+			if v, ok := accessLevelsMap[strings.ToLower(name)]; ok {
+				return v, nil
+			}
+
+			return 0, fmt.Errorf("unknown AccessLevel name %q", name)
+		}
+	*/
+	g.Func().
+		Id(newFuncName).
+		Params(Id("name").String()).
+		Params(Id(v.Name), Error()).
+		Block(
+			If(
+				List(Id("v"), Id("ok")).Op(":=").Id(mapName).Index(Qual(stringsPkg, "ToLower").Call(Id("name"))),
+				Id("ok"),
+			).Block(
+				Return(Id("v"), Nil()),
+			),
+			Line(),
+			Return(
+				unknown,
+				Qual(fmtPkg, "Errorf").
+					Call(
+						Lit(fmt.Sprintf("unknown %s name %%q", v.Name)),
+						Id("name"),
+					),
+			),
+		).
+		Line()
+}
+
+func (v Enum) renderUint8(g *Group) {
+	name := v.Name
+	totalName, sliceName, _, _ := v.names()
+
+	/*
+		type AccessLevel uint8
+	*/
+	g.Type().Id(v.Name).Uint8()
+
+	/*
+		const (
+			AccessLevelViewer AccessLevel = iota
+			AccessLevelContributor
+			AccessLevelAdmin
+			totalAccessLevels
+		)
+	*/
+	g.Const().
+		DefsFunc(func(g *Group) {
+			for idx, v := range v.Values {
+				s := g.Id(name + v.Name)
+				if idx == 0 {
+					s.Id(name).Op("=").Iota()
+				}
+			}
+			g.Id(totalName)
+		})
+
+	/*
+		var (
+			accessLevels = [totalAccessLevels]string{
+				"viewer",
+				"contributor",
+				"admin",
+			}
+
+			accessLevelsMap = map[string]AccessLevel{...}
+		)
+	*/
+	g.Var().
+		DefsFunc(func(g *Group) {
+			g.Id(sliceName).
+				Op("=").
+				Index(Id(totalName)).
+				String().
+				ValuesFunc(func(g *Group) {
+					for _, v := range v.Values {
+						g.Line().Lit(v.Value)
+					}
+					g.Line()
+				})
+
+			g.Line()
+
+			v.renderMap(g)
+		})
+
+	/*
+		func AccessLevelValues() []AccessLevel {
+			rv := make([]AccessLevel, totalAccessLevels)
+			for idx := range rv {
+				rv[idx] = AccessLevel(idx)
+			}
+			return rv
+		}
+	*/
+	g.Func().
+		Id(name+"Values").
+		Params().
+		Index().Id(name).
+		Block(
+			Id("rv").Op(":=").Make(Index().Id(name), Id(totalName)),
+			For(Id("idx").Op(":=").Range().Id("rv")).Block(
+				Id("rv").Index(Id("idx")).Op("=").Id(name).Call(Id("idx")),
+			),
+			Return(Id("rv")),
+		).
+		Line()
+
+	v.renderNew(g, Lit(0))
+
+	/*
+		func (v AccessLevel) IsValid() bool {
+			return v < totalAccessLevels
+		}
+	*/
+	g.Func().
+		Params(Id("v").Id(name)).
+		Id("IsValid").
+		Params().
+		Bool().
+		Block(
+			Return(Id("v").Op("<").Id(totalName)),
+		).
+		Line()
+
+	/*
+		func (v AccessLevel) String() string {
+			if v < totalAccessLevels {
+				return accessLevels[v]
+			}
+
+			return fmt.Sprintf("unknown AccessLevel value %02x", uint8(v))
+		}
+	*/
+	g.Func().
+		Params(Id("v").Id(name)).
+		Id("String").
+		Params().
+		String().
+		Block(
+			If(Id("v").Op("<").Id(totalName)).
+				Block(
+					Return(Id(sliceName).Index(Id("v"))),
+				),
+			Line(),
+			Return(
+				Qual(fmtPkg, "Sprintf").
+					Call(
+						Lit(fmt.Sprintf("unknown %s value %%02x", name)),
+						Uint8().Call(Id("v")),
+					),
+			),
+		).
+		Line()
+
+	/*
+		func (v *AccessLevel) UnmarshalText(text []byte) (err error) {
+			*v, err = NewAccessLevel(string(text))
+			return err
+		}
+	*/
+	g.Func().
+		Params(Id("v").Op("*").Id(name)).
+		Id("UnmarshalText").
+		Params(Id("text").Index().Byte()).
+		Params(Err().Error()).
+		Block(
+			List(Op("*").Id("v"), Err()).Op("=").
+				Id("New"+name).Call(String().Call(Id("text"))),
+			Return(Err()),
+		).
+		Line()
+
+	/*
+		func (v AccessLevel) MarshalText() ([]byte, error) {
+			if v < totalAccessLevels {
+				return []byte(accessLevels[v]), nil
+			}
+
+			return nil, errors.New(v.String())
+		}
+	*/
+	g.Func().
+		Params(Id("v").Id(name)).
+		Id("MarshalText").
+		Params().
+		Params(Index().Byte(), Error()).
+		Block(
+			If(Id("v").Op("<").Id(totalName)).
+				Block(
+					Return(
+						Index().Byte().Call(Id(sliceName).Index(Id("v"))),
+						Nil(),
+					),
+				),
+			Line(),
+			Return(
+				Nil(),
+				Qual(errorsPkg, "New").Call(Id("v").Dot("String").Call()),
+			),
+		).Line()
+}
+
+func (v Enum) renderString(g *Group) {
+	name := v.Name
+	_, _, mapName, _ := v.names()
+
+	/*
+		type ServiceSize string
+	*/
+	g.Type().Id(name).String()
+
+	/*
+		const (
+			ServiceSizeAuto  ServiceSize = "AUTO"
+			ServiceSizeSmall ServiceSize = "S"
+		)
+	*/
+	g.Const().
+		DefsFunc(func(g *Group) {
+			for _, v := range v.Values {
+				g.Id(name + v.Name).Id(name).Op("=").Lit(v.Value)
+			}
+		})
+
+	/*
+		var serviceSizesMap = map[string]ServiceSize{...}
+	*/
+	g.Var().DefsFunc(v.renderMap)
+
+	/*
+		func ServiceSizeValues() []ServiceSize {
+			return []ServiceSize{
+				ServiceSizeAuto,
+				ServiceSizeSmall,
+			}
+		}
+	*/
+	g.Func().
+		Id(name + "Values").
+		Params().
+		Index().Id(name).
+		Block(
+			Return(Index().Id(name).ValuesFunc(func(g *Group) {
+				for _, v := range v.Values {
+					g.Line().Id(name + v.Name)
+				}
+				g.Line()
+			})),
+		).
+		Line()
+
+	v.renderNew(g, Id(name).Call(Id("name")))
+
+	/*
+		func (v ServiceSize) IsValid() bool {
+			known, ok := serviceSizesMap[strings.ToLower(string(v))]
+			return ok && known == v
+		}
+	*/
+	g.Func().
+		Params(Id("v").Id(name)).
+		Id("IsValid").
+		Params().
+		Bool().
+		Block(
+			List(Id("known"), Id("ok")).Op(":=").Id(mapName).Index(Qual(stringsPkg, "ToLower").Call(String().Call(Id("v")))),
+			Return(Id("ok").Op("&&").Id("known").Op("==").Id("v")),
+		).
+		Line()
+
+	/*
+		func (v ServiceSize) String() string {
+			return string(v)
+		}
+	*/
+	g.Func().
+		Params(Id("v").Id(name)).
+		Id("String").
+		Params().
+		String().
+		Block(
+			Return(String().Call(Id("v"))),
+		).
+		Line()
+
+	/*
+		func (v *ServiceSize) UnmarshalText(text []byte) error {
+			*v, _ = NewServiceSize(string(text))
+			return nil
+		}
+	*/
+	g.Func().
+		Params(Id("v").Op("*").Id(name)).
+		Id("UnmarshalText").
+		Params(Id("text").Index().Byte()).
+		Error().
+		Block(
+			List(Op("*").Id("v"), Id("_")).Op("=").
+				Id("New"+name).Call(String().Call(Id("text"))),
+			Return(Nil()),
+		).
+		Line()
+
+	/*
+		func (v ServiceSize) MarshalText() ([]byte, error) {
+			return []byte(v), nil
+		}
+	*/
+	g.Func().
+		Params(Id("v").Id(name)).
+		Id("MarshalText").
+		Params().
+		Params(Index().Byte(), Error()).
+		Block(
+			Return(Index().Byte().Call(Id("v")), Nil()),
+		).
+		Line()
+}
+
+// renderCodecs renders the YAML and database/sql methods, which delegate to
+// the text methods.
+func (v Enum) renderCodecs(g *Group) {
+	name := v.Name
+
+	/*
+		func (v *AccessLevel) UnmarshalYAML(unmarshal func(interface{}) error) error {
+			var text string
+			if err := unmarshal(&text); err != nil {
+				return err
+			}
+			return v.UnmarshalText([]byte(text))
+		}
+	*/
+	g.Func().
+		Params(Id("v").Op("*").Id(name)).
+		Id("UnmarshalYAML").
+		Params(Id("unmarshal").Func().Params(Interface()).Error()).
+		Error().
+		Block(
+			Var().Id("text").String(),
+			If(
+				Err().Op(":=").Id("unmarshal").Call(Op("&").Id("text")),
+				Err().Op("!=").Nil(),
+			).Block(
+				Return(Err()),
+			),
+			Return(Id("v").Dot("UnmarshalText").Call(Index().Byte().Call(Id("text")))),
+		).
+		Line()
+
+	/*
+		func (v AccessLevel) MarshalYAML() (interface{}, error) {
+			text, err := v.MarshalText()
+			return string(text), err
+		}
+	*/
+	g.Func().
+		Params(Id("v").Id(name)).
+		Id("MarshalYAML").
+		Params().
+		Params(Interface(), Error()).
+		Block(
+			List(Id("text"), Err()).Op(":=").Id("v").Dot("MarshalText").Call(),
+			Return(String().Call(Id("text")), Err()),
+		).
+		Line()
+
+	/*
+		func (v *AccessLevel) Scan(src interface{}) error {
+			switch src := src.(type) {
+			case string:
+				return v.UnmarshalText([]byte(src))
+			case []byte:
+				return v.UnmarshalText(src)
+			default:
+				return fmt.Errorf("cannot scan %T into AccessLevel", src)
+			}
+		}
+	*/
+	g.Func().
+		Params(Id("v").Op("*").Id(name)).
+		Id("Scan").
+		Params(Id("src").Interface()).
+		Error().
+		Block(
+			Switch(Id("src").Op(":=").Id("src").Assert(Type())).Block(
+				Case(String()).Block(
+					Return(Id("v").Dot("UnmarshalText").Call(Index().Byte().Call(Id("src")))),
+				),
+				Case(Index().Byte()).Block(
+					Return(Id("v").Dot("UnmarshalText").Call(Id("src"))),
+				),
+				Default().Block(
+					Return(Qual(fmtPkg, "Errorf").Call(Lit(fmt.Sprintf("cannot scan %%T into %s", name)), Id("src"))),
+				),
+			),
+		).
+		Line()
+
+	/*
+		func (v AccessLevel) Value() (driver.Value, error) {
+			text, err := v.MarshalText()
+			if err != nil {
+				return nil, err
+			}
+			return string(text), nil
+		}
+	*/
+	g.Func().
+		Params(Id("v").Id(name)).
+		Id("Value").
+		Params().
+		Params(Qual(driverPkg, "Value"), Error()).
+		Block(
+			List(Id("text"), Err()).Op(":=").Id("v").Dot("MarshalText").Call(),
+			If(Err().Op("!=").Nil()).Block(
+				Return(Nil(), Err()),
+			),
+			Return(String().Call(Id("text")), Nil()),
+		).
+		Line()
+}
+
+type EnumValues []EnumValue
+
+type EnumValue struct {
+	Name    string   `json:"name" yaml:"name"`
+	Value   string   `json:"value" yaml:"value"`
+	Aliases []string `json:"aliases,omitempty" yaml:"aliases,omitempty,flow"`
+}
+
+// keys returns the lower case value and aliases parsed into the value.
+func (v EnumValue) keys() []string {
+	rv := []string{strings.ToLower(v.Value)}
+	for _, a := range v.Aliases {
+		rv = append(rv, strings.ToLower(a))
+	}
+	return rv
+}
+
+type Variables []Variable
+
+func (v Variables) Len() int {
+	return len(v)
+}
+
+func (v Variables) Less(i, j int) bool {
+	return strings.Compare(v[i].Name, v[j].Name) < 0
+}
+
+func (v Variables) Swap(i, j int) {
+	v[i], v[j] = v[j], v[i]
+}
+
+func (v Variables) Render(g *Group, s Schema) {
+	sort.Sort(v)
+	for _, v := range v {
+		v.Render(g, s)
+	}
+}
+
+// VariableKind selects how the raw value of a variable is decoded.
+type VariableKind string
+
+const (
+	// KindString returns the raw value.
+	KindString VariableKind = ""
+	// KindJSON decodes base64 then unmarshals the JSON into DecodedType;
+	// the default when DecodedType is set.
+	KindJSON VariableKind = "json"
+	// KindText unmarshals the raw value with DecodedType.UnmarshalText.
+	KindText VariableKind = "text"
+	// KindBase64 decodes base64 into a byte slice.
+	KindBase64 VariableKind = "base64"
+	// KindInt parses a decimal integer.
+	KindInt VariableKind = "int"
+	// KindBool parses a boolean with strconv.ParseBool.
+	KindBool VariableKind = "bool"
+	// KindPath cleans a non-empty filesystem path.
+	KindPath VariableKind = "path"
+	// KindList splits a comma separated list, dropping empty items.
+	KindList VariableKind = "list"
+	// KindDuration parses a time.Duration.
+	KindDuration VariableKind = "duration"
+)
+
+type Variable struct {
+	Name           string       `json:"name" yaml:"name"`
+	NoPrefix       bool         `json:"no_prefix,omitempty" yaml:"no_prefix,omitempty"`
+	Aliases        []string     `json:"aliases,omitempty" yaml:"aliases,omitempty,flow"`
+	Kind           VariableKind `json:"kind,omitempty" yaml:"kind,omitempty"`
+	DecodedType    string       `json:"decoded_type,omitempty" yaml:"decoded_type,omitempty"`
+	DecodedPointer bool         `json:"decoded_pointer,omitempty" yaml:"decoded_pointer,omitempty"`
+	// Key reads the value from this entry of PLATFORM_VARIABLES (e.g.
+	// "myapp:debug") instead of from an environment variable.
+	Key string `json:"key,omitempty" yaml:"key,omitempty"`
+}
+
+// typeRef renders a type name which is either local to the generated package
+// or qualified with its import path, e.g. "github.com/demosdemon/pshgo.Routes".
+func typeRef(name string) *Statement {
+	if idx := strings.LastIndex(name, "."); idx >= 0 {
+		return Qual(name[:idx], name[idx+1:])
+	}
+	return Id(name)
+}
+
+// envName is the name of the environment variable read by the accessors.
+func (v Variable) envName(s Schema) *Statement {
+	name := strcase.ToScreamingSnake(v.Name)
+	switch {
+	case v.NoPrefix:
+		return Lit(name)
+	case s.Prefix != "":
+		return Lit(s.Prefix + name)
+	default:
+		return Id("p").Dot("Prefix").Call().Op("+").Lit(name)
+	}
+}
+
+// identifiers returns every package level identifier generated for the
+// variable and its aliases.
+func (v Variable) identifiers() []string {
+	var rv []string
+	for _, name := range append([]string{v.Name}, v.Aliases...) {
+		rv = append(rv, "Lookup"+strcase.ToCamel(name), "Get"+strcase.ToCamel(name))
+	}
+	return rv
+}
+
+func (v Variable) kind() VariableKind {
+	if v.Kind == KindString && v.DecodedType != "" {
+		return KindJSON
+	}
+	return v.Kind
+}
+
+// returnType is the type returned by the LookupX and GetX functions.
+func (v Variable) returnType() *Statement {
+	switch v.kind() {
+	case KindJSON, KindText:
+		rType := Null()
+		if v.DecodedPointer {
+			rType.Op("*")
+		}
+		return rType.Add(typeRef(v.DecodedType))
+	case KindBase64:
+		return Index().Byte()
+	case KindInt:
+		return Int()
+	case KindBool:
+		return Bool()
+	case KindList:
+		return Index().String()
+	case KindDuration:
+		return Qual(timePkg, "Duration")
+	default:
+		return String()
+	}
+}
+
+// zeroValue is returned by LookupX when the value is missing or invalid.
+func (v Variable) zeroValue() *Statement {
+	switch v.kind() {
+	case KindText:
+		if v.DecodedPointer {
+			return Nil()
+		}
+		return Op("*").New(typeRef(v.DecodedType))
+	case KindInt, KindDuration:
+		return Lit(0)
+	case KindBool:
+		return False()
+	case KindPath:
+		return Lit("")
+	default:
+		return Nil()
+	}
+}
+
+// renderDecode renders the statements converting value to the return type.
+func (v Variable) renderDecode(g *Group) {
+	zero := v.zeroValue()
+
+	switch v.kind() {
+	case KindString:
+		g.Return(Id("value"), Id("ok"))
+		return
+	case KindPath:
+		g.If(Op("!").Id("ok").Op("||").Id("value").Op("==").Lit("")).Block(Return(zero, False()))
+	default:
+		g.If(Op("!").Id("ok")).Block(Return(zero, False()))
+	}
+
+	switch v.kind() {
+	case KindJSON:
+		if v.Key != "" {
+			// the values of PLATFORM_VARIABLES are already decoded
+			g.Id("obj").Op(":=").Add(typeRef(v.DecodedType)).Values()
+
+			g.Err().Op(":=").Qual(jsonPkg, "Unmarshal").Call(Index().Byte().Call(Id("value")), Op("&").Id("obj"))
+		} else {
+			g.List(Id("data"), Err()).
+				Op(":=").
+				Qual(base64Pkg, "StdEncoding").
+				Dot("DecodeString").
+				Call(Id("value"))
+
+			errNotNil(g, "unable to decode value", zero)
+
+			g.Id("obj").Op(":=").Add(typeRef(v.DecodedType)).Values()
+
+			g.Err().Op("=").Qual(jsonPkg, "Unmarshal").Call(Id("data"), Op("&").Id("obj"))
+		}
+
+		errNotNil(g, "unable to unmarshal value", zero)
+
+		val := Null()
+		if v.DecodedPointer {
+			val.Op("&")
+		}
+		val.Id("obj")
+
+		g.Return(val, True())
+
+	case KindText:
+		g.Id("obj").Op(":=").New(typeRef(v.DecodedType))
+
+		g.Err().Op(":=").Id("obj").Dot("UnmarshalText").Call(Index().Byte().Call(Id("value")))
+
+		errNotNil(g, "unable to unmarshal value", zero)
+
+		val := Null()
+		if !v.DecodedPointer {
+			val.Op("*")
+		}
+		val.Id("obj")
+
+		g.Return(val, True())
+
+	case KindBase64:
+		g.List(Id("data"), Err()).
+			Op(":=").
+			Qual(base64Pkg, "StdEncoding").
+			Dot("DecodeString").
+			Call(Id("value"))
+
+		errNotNil(g, "unable to decode value", zero)
+
+		g.Return(Id("data"), True())
+
+	case KindInt, KindBool, KindDuration:
+		fn := map[VariableKind]*Statement{
+			KindInt:      Qual(strconvPkg, "Atoi"),
+			KindBool:     Qual(strconvPkg, "ParseBool"),
+			KindDuration: Qual(timePkg, "ParseDuration"),
+		}[v.kind()]
+
+		g.List(Id("obj"), Err()).
+			Op(":=").
+			Add(fn).
+			Call(Qual(stringsPkg, "TrimSpace").Call(Id("value")))
+
+		errNotNil(g, "unable to parse value", zero)
+
+		g.Return(Id("obj"), True())
+
+	case KindPath:
+		g.Return(Qual(filepathPkg, "Clean").Call(Id("value")), True())
+
+	case KindList:
+		g.Var().Id("list").Index().String()
+
+		g.For(
+			List(Id("_"), Id("item")).
+				Op(":=").
+				Range().
+				Qual(stringsPkg, "Split").Call(Id("value"), Lit(",")),
+		).Block(
+			If(
+				Id("item").Op("=").Qual(stringsPkg, "TrimSpace").Call(Id("item")),
+				Id("item").Op("!=").Lit(""),
+			).Block(
+				Id("list").Op("=").Append(Id("list"), Id("item")),
+			),
+		)
+
+		g.Return(Id("list"), True())
+
+	default:
+		panic(fmt.Sprintf("unknown variable kind %q", v.Kind))
+	}
+}
+
+func (v Variable) Render(g *Group, s Schema) {
+	lookupName := "Lookup" + strcase.ToCamel(v.Name)
+	getName := "Get" + strcase.ToCamel(v.Name)
+	rType := v.returnType()
+
+	provider := Id("p").Qual(pshgoPkg, "PlatformProvider")
+
+	/*
+		func LookupApplication(p PlatformProvider) (*Application, bool) {
+			name := p.Prefix() + "APPLICATION"
+			value, ok := p.Lookup(name)
+			if !ok {
+				return nil, false
+			}
+			data, err := base64.StdEncoding.DecodeString(value)
+			if err != nil {
+				logrus.WithError(err).Warn("unable to decode value")
+				return nil, false
+			}
+			obj := Application{}
+			err = json.Unmarshal(data, &obj)
+			if err != nil {
+				logrus.WithError(err).Warn("unable to unmarshal value")
+				return nil, false
+			}
+			return &app, true
+		}
+	*/
+	g.Func().
+		Id(lookupName).
+		Params(provider).
+		Params(rType, Bool()).
+		BlockFunc(func(g *Group) {
+			if v.Key != "" {
+				g.List(Id("value"), Id("ok")).Op(":=").Qual(pshgoPkg, "LookupVariable").Call(Id("p"), Lit(v.Key))
+			} else {
+				g.Id("name").Op(":=").Add(v.envName(s))
+				g.List(Id("value"), Id("ok")).Op(":=").Id("p").Dot("Lookup").Call(Id("name"))
+			}
+
+			v.renderDecode(g)
+		}).
+		Line()
+
+	/*
+		func GetApplication(p PlatformProvider) *Application {
+			v, _ := LookupApplication(p)
+			return v
+		}
+	*/
+	g.Func().
+		Id(getName).
+		Params(provider).
+		Add(rType).
+		Block(
+			List(Id("v"), Id("_")).Op(":=").Id(lookupName).Call(Id("p")),
+			Return(Id("v")),
+		).
+		Line()
+
+	s.renderMethods(g, lookupName, getName, rType)
+
+	for _, a := range v.Aliases {
+		lookupAlias := "Lookup" + strcase.ToCamel(a)
+		getAlias := "Get" + strcase.ToCamel(a)
+
+		/*
+			func LookupApp(p PlatformProvider) (*Application, bool) {
+				return LookupApplication(p)
+			}
+		*/
+		g.Func().
+			Id(lookupAlias).
+			Params(provider).
+			Params(rType, Bool()).
+			Block(
+				Return(Id(lookupName).Call(Id("p"))),
+			).
+			Line()
+
+		/*
+			func GetApp(p PlatformProvider) *Application {
+				return GetApplication(p)
+			}
+		*/
+		g.Func().
+			Id(getAlias).
+			Params(provider).
+			Add(rType).
+			Block(
+				Return(Id(getName).Call(Id("p"))),
+			).
+			Line()
+
+		s.renderMethods(g, lookupAlias, getAlias, rType)
+	}
+}
+
+// renderMethods renders the methods forwarding to the LookupX and GetX
+// functions when the schema names a receiver.
+func (s Schema) renderMethods(g *Group, lookupName, getName string, rType *Statement) {
+	if s.Receiver == "" {
+		return
+	}
+
+	receiver := Id("e").Op("*").Id(s.Receiver)
+
+	/*
+		func (e *Environment) LookupApplication() (*Application, bool) {
+			return LookupApplication(e)
+		}
+	*/
+	g.Func().
+		Params(receiver).
+		Id(lookupName).
+		Params().
+		Params(rType, Bool()).
+		Block(
+			Return(Id(lookupName).Call(Id("e"))),
+		).
+		Line()
+
+	/*
+		func (e *Environment) GetApplication() *Application {
+			return GetApplication(e)
+		}
+	*/
+	g.Func().
+		Params(receiver).
+		Id(getName).
+		Params().
+		Add(rType).
+		Block(
+			Return(Id(getName).Call(Id("e"))),
+		).
+		Line()
+}
+
+func errNotNil(g *Group, msg string, zero Code) {
+	g.If(Err().Op("!=").Nil()).
+		Block(
+			Qual(logrusPkg, "WithError").
+				Call(Err()).
+				Dot("Warn").
+				Call(Lit(msg)),
+			Return(zero, False()),
+		)
+}
@@ -0,0 +1,343 @@
+package gen
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchema_Render(t *testing.T) {
+	cases := []struct {
+		name    string
+		schema  Schema
+		wantW   string
+		wantErr bool
+	}{
+		{
+			name:    "empty",
+			schema:  Schema{},
+			wantW:   "",
+			wantErr: true,
+		},
+		{
+			name:   "EmptyWithPackage",
+			schema: Schema{Package: "main"},
+			wantW: `// Code generated by pshgo-gen. DO NOT EDIT.
+
+package main
+`,
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := c.schema.Render(&buf)
+			assert.True(t, (err != nil) == c.wantErr)
+			assert.Equal(t, c.wantW, string(buf.Bytes()))
+		})
+	}
+}
+
+func TestEnum_Render(t *testing.T) {
+	cases := []struct {
+		name string
+		enum Enum
+		want []string
+	}{
+		{
+			name: "uint8",
+			enum: Enum{Name: "Color", Values: EnumValues{{Name: "Red", Value: "red", Aliases: []string{"Crimson"}}}},
+			want: []string{
+				"type Color uint8",
+				`"crimson": ColorRed,`,
+				"func ColorValues() []Color {",
+				"if v, ok := colorsMap[strings.ToLower(name)]; ok {",
+				"func (v Color) IsValid() bool {",
+				"func (v *Color) UnmarshalYAML(unmarshal func(interface{}) error) error {",
+				"func (v *Color) Scan(src interface{}) error {",
+				"func (v Color) Value() (driver.Value, error) {",
+			},
+		},
+		{
+			name: "preserve unknown",
+			enum: Enum{Name: "Color", PreserveUnknown: true, Values: EnumValues{{Name: "Red", Value: "RED"}}},
+			want: []string{
+				"type Color string",
+				`ColorRed Color = "RED"`,
+				`"red": ColorRed,`,
+				"return Color(name), fmt.Errorf(",
+				"*v, _ = NewColor(string(text))",
+				"func (v Color) MarshalYAML() (interface{}, error) {",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			schema := Schema{Package: "main", Enums: Enums{c.enum}}
+			var buf bytes.Buffer
+			require.NoError(t, schema.Render(&buf))
+			for _, want := range c.want {
+				assert.Contains(t, buf.String(), want)
+			}
+		})
+	}
+}
+
+func TestVariable_Render(t *testing.T) {
+	cases := []struct {
+		name     string
+		variable Variable
+		want     []string
+	}{
+		{
+			name:     "string",
+			variable: Variable{Name: "Branch"},
+			want: []string{
+				"func LookupBranch(p PlatformProvider) (string, bool) {",
+				"return value, ok",
+			},
+		},
+		{
+			name:     "json",
+			variable: Variable{Name: "Application", DecodedType: "Application", DecodedPointer: true},
+			want: []string{
+				"func LookupApplication(p PlatformProvider) (*Application, bool) {",
+				"err = json.Unmarshal(data, &obj)",
+				"return &obj, true",
+			},
+		},
+		{
+			name:     "text",
+			variable: Variable{Name: "EnvironmentType", Kind: KindText, DecodedType: "EnvironmentType"},
+			want: []string{
+				"func LookupEnvironmentType(p PlatformProvider) (EnvironmentType, bool) {",
+				"err := obj.UnmarshalText([]byte(value))",
+				"return *new(EnvironmentType), false",
+				"return *obj, true",
+			},
+		},
+		{
+			name:     "base64",
+			variable: Variable{Name: "Secret", Kind: KindBase64},
+			want: []string{
+				"func LookupSecret(p PlatformProvider) ([]byte, bool) {",
+				"data, err := base64.StdEncoding.DecodeString(value)",
+				"return data, true",
+			},
+		},
+		{
+			name:     "int",
+			variable: Variable{Name: "Workers", Kind: KindInt},
+			want: []string{
+				"func LookupWorkers(p PlatformProvider) (int, bool) {",
+				"obj, err := strconv.Atoi(strings.TrimSpace(value))",
+				"return 0, false",
+			},
+		},
+		{
+			name:     "bool",
+			variable: Variable{Name: "Debug", Kind: KindBool},
+			want: []string{
+				"func LookupDebug(p PlatformProvider) (bool, bool) {",
+				"obj, err := strconv.ParseBool(strings.TrimSpace(value))",
+			},
+		},
+		{
+			name:     "duration",
+			variable: Variable{Name: "Timeout", Kind: KindDuration},
+			want: []string{
+				"func LookupTimeout(p PlatformProvider) (time.Duration, bool) {",
+				"obj, err := time.ParseDuration(strings.TrimSpace(value))",
+			},
+		},
+		{
+			name:     "path",
+			variable: Variable{Name: "CacheDir", Kind: KindPath},
+			want: []string{
+				"func LookupCacheDir(p PlatformProvider) (string, bool) {",
+				`if !ok || value == "" {`,
+				"return filepath.Clean(value), true",
+			},
+		},
+		{
+			name:     "list",
+			variable: Variable{Name: "Hosts", Kind: KindList},
+			want: []string{
+				"func LookupHosts(p PlatformProvider) ([]string, bool) {",
+				`for _, item := range strings.Split(value, ",") {`,
+				"return list, true",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			schema := Schema{Package: "pshgo", ImportPath: pshgoPkg, Variables: Variables{c.variable}}
+			var buf bytes.Buffer
+			require.NoError(t, schema.Render(&buf))
+			for _, want := range c.want {
+				assert.Contains(t, buf.String(), want)
+			}
+		})
+	}
+}
+
+func TestSchema_Render_Downstream(t *testing.T) {
+	schema := Schema{
+		Package:    "config",
+		ImportPath: "example.com/app/config",
+		Prefix:     "APP_",
+		Receiver:   "Env",
+		Variables: Variables{
+			{Name: "Debug", Kind: KindBool},
+			{Name: "Home", NoPrefix: true, Kind: KindPath},
+			{Name: "Mailer", Key: "app:mailer", DecodedType: "Mailer", DecodedPointer: true},
+			{Name: "Upstreams", DecodedType: "github.com/demosdemon/pshgo.Routes", Aliases: []string{"Backends"}},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, schema.Render(&buf))
+	out := buf.String()
+
+	for _, want := range []string{
+		"package config",
+		`"github.com/demosdemon/pshgo"`,
+		"func LookupDebug(p pshgo.PlatformProvider) (bool, bool) {",
+		`name := "APP_DEBUG"`,
+		`name := "HOME"`,
+		`value, ok := pshgo.LookupVariable(p, "app:mailer")`,
+		"err := json.Unmarshal([]byte(value), &obj)",
+		"func LookupMailer(p pshgo.PlatformProvider) (*Mailer, bool) {",
+		"func LookupUpstreams(p pshgo.PlatformProvider) (pshgo.Routes, bool) {",
+		"obj := pshgo.Routes{}",
+		"func (e *Env) GetDebug() bool {",
+		"func (e *Env) GetBackends() pshgo.Routes {",
+	} {
+		assert.Contains(t, out, want)
+	}
+
+	assert.NotContains(t, out, "p.Prefix()")
+	assert.NotContains(t, out, "Environment")
+}
+
+// keyedMain looks the keyed variables up in a PLATFORM_VARIABLES fixture.
+const keyedMain = `package main
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"github.com/demosdemon/pshgo"
+)
+
+type Mailer struct {
+	Host string ` + "`json:\"host\"`" + `
+	Port int    ` + "`json:\"port\"`" + `
+}
+
+func main() {
+	vars := base64.StdEncoding.EncodeToString([]byte(` + "`" + `{
+		"app:mailer": {"host": "smtp.example.com", "port": 2525},
+		"app:legacy": "{\"host\": \"legacy.example.com\"}",
+		"app:broken": "{"
+	}` + "`" + `))
+	env := pshgo.NewEnvironmentWithProvider("PLATFORM_", pshgo.MapProvider{"PLATFORM_VARIABLES": vars})
+
+	mailer, ok := LookupMailer(env)
+	fmt.Println(ok, mailer.Host, mailer.Port)
+	legacy, ok := LookupLegacy(env)
+	fmt.Println(ok, legacy.Host)
+	_, ok = LookupBroken(env)
+	fmt.Println(ok)
+	_, ok = LookupMailer(pshgo.NewEnvironmentWithProvider("PLATFORM_", pshgo.MapProvider{}))
+	fmt.Println(ok)
+}
+`
+
+func TestSchema_Render_KeyedJSON(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds the generated code")
+	}
+
+	schema := Schema{
+		Package: "main",
+		Variables: Variables{
+			{Name: "Mailer", Key: "app:mailer", DecodedType: "Mailer", DecodedPointer: true},
+			{Name: "Legacy", Key: "app:legacy", DecodedType: "Mailer", DecodedPointer: true},
+			{Name: "Broken", Key: "app:broken", DecodedType: "Mailer", DecodedPointer: true},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, schema.Render(&buf))
+	assert.NotContains(t, buf.String(), "base64")
+
+	// the program must be inside the module to import it
+	require.NoError(t, os.MkdirAll("testdata", 0755))
+	defer os.Remove("testdata")
+	dir, err := ioutil.TempDir("testdata", "keyed")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "generated.go"), buf.Bytes(), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "main.go"), []byte(keyedMain), 0644))
+
+	var stderr bytes.Buffer
+	cmd := exec.Command("go", "run", "./"+filepath.ToSlash(dir))
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	require.NoError(t, err, stderr.String())
+	assert.Equal(t, "true smtp.example.com 2525\ntrue legacy.example.com\nfalse\nfalse\n", string(out))
+}
+
+func TestSchema_Render_Accessor(t *testing.T) {
+	schema := Schema{
+		Package:  "config",
+		Receiver: "Env",
+		Accessor: "Accessor",
+		Variables: Variables{
+			{Name: "Debug", Kind: KindBool, Aliases: []string{"Verbose"}},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, schema.Render(&buf))
+	out := buf.String()
+
+	for _, want := range []string{
+		"type Accessor interface {",
+		"LookupVerbose() (bool, bool)",
+		"type FakeAccessor struct {",
+		"LookupDebugFunc func() (bool, bool)",
+		"_ Accessor = (*Env)(nil)",
+		"func (f *FakeAccessor) GetVerbose() bool {\n\treturn f.GetDebug()\n}",
+		`f.record("LookupDebug")`,
+		"return LookupDebug(f.Provider)",
+	} {
+		assert.Contains(t, out, want)
+	}
+
+	schema.Receiver = ""
+	buf.Reset()
+	require.NoError(t, schema.Render(&buf))
+	assert.NotContains(t, buf.String(), "Accessor")
+}
+
+func TestVariable_Render_UnknownKind(t *testing.T) {
+	schema := Schema{Package: "main", Variables: Variables{{Name: "Foo", Kind: "bogus"}}}
+	assert.Panics(t, func() {
+		_ = schema.Render(&bytes.Buffer{})
+	})
+}
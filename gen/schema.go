@@ -0,0 +1,212 @@
+package gen
+
+import (
+	"fmt"
+	"go/token"
+	"io/ioutil"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/iancoleman/strcase"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	ErrNoSchema = errors.New("no schema provided; use -schema")
+
+	variableKinds = map[VariableKind]bool{
+		KindString:   true,
+		KindJSON:     true,
+		KindText:     true,
+		KindBase64:   true,
+		KindInt:      true,
+		KindBool:     true,
+		KindPath:     true,
+		KindList:     true,
+		KindDuration: true,
+	}
+)
+
+// LoadSchema reads and validates a schema file. JSON is a subset of YAML so
+// both formats are accepted.
+func LoadSchema(path string) (*Schema, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading schema")
+	}
+
+	return ParseSchema(data)
+}
+
+func ParseSchema(data []byte) (*Schema, error) {
+	var s Schema
+	if err := yaml.UnmarshalStrict(data, &s); err != nil {
+		return nil, errors.Wrap(err, "error parsing schema")
+	}
+
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+// Validate reports every problem in the schema that would otherwise produce an
+// invalid or ambiguous generated file.
+func (s Schema) Validate() error {
+	var result error
+	fail := func(format string, args ...interface{}) {
+		result = multierror.Append(result, fmt.Errorf(format, args...))
+	}
+
+	if !token.IsIdentifier(s.Package) {
+		fail("invalid package name %q", s.Package)
+	}
+
+	if strings.Trim(s.Prefix, "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_") != "" {
+		fail("invalid prefix %q: must be upper case letters, digits and underscores", s.Prefix)
+	}
+
+	if s.Receiver != "" && !token.IsIdentifier(s.Receiver) {
+		fail("invalid receiver %q", s.Receiver)
+	}
+
+	if s.Accessor != "" {
+		switch {
+		case !token.IsIdentifier(s.Accessor):
+			fail("invalid accessor %q", s.Accessor)
+		case s.Receiver == "":
+			fail("accessor %q requires a receiver", s.Accessor)
+		}
+	}
+
+	for name := range s.Shapes {
+		if !token.IsIdentifier(name) {
+			fail("invalid shape type name %q", name)
+		}
+	}
+
+	identifiers := make(map[string]string)
+	declare := func(owner string, ids []string) {
+		for _, id := range ids {
+			if prev, ok := identifiers[id]; ok {
+				fail("%s generates %s which collides with %s", owner, id, prev)
+				continue
+			}
+			identifiers[id] = owner
+		}
+	}
+
+	if s.Accessor != "" {
+		iface, fake := s.accessorNames()
+		declare(fmt.Sprintf("accessor %q", s.Accessor), []string{iface, fake})
+	}
+
+	for _, e := range s.Enums {
+		owner := fmt.Sprintf("enum %q", e.Name)
+		if !token.IsExported(e.Name) || !token.IsIdentifier(e.Name) {
+			fail("%s: name must be an exported identifier", owner)
+			continue
+		}
+
+		if len(e.Values) == 0 {
+			fail("%s: has no values", owner)
+		}
+
+		names := make(map[string]bool, len(e.Values))
+		values := make(map[string]bool, len(e.Values))
+		for _, v := range e.Values {
+			if !token.IsIdentifier(e.Name + v.Name) {
+				fail("%s: invalid value name %q", owner, v.Name)
+			}
+			if names[v.Name] {
+				fail("%s: duplicate value name %q", owner, v.Name)
+			}
+			names[v.Name] = true
+
+			// values are parsed case-insensitively so they must also be
+			// unique once folded
+			for _, key := range v.keys() {
+				if key == "" {
+					fail("%s: value %q has an empty value or alias", owner, v.Name)
+					continue
+				}
+				if values[key] {
+					fail("%s: duplicate value or alias %q", owner, key)
+				}
+				values[key] = true
+			}
+		}
+
+		declare(owner, e.identifiers())
+	}
+
+	envNames := make(map[string]string)
+	for _, v := range s.Variables {
+		owner := fmt.Sprintf("variable %q", v.Name)
+		if !token.IsIdentifier(strcase.ToCamel(v.Name)) || v.Name == "" {
+			fail("%s: invalid name", owner)
+			continue
+		}
+
+		if !variableKinds[v.Kind] {
+			fail("%s: unknown kind %q", owner, v.Kind)
+		}
+
+		switch v.kind() {
+		case KindJSON, KindText:
+			if !isTypeName(v.DecodedType) {
+				fail("%s: invalid decoded type %q", owner, v.DecodedType)
+			}
+		default:
+			if v.DecodedType != "" || v.DecodedPointer {
+				fail("%s: kind %q does not take a decoded type", owner, v.Kind)
+			}
+		}
+
+		env := strcase.ToScreamingSnake(v.Name)
+		switch {
+		case v.Key != "":
+			env = "PLATFORM_VARIABLES[" + v.Key + "]"
+		case v.NoPrefix:
+		case s.Prefix != "":
+			env = s.Prefix + env
+		default:
+			env = "{prefix}" + env
+		}
+		if prev, ok := envNames[env]; ok {
+			fail("%s: reads %s which is already read by %s", owner, env, prev)
+		}
+		envNames[env] = owner
+
+		aliases := make(map[string]bool, len(v.Aliases))
+		valid := Variable{Name: v.Name}
+		for _, a := range v.Aliases {
+			switch {
+			case !token.IsIdentifier(strcase.ToCamel(a)) || a == "":
+				fail("%s: invalid alias %q", owner, a)
+			case strcase.ToCamel(a) == strcase.ToCamel(v.Name):
+				fail("%s: alias %q is the variable itself", owner, a)
+			case aliases[a]:
+				fail("%s: duplicate alias %q", owner, a)
+			default:
+				valid.Aliases = append(valid.Aliases, a)
+			}
+			aliases[a] = true
+		}
+
+		declare(owner, valid.identifiers())
+	}
+
+	return result
+}
+
+// isTypeName reports whether name is an identifier, optionally qualified with
+// an import path.
+func isTypeName(name string) bool {
+	if idx := strings.LastIndex(name, "."); idx >= 0 {
+		return idx > 0 && token.IsExported(name[idx+1:]) && token.IsIdentifier(name[idx+1:])
+	}
+	return token.IsIdentifier(name)
+}
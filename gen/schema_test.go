@@ -0,0 +1,165 @@
+package gen
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadSchema(t *testing.T) {
+	schema, err := LoadSchema("../schema.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, "pshgo", schema.Package)
+	assert.NotEmpty(t, schema.Enums)
+	assert.NotEmpty(t, schema.Variables)
+
+	_, err = LoadSchema("does-not-exist.yaml")
+	assert.Error(t, err)
+}
+
+func TestParseSchema(t *testing.T) {
+	cases := []struct {
+		name   string
+		data   string
+		errors int
+	}{
+		{
+			name: "json",
+			data: `{"package": "main", "enums": [{"name": "Color", "values": [{"name": "Red", "value": "red"}]}], "variables": [{"name": "Color", "kind": "text", "decoded_type": "Color"}]}`,
+		},
+		{
+			name: "yaml",
+			data: "package: main\nvariables:\n- name: Debug\n  kind: bool\n- name: Hosts\n  kind: list\n  aliases: [Servers]\n",
+		},
+		{
+			name:   "syntax",
+			data:   "package: [",
+			errors: 1,
+		},
+		{
+			name:   "unknown field",
+			data:   "package: main\nvariable: []\n",
+			errors: 1,
+		},
+		{
+			name:   "package",
+			data:   "package: 1main\n",
+			errors: 1,
+		},
+		{
+			name:   "duplicate enum",
+			data:   "package: main\nenums:\n- name: Color\n  values: [{name: Red, value: red}]\n- name: Color\n  values: [{name: Blue, value: blue}]\n",
+			errors: 6,
+		},
+		{
+			name:   "enum values",
+			data:   "package: main\nenums:\n- name: Color\n  values: [{name: Red, value: red}, {name: Red, value: red}]\n",
+			errors: 3,
+		},
+		{
+			name:   "enum aliases",
+			data:   "package: main\nenums:\n- name: Color\n  values: [{name: Red, value: red, aliases: [RED, '']}, {name: Blue, value: blue, aliases: [navy]}]\n",
+			errors: 2,
+		},
+		{
+			name:   "unexported enum",
+			data:   "package: main\nenums:\n- name: color\n  values: [{name: Red, value: red}]\n",
+			errors: 1,
+		},
+		{
+			name:   "colliding identifiers",
+			data:   "package: main\nenums:\n- name: Color\n  values: [{name: Red, value: red}]\n- name: ColorRed\n  values: [{name: Dark, value: dark}]\n",
+			errors: 1,
+		},
+		{
+			name:   "duplicate variable",
+			data:   "package: main\nvariables:\n- name: Branch\n- name: Branch\n",
+			errors: 3,
+		},
+		{
+			name:   "bad aliases",
+			data:   "package: main\nvariables:\n- name: Branch\n  aliases: [branch, Ref, Ref, '']\n",
+			errors: 3,
+		},
+		{
+			name:   "alias collides",
+			data:   "package: main\nvariables:\n- name: Branch\n  aliases: [Ref]\n- name: Ref\n",
+			errors: 2,
+		},
+		{
+			name: "downstream",
+			data: "package: config\nimport_path: example.com/app/config\nprefix: APP_\nreceiver: Env\nvariables:\n- name: Routes\n  decoded_type: github.com/demosdemon/pshgo.Routes\n- name: Mailer\n  key: app:mailer\n  decoded_type: Mailer\n",
+		},
+		{
+			name:   "downstream errors",
+			data:   "package: config\nprefix: app\nreceiver: 1Env\nvariables:\n- name: Routes\n  decoded_type: pshgo.routes\n",
+			errors: 3,
+		},
+		{
+			name:   "accessor",
+			data:   "package: config\naccessor: Accessor\nenums:\n- name: FakeAccessor\n  values: [{name: A, value: a}]\n",
+			errors: 2,
+		},
+		{
+			name:   "kind",
+			data:   "package: main\nvariables:\n- name: Branch\n  kind: bogus\n- name: Routes\n  kind: json\n- name: Port\n  kind: int\n  decoded_type: Port\n",
+			errors: 3,
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			_, err := ParseSchema([]byte(c.data))
+			if c.errors == 0 {
+				assert.NoError(t, err)
+				return
+			}
+
+			require.Error(t, err)
+			if merr, ok := err.(*multierror.Error); ok {
+				assert.Len(t, merr.Errors, c.errors, "%v", err)
+			} else {
+				assert.Equal(t, 1, c.errors, "%v", err)
+			}
+		})
+	}
+}
+
+func TestExecute_Schema(t *testing.T) {
+	tmp, err := ioutil.TempFile("", "*.yaml")
+	require.NoError(t, err)
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.WriteString("package: main\nvariables:\n- name: Branch\n")
+	require.NoError(t, err)
+	require.NoError(t, tmp.Close())
+
+	stdout, stderr, err := captureOutput(func() {
+		Execute([]string{"-schema", tmp.Name(), "-diff", "-package", "config", "-prefix", "APP_"}, nil)
+	})
+	assert.NoError(t, err)
+	assert.Contains(t, stdout, "+package config\n")
+	assert.Contains(t, stdout, "func LookupBranch(p pshgo.PlatformProvider) (string, bool) {")
+	assert.Contains(t, stdout, `name := "APP_BRANCH"`)
+	assert.Equal(t, "", stderr)
+
+	_, _, err = captureOutput(func() {
+		Execute([]string{"-schema", tmp.Name(), "-prefix", "app-"}, nil)
+	})
+	assert.Equal(t, exit(1), err)
+
+	_, _, err = captureOutput(func() {
+		Execute([]string{"-schema", tmp.Name() + ".missing"}, nil)
+	})
+	assert.Equal(t, exit(1), err)
+
+	_, _, err = captureOutput(func() {
+		Execute([]string{"-path", "/dev/null"}, nil)
+	})
+	assert.Equal(t, exit(1), err)
+}
@@ -0,0 +1,428 @@
+package gen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const definitionsRef = "#/definitions/"
+
+// Shape is the language neutral description of a JSON value, expressed as a
+// subset of JSON Schema. It is derived from the Go types decoded by the
+// variables and rendered as JSON Schema or TypeScript.
+type Shape struct {
+	Ref                  string            `json:"$ref,omitempty" yaml:"$ref,omitempty"`
+	Description          string            `json:"description,omitempty" yaml:"description,omitempty"`
+	Type                 string            `json:"type,omitempty" yaml:"type,omitempty"`
+	Format               string            `json:"format,omitempty" yaml:"format,omitempty"`
+	Enum                 []string          `json:"enum,omitempty" yaml:"enum,omitempty"`
+	Examples             []string          `json:"examples,omitempty" yaml:"examples,omitempty"`
+	Items                *Shape            `json:"items,omitempty" yaml:"items,omitempty"`
+	Properties           map[string]*Shape `json:"properties,omitempty" yaml:"properties,omitempty"`
+	Required             []string          `json:"required,omitempty" yaml:"required,omitempty"`
+	AdditionalProperties *Shape            `json:"additionalProperties,omitempty" yaml:"additionalProperties,omitempty"`
+	OneOf                []*Shape          `json:"oneOf,omitempty" yaml:"oneOf,omitempty"`
+
+	// order is the declaration order of Properties
+	order []string
+}
+
+// propertyNames returns the property names in declaration order, falling back
+// to sorted order for shapes loaded from a schema file.
+func (s *Shape) propertyNames() []string {
+	if len(s.order) == len(s.Properties) {
+		return s.order
+	}
+
+	rv := make([]string, 0, len(s.Properties))
+	for k := range s.Properties {
+		rv = append(rv, k)
+	}
+	sort.Strings(rv)
+	return rv
+}
+
+// refs returns the names of the definitions referenced by the shape.
+func (s *Shape) refs() []string {
+	if s == nil {
+		return nil
+	}
+
+	var rv []string
+	if name := strings.TrimPrefix(s.Ref, definitionsRef); name != s.Ref {
+		rv = append(rv, name)
+	}
+	rv = append(rv, s.Items.refs()...)
+	rv = append(rv, s.AdditionalProperties.refs()...)
+	for _, name := range s.propertyNames() {
+		rv = append(rv, s.Properties[name].refs()...)
+	}
+	for _, v := range s.OneOf {
+		rv = append(rv, v.refs()...)
+	}
+	return rv
+}
+
+type typeDecl struct {
+	spec *ast.TypeSpec
+	doc  string
+	text bool
+	json bool
+}
+
+// TypeSet holds the type declarations of a Go package read from source.
+type TypeSet struct {
+	decls map[string]*typeDecl
+}
+
+// LoadTypes parses the non-test Go files of dir and collects their type
+// declarations.
+func LoadTypes(dir string) (*TypeSet, error) {
+	logrus.WithField("dir", dir).Trace("LoadTypes")
+
+	fset := token.NewFileSet()
+	filter := func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}
+
+	pkgs, err := parser.ParseDir(fset, dir, filter, parser.ParseComments)
+	if err != nil {
+		return nil, errors.Wrap(err, "error parsing types")
+	}
+
+	ts := &TypeSet{decls: make(map[string]*typeDecl)}
+	methods := make(map[string][]string)
+
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				switch decl := decl.(type) {
+				case *ast.GenDecl:
+					if decl.Tok != token.TYPE {
+						continue
+					}
+					for _, spec := range decl.Specs {
+						spec := spec.(*ast.TypeSpec)
+						doc := spec.Doc.Text()
+						if doc == "" && len(decl.Specs) == 1 {
+							doc = decl.Doc.Text()
+						}
+						ts.decls[spec.Name.Name] = &typeDecl{spec: spec, doc: strings.TrimSpace(doc)}
+					}
+				case *ast.FuncDecl:
+					if decl.Recv == nil || len(decl.Recv.List) == 0 {
+						continue
+					}
+					recv := decl.Recv.List[0].Type
+					if star, ok := recv.(*ast.StarExpr); ok {
+						recv = star.X
+					}
+					if id, ok := recv.(*ast.Ident); ok {
+						methods[id.Name] = append(methods[id.Name], decl.Name.Name)
+					}
+				}
+			}
+		}
+	}
+
+	for name, list := range methods {
+		decl, ok := ts.decls[name]
+		if !ok {
+			continue
+		}
+		for _, m := range list {
+			switch m {
+			case "MarshalText", "UnmarshalText":
+				decl.text = true
+			case "MarshalJSON", "UnmarshalJSON":
+				decl.json = true
+			}
+		}
+	}
+
+	return ts, nil
+}
+
+// shapes builds the definitions reachable from a set of root types.
+type shapes struct {
+	schema      *Schema
+	types       *TypeSet
+	definitions map[string]*Shape
+}
+
+func newShapes(s *Schema, types *TypeSet) *shapes {
+	if types == nil {
+		types = &TypeSet{}
+	}
+	return &shapes{
+		schema:      s,
+		types:       types,
+		definitions: make(map[string]*Shape),
+	}
+}
+
+func (sh *shapes) enum(name string) (Enum, bool) {
+	for _, e := range sh.schema.Enums {
+		if e.Name == name {
+			return e, true
+		}
+	}
+	return Enum{}, false
+}
+
+// define adds the definition of a named type and everything it references.
+func (sh *shapes) define(name string) {
+	if _, ok := sh.definitions[name]; ok {
+		return
+	}
+
+	// reserve the name first so recursive types terminate
+	sh.definitions[name] = &Shape{}
+
+	var rv *Shape
+	if override, ok := sh.schema.Shapes[name]; ok {
+		rv = override
+	} else if e, ok := sh.enum(name); ok {
+		rv = &Shape{Type: "string"}
+		for _, v := range e.Values {
+			if e.PreserveUnknown {
+				rv.Examples = append(rv.Examples, v.Value)
+			} else {
+				rv.Enum = append(rv.Enum, v.Value)
+			}
+		}
+	} else if decl, ok := sh.types.decls[name]; ok {
+		switch {
+		case decl.text:
+			rv = &Shape{Type: "string"}
+		case decl.json:
+			logrus.WithField("type", name).Warn("type has a custom JSON encoding; add a shape to the schema")
+			rv = &Shape{}
+		default:
+			rv = sh.expr(decl.spec.Type)
+		}
+		if rv.Description == "" {
+			rv.Description = decl.doc
+		}
+	} else {
+		logrus.WithField("type", name).Warn("unknown type")
+		rv = &Shape{}
+	}
+
+	*sh.definitions[name] = *rv
+
+	for _, ref := range rv.refs() {
+		sh.define(ref)
+	}
+}
+
+// named returns the shape of a type referred to by name.
+func (sh *shapes) named(name string) *Shape {
+	switch name {
+	case "string":
+		return &Shape{Type: "string"}
+	case "bool":
+		return &Shape{Type: "boolean"}
+	case "int", "int8", "int16", "int32", "int64",
+		"uint", "uint8", "uint16", "uint32", "uint64", "uintptr", "byte", "rune":
+		return &Shape{Type: "integer"}
+	case "float32", "float64":
+		return &Shape{Type: "number"}
+	case "error", "any":
+		return &Shape{}
+	}
+
+	sh.define(name)
+	return &Shape{Ref: definitionsRef + name}
+}
+
+// qualified returns the shape of a type declared in another package.
+func (sh *shapes) qualified(pkg, name string) *Shape {
+	switch pkg + "." + name {
+	case "time.Time":
+		return &Shape{Type: "string", Format: "date-time"}
+	case "time.Duration":
+		return &Shape{Type: "integer"}
+	case "url.URL":
+		return &Shape{Type: "string", Format: "uri"}
+	case "json.RawMessage":
+		return &Shape{}
+	}
+
+	logrus.WithField("type", pkg+"."+name).Warn("unknown type")
+	return &Shape{}
+}
+
+func (sh *shapes) expr(expr ast.Expr) *Shape {
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		return sh.named(expr.Name)
+	case *ast.SelectorExpr:
+		if pkg, ok := expr.X.(*ast.Ident); ok {
+			return sh.qualified(pkg.Name, expr.Sel.Name)
+		}
+	case *ast.StarExpr:
+		return &Shape{OneOf: []*Shape{sh.expr(expr.X), {Type: "null"}}}
+	case *ast.ArrayType:
+		if id, ok := expr.Elt.(*ast.Ident); ok && id.Name == "byte" {
+			return &Shape{Type: "string", Format: "byte"}
+		}
+		return &Shape{Type: "array", Items: sh.expr(expr.Elt)}
+	case *ast.MapType:
+		return &Shape{Type: "object", AdditionalProperties: sh.expr(expr.Value)}
+	case *ast.InterfaceType:
+		return &Shape{}
+	case *ast.StructType:
+		rv := &Shape{Type: "object", Properties: make(map[string]*Shape)}
+		sh.fields(rv, expr)
+		return rv
+	}
+
+	logrus.WithField("expr", fmt.Sprintf("%T", expr)).Warn("unsupported type expression")
+	return &Shape{}
+}
+
+// fields adds the JSON properties of a struct, flattening embedded structs
+// the way encoding/json does.
+func (sh *shapes) fields(rv *Shape, st *ast.StructType) {
+	for _, field := range st.Fields.List {
+		var tag string
+		if field.Tag != nil {
+			tag, _ = strconv.Unquote(field.Tag.Value)
+		}
+		jsonTag := reflect.StructTag(tag).Get("json")
+		if jsonTag == "-" {
+			continue
+		}
+
+		name, opts := jsonTag, ""
+		if idx := strings.Index(jsonTag, ","); idx >= 0 {
+			name, opts = jsonTag[:idx], jsonTag[idx+1:]
+		}
+
+		if len(field.Names) == 0 {
+			if name == "" {
+				if embedded := sh.embedded(field.Type); embedded != nil {
+					sh.fields(rv, embedded)
+					continue
+				}
+			}
+			if name == "" {
+				name = embeddedName(field.Type)
+			}
+			sh.property(rv, name, opts, field)
+			continue
+		}
+
+		for _, id := range field.Names {
+			if !id.IsExported() {
+				continue
+			}
+			fieldName := name
+			if fieldName == "" {
+				fieldName = id.Name
+			}
+			sh.property(rv, fieldName, opts, field)
+		}
+	}
+}
+
+func (sh *shapes) property(rv *Shape, name, opts string, field *ast.Field) {
+	if _, ok := rv.Properties[name]; ok {
+		return
+	}
+
+	p := sh.expr(field.Type)
+	if strings.Contains(","+opts+",", ",string,") {
+		p = &Shape{Type: "string"}
+	}
+	if doc := strings.TrimSpace(field.Doc.Text()); doc != "" && p.Ref == "" {
+		p.Description = doc
+	}
+
+	rv.Properties[name] = p
+	rv.order = append(rv.order, name)
+	if !strings.Contains(","+opts+",", ",omitempty,") {
+		rv.Required = append(rv.Required, name)
+	}
+}
+
+// embedded returns the struct type of an embedded field declared in the
+// package, or nil when it is not a struct or encodes itself.
+func (sh *shapes) embedded(expr ast.Expr) *ast.StructType {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+
+	id, ok := expr.(*ast.Ident)
+	if !ok {
+		return nil
+	}
+
+	decl, ok := sh.types.decls[id.Name]
+	if !ok || decl.text || decl.json {
+		return nil
+	}
+
+	st, _ := decl.spec.Type.(*ast.StructType)
+	return st
+}
+
+func embeddedName(expr ast.Expr) string {
+	switch expr := expr.(type) {
+	case *ast.StarExpr:
+		return embeddedName(expr.X)
+	case *ast.SelectorExpr:
+		return expr.Sel.Name
+	case *ast.Ident:
+		return expr.Name
+	}
+	return ""
+}
+
+// decodedName returns the unqualified name of the decoded type of a variable.
+func (v Variable) decodedName() string {
+	name := v.DecodedType
+	if idx := strings.LastIndex(name, "."); idx >= 0 {
+		name = name[idx+1:]
+	}
+	return name
+}
+
+// build collects the definitions of every enum and every type decoded from
+// JSON by a variable.
+func (sh *shapes) build() {
+	for _, e := range sh.schema.Enums {
+		sh.define(e.Name)
+	}
+
+	for _, v := range sh.schema.Variables {
+		switch v.kind() {
+		case KindJSON:
+			sh.define(v.decodedName())
+		case KindText:
+			if _, ok := sh.enum(v.DecodedType); ok {
+				sh.define(v.DecodedType)
+			}
+		}
+	}
+}
+
+func (sh *shapes) names() []string {
+	rv := make([]string, 0, len(sh.definitions))
+	for k := range sh.definitions {
+		rv = append(rv, k)
+	}
+	sort.Strings(rv)
+	return rv
+}
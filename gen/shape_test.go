@@ -0,0 +1,183 @@
+package gen
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testTypes = `package config
+
+import "time"
+
+// Config is the decoded configuration.
+type Config struct {
+	Base
+	Name    string            ` + "`json:\"name\"`" + `
+	Color   Color             ` + "`json:\"color\"`" + `
+	Tags    []string          ` + "`json:\"tags,omitempty\"`" + `
+	Limits  map[string]int    ` + "`json:\"limits\"`" + `
+	Timeout *Timeout          ` + "`json:\"timeout\"`" + `
+	Started time.Time         ` + "`json:\"started\"`" + `
+	Extra   interface{}       ` + "`json:\"extra\"`" + `
+	Custom  Custom            ` + "`json:\"custom\"`" + `
+	Ignored string            ` + "`json:\"-\"`" + `
+	private string
+}
+
+type Base struct {
+	ID int ` + "`json:\"id,string\"`" + `
+}
+
+type Color uint8
+
+type Timeout struct{ time.Duration }
+
+func (t Timeout) MarshalText() ([]byte, error) { return nil, nil }
+
+type Custom struct{}
+
+func (c Custom) MarshalJSON() ([]byte, error) { return nil, nil }
+`
+
+func testSchema(tb testing.TB) (*Schema, *TypeSet) {
+	dir, err := ioutil.TempDir("", "types")
+	require.NoError(tb, err)
+	defer os.RemoveAll(dir)
+
+	require.NoError(tb, ioutil.WriteFile(filepath.Join(dir, "types.go"), []byte(testTypes), 0666))
+	require.NoError(tb, ioutil.WriteFile(filepath.Join(dir, "types_test.go"), []byte("package config\n\ntype Unused struct{}\n"), 0666))
+
+	types, err := LoadTypes(dir)
+	require.NoError(tb, err)
+
+	schema := &Schema{
+		Package: "config",
+		Enums: Enums{
+			{Name: "Color", Values: EnumValues{{Name: "Red", Value: "red", Aliases: []string{"Crimson"}}, {Name: "Blue", Value: "blue"}}},
+		},
+		Variables: Variables{
+			{Name: "Config", DecodedType: "Config", DecodedPointer: true},
+			{Name: "Color", Kind: KindText, DecodedType: "Color"},
+			{Name: "Debug", Kind: KindBool, Aliases: []string{"Verbose"}},
+			{Name: "Home", Kind: KindPath, NoPrefix: true},
+			{Name: "Secret", Key: "app:secret"},
+		},
+		Shapes: map[string]*Shape{
+			"Custom": {OneOf: []*Shape{{Type: "boolean"}, {Type: "string"}}},
+		},
+	}
+
+	return schema, types
+}
+
+func TestLoadTypes(t *testing.T) {
+	_, types := testSchema(t)
+	assert.Contains(t, types.decls, "Config")
+	assert.NotContains(t, types.decls, "Unused")
+	assert.True(t, types.decls["Timeout"].text)
+	assert.True(t, types.decls["Custom"].json)
+	assert.Equal(t, "Config is the decoded configuration.", types.decls["Config"].doc)
+
+	_, err := LoadTypes("does-not-exist")
+	assert.Error(t, err)
+}
+
+func TestJSONSchema_Render(t *testing.T) {
+	schema, types := testSchema(t)
+
+	var buf bytes.Buffer
+	require.NoError(t, JSONSchema{Schema: schema, Types: types}.Render(&buf))
+
+	var doc struct {
+		Properties  map[string]*Shape `json:"properties"`
+		Definitions map[string]*Shape `json:"definitions"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+
+	assert.Equal(t, map[string]*Shape{
+		"CONFIG": {Ref: "#/definitions/Config"},
+		"COLOR":  {Ref: "#/definitions/Color"},
+	}, doc.Properties)
+
+	assert.Equal(t, &Shape{Type: "string", Enum: []string{"red", "blue"}}, doc.Definitions["Color"])
+	assert.Equal(t, &Shape{Type: "string"}, doc.Definitions["Timeout"])
+	assert.Equal(t, &Shape{OneOf: []*Shape{{Type: "boolean"}, {Type: "string"}}}, doc.Definitions["Custom"])
+
+	config := doc.Definitions["Config"]
+	require.NotNil(t, config)
+	assert.Equal(t, "Config is the decoded configuration.", config.Description)
+	assert.Equal(t, []string{"id", "name", "color", "limits", "timeout", "started", "extra", "custom"}, config.Required)
+	assert.Equal(t, &Shape{Type: "string"}, config.Properties["id"])
+	assert.Equal(t, &Shape{Type: "array", Items: &Shape{Type: "string"}}, config.Properties["tags"])
+	assert.Equal(t, &Shape{Type: "object", AdditionalProperties: &Shape{Type: "integer"}}, config.Properties["limits"])
+	assert.Equal(t, &Shape{OneOf: []*Shape{{Ref: "#/definitions/Timeout"}, {Type: "null"}}}, config.Properties["timeout"])
+	assert.Equal(t, &Shape{Type: "string", Format: "date-time"}, config.Properties["started"])
+	assert.Equal(t, &Shape{}, config.Properties["extra"])
+	assert.NotContains(t, config.Properties, "Ignored")
+	assert.NotContains(t, config.Properties, "private")
+}
+
+func TestTypeScript_Render(t *testing.T) {
+	schema, types := testSchema(t)
+
+	var buf bytes.Buffer
+	require.NoError(t, TypeScript{Schema: schema, Types: types}.Render(&buf))
+	out := buf.String()
+
+	for _, want := range []string{
+		"// Code generated by pshgo-gen. DO NOT EDIT.",
+		"/** Config is the decoded configuration. */\nexport interface Config {\n  id: string;\n  name: string;\n  color: Color;\n  tags?: string[];\n",
+		"  timeout: Timeout | null;\n",
+		"  extra: unknown;\n",
+		`export type Color = "red" | "blue";`,
+		`export const ColorValues: readonly Color[] = ["red", "blue"];`,
+		"export type Custom = boolean | string;",
+		"export function lookupConfig(env: Env, prefix: string = DEFAULT_PREFIX): Config | undefined {",
+		`const value = env[prefix + "CONFIG"];`,
+		"decodeJSON<Config>(value)",
+		"const ColorNames: ReadonlyMap<string, Color> = new Map<string, Color>([\n  [\"red\", \"red\"],\n  [\"crimson\", \"red\"],\n  [\"blue\", \"blue\"],\n]);",
+		"export function parseColor(value: string): Color | undefined {\n  return ColorNames.get(value.toLowerCase());\n}",
+		"return value === undefined ? undefined : parseColor(value);",
+		"export function lookupDebug(env: Env, prefix: string = DEFAULT_PREFIX): boolean | undefined {",
+		"export const lookupVerbose = lookupDebug;",
+		"export function lookupHome(env: Env): string | undefined {",
+		`const value = lookupVariable(env, "app:secret", prefix);`,
+	} {
+		assert.Contains(t, out, want)
+	}
+
+	schema.Enums[0].PreserveUnknown = true
+	buf.Reset()
+	require.NoError(t, TypeScript{Schema: schema, Types: types}.Render(&buf))
+	assert.Contains(t, buf.String(), "export function parseColor(value: string): Color {\n  const known = ColorNames.get(value.toLowerCase());\n  return known === undefined ? value as Color : known;\n}")
+
+	schema.Enums[0].PreserveUnknown = false
+	schema.Prefix = "APP_"
+	buf.Reset()
+	require.NoError(t, TypeScript{Schema: schema, Types: types}.Render(&buf))
+	assert.Contains(t, buf.String(), "export function lookupDebug(env: Env): boolean | undefined {\n  const value = env[\"APP_DEBUG\"];")
+}
+
+func TestSchema_Renderer(t *testing.T) {
+	schema := &Schema{Package: "main"}
+	for target, want := range map[string]Render{
+		"":               schema,
+		TargetGo:         schema,
+		TargetTypeScript: TypeScript{Schema: schema},
+		TargetJSONSchema: JSONSchema{Schema: schema},
+	} {
+		got, err := schema.Renderer(target, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+
+	_, err := schema.Renderer("python", nil)
+	assert.Error(t, err)
+}
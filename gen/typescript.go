@@ -0,0 +1,324 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/iancoleman/strcase"
+)
+
+// TypeScript renders TypeScript definitions of the decoded types and a
+// lookupX function decoding each variable from an environment object such as
+// process.env.
+type TypeScript struct {
+	Schema *Schema
+	Types  *TypeSet
+}
+
+var tsIdentifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+const typeScriptHelpers = `export type Env = { [name: string]: string | undefined };
+
+export const DEFAULT_PREFIX = "PLATFORM_";
+
+export function decodeBase64(value: string): Uint8Array | undefined {
+  try {
+    const binary = atob(value);
+    const bytes = new Uint8Array(binary.length);
+    for (let i = 0; i < binary.length; i++) {
+      bytes[i] = binary.charCodeAt(i);
+    }
+    return bytes;
+  } catch {
+    return undefined;
+  }
+}
+
+export function decodeJSON<T>(value: string): T | undefined {
+  const bytes = decodeBase64(value);
+  if (bytes === undefined) {
+    return undefined;
+  }
+  try {
+    return JSON.parse(new TextDecoder().decode(bytes)) as T;
+  } catch {
+    return undefined;
+  }
+}
+
+export function parseInteger(value: string): number | undefined {
+  const trimmed = value.trim();
+  return /^[+-]?[0-9]+$/.test(trimmed) ? Number(trimmed) : undefined;
+}
+
+export function parseBool(value: string): boolean | undefined {
+  switch (value.trim()) {
+    case "1": case "t": case "T": case "true": case "TRUE": case "True":
+      return true;
+    case "0": case "f": case "F": case "false": case "FALSE": case "False":
+      return false;
+  }
+  return undefined;
+}
+
+export function parseList(value: string): string[] {
+  return value.split(",").map((item) => item.trim()).filter((item) => item !== "");
+}
+
+const durationUnits: { [unit: string]: number } = {
+  ns: 1e-6, us: 1e-3, "µs": 1e-3, "μs": 1e-3, ms: 1, s: 1e3, m: 60e3, h: 3600e3,
+};
+
+// parseDuration parses a Go duration string into milliseconds.
+export function parseDuration(value: string): number | undefined {
+  const trimmed = value.trim();
+  const match = /^([+-]?)((?:[0-9]*\.?[0-9]+[a-zµμ]+)+|0)$/.exec(trimmed);
+  if (match === null) {
+    return undefined;
+  }
+  let total = 0;
+  const re = /([0-9]*\.?[0-9]+)([a-zµμ]+)/g;
+  for (let part = re.exec(match[2]); part !== null; part = re.exec(match[2])) {
+    const unit = durationUnits[part[2]];
+    if (unit === undefined) {
+      return undefined;
+    }
+    total += Number(part[1]) * unit;
+  }
+  return match[1] === "-" ? -total : total;
+}
+
+export function lookupVariable(env: Env, key: string, prefix: string = DEFAULT_PREFIX): string | undefined {
+  const value = env[prefix + "VARIABLES"];
+  const vars = value === undefined ? undefined : decodeJSON<{ [key: string]: unknown }>(value);
+  if (vars === undefined || vars === null || !(key in vars)) {
+    return undefined;
+  }
+  const v = vars[key];
+  return typeof v === "string" ? v : JSON.stringify(v);
+}
+`
+
+func (t TypeScript) Render(w io.Writer) error {
+	sh := newShapes(t.Schema, t.Types)
+	sh.build()
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by pshgo-gen. DO NOT EDIT.\n\n")
+	buf.WriteString("/* eslint-disable */\n\n")
+	buf.WriteString(typeScriptHelpers)
+
+	for _, name := range sh.names() {
+		buf.WriteString("\n")
+		t.definition(&buf, sh, name, sh.definitions[name])
+	}
+
+	for _, v := range t.Schema.Variables {
+		buf.WriteString("\n")
+		t.variable(&buf, sh, v)
+	}
+
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+func (t TypeScript) definition(buf *bytes.Buffer, sh *shapes, name string, s *Shape) {
+	writeTSDoc(buf, "", s.Description)
+
+	if s.Type == "object" && s.Properties != nil {
+		fmt.Fprintf(buf, "export interface %s {\n", name)
+		writeTSProperties(buf, "  ", s)
+		buf.WriteString("}\n")
+		return
+	}
+
+	fmt.Fprintf(buf, "export type %s = %s;\n", name, tsType(s, ""))
+
+	if e, ok := sh.enum(name); ok {
+		values := make([]string, len(e.Values))
+		for idx, v := range e.Values {
+			values[idx] = strconv.Quote(v.Value)
+		}
+		fmt.Fprintf(buf, "\nexport const %sValues: readonly %s[] = [%s];\n", name, name, strings.Join(values, ", "))
+		t.enumParser(buf, e)
+	}
+}
+
+// enumParser renders parseX, which parses the names of an enum the way its Go
+// constructor does: case-insensitively, accepting the aliases of the values.
+func (t TypeScript) enumParser(buf *bytes.Buffer, e Enum) {
+	fmt.Fprintf(buf, "\nconst %sNames: ReadonlyMap<string, %s> = new Map<string, %s>([\n", e.Name, e.Name, e.Name)
+	for _, v := range e.Values {
+		for _, key := range v.keys() {
+			fmt.Fprintf(buf, "  [%s, %s],\n", strconv.Quote(key), strconv.Quote(v.Value))
+		}
+	}
+	buf.WriteString("]);\n")
+
+	if e.PreserveUnknown {
+		fmt.Fprintf(buf, "\nexport function parse%s(value: string): %s {\n", e.Name, e.Name)
+		fmt.Fprintf(buf, "  const known = %sNames.get(value.toLowerCase());\n", e.Name)
+		fmt.Fprintf(buf, "  return known === undefined ? value as %s : known;\n", e.Name)
+	} else {
+		fmt.Fprintf(buf, "\nexport function parse%s(value: string): %s | undefined {\n", e.Name, e.Name)
+		fmt.Fprintf(buf, "  return %sNames.get(value.toLowerCase());\n", e.Name)
+	}
+	buf.WriteString("}\n")
+}
+
+func (t TypeScript) variable(buf *bytes.Buffer, sh *shapes, v Variable) {
+	lookupName := "lookup" + strcase.ToCamel(v.Name)
+	env := strcase.ToScreamingSnake(v.Name)
+
+	params := "env: Env"
+	var read string
+	switch {
+	case v.Key != "":
+		params += ", prefix: string = DEFAULT_PREFIX"
+		read = fmt.Sprintf("lookupVariable(env, %s, prefix)", strconv.Quote(v.Key))
+	case v.NoPrefix:
+		read = fmt.Sprintf("env[%s]", strconv.Quote(env))
+	case t.Schema.Prefix != "":
+		read = fmt.Sprintf("env[%s]", strconv.Quote(t.Schema.Prefix+env))
+	default:
+		params += ", prefix: string = DEFAULT_PREFIX"
+		read = fmt.Sprintf("env[prefix + %s]", strconv.Quote(env))
+	}
+
+	rType, decode := "string", "value"
+	switch v.kind() {
+	case KindJSON:
+		rType = "unknown"
+		if _, ok := sh.definitions[v.decodedName()]; ok {
+			rType = v.decodedName()
+		}
+		decode = fmt.Sprintf("decodeJSON<%s>(value)", rType)
+	case KindText:
+		if e, ok := sh.enum(v.DecodedType); ok {
+			rType = e.Name
+			decode = fmt.Sprintf("parse%s(value)", rType)
+		}
+	case KindBase64:
+		rType, decode = "Uint8Array", "decodeBase64(value)"
+	case KindInt:
+		rType, decode = "number", "parseInteger(value)"
+	case KindBool:
+		rType, decode = "boolean", "parseBool(value)"
+	case KindPath:
+		decode = `value === "" ? undefined : value`
+	case KindList:
+		rType, decode = "string[]", "parseList(value)"
+	case KindDuration:
+		rType, decode = "number", "parseDuration(value)"
+	}
+
+	fmt.Fprintf(buf, "export function %s(%s): %s | undefined {\n", lookupName, params, rType)
+	fmt.Fprintf(buf, "  const value = %s;\n", read)
+	fmt.Fprintf(buf, "  return value === undefined ? undefined : %s;\n", decode)
+	buf.WriteString("}\n")
+
+	for _, a := range v.Aliases {
+		fmt.Fprintf(buf, "\nexport const lookup%s = %s;\n", strcase.ToCamel(a), lookupName)
+	}
+}
+
+func writeTSDoc(buf *bytes.Buffer, indent, doc string) {
+	if doc == "" {
+		return
+	}
+
+	lines := strings.Split(doc, "\n")
+	if len(lines) == 1 {
+		fmt.Fprintf(buf, "%s/** %s */\n", indent, doc)
+		return
+	}
+
+	fmt.Fprintf(buf, "%s/**\n", indent)
+	for _, line := range lines {
+		fmt.Fprintf(buf, "%s * %s\n", indent, line)
+	}
+	fmt.Fprintf(buf, "%s */\n", indent)
+}
+
+func writeTSProperties(buf *bytes.Buffer, indent string, s *Shape) {
+	required := make(map[string]bool, len(s.Required))
+	for _, name := range s.Required {
+		required[name] = true
+	}
+
+	for _, name := range s.propertyNames() {
+		p := s.Properties[name]
+		writeTSDoc(buf, indent, p.Description)
+
+		key := name
+		if !tsIdentifier.MatchString(name) {
+			key = strconv.Quote(name)
+		}
+		if !required[name] {
+			key += "?"
+		}
+
+		fmt.Fprintf(buf, "%s%s: %s;\n", indent, key, tsType(p, indent))
+	}
+}
+
+// tsType renders the TypeScript type expression of a shape.
+func tsType(s *Shape, indent string) string {
+	switch {
+	case s.Ref != "":
+		return strings.TrimPrefix(s.Ref, definitionsRef)
+	case len(s.OneOf) > 0:
+		types := make([]string, len(s.OneOf))
+		for idx, v := range s.OneOf {
+			types[idx] = tsType(v, indent)
+		}
+		return strings.Join(types, " | ")
+	}
+
+	switch s.Type {
+	case "string":
+		var types []string
+		for _, v := range append(append([]string(nil), s.Enum...), s.Examples...) {
+			types = append(types, strconv.Quote(v))
+		}
+		if len(s.Enum) == 0 {
+			// (string & {}) keeps the known values available to completion
+			if len(types) > 0 {
+				types = append(types, "(string & {})")
+			} else {
+				types = append(types, "string")
+			}
+		}
+		return strings.Join(types, " | ")
+	case "integer", "number":
+		return "number"
+	case "boolean":
+		return "boolean"
+	case "null":
+		return "null"
+	case "array":
+		item := tsType(s.Items, indent)
+		if strings.Contains(item, " | ") {
+			item = "(" + item + ")"
+		}
+		return item + "[]"
+	case "object":
+		if s.Properties != nil {
+			var buf bytes.Buffer
+			buf.WriteString("{\n")
+			writeTSProperties(&buf, indent+"  ", s)
+			buf.WriteString(indent + "}")
+			return buf.String()
+		}
+		if s.AdditionalProperties != nil {
+			return fmt.Sprintf("{ [key: string]: %s }", tsType(s.AdditionalProperties, indent))
+		}
+		return "{ [key: string]: unknown }"
+	}
+
+	return "unknown"
+}
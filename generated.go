@@ -1,11 +1,15 @@
-// This file is generated by the ./internal/gen command -- do not edit!
+// Code generated by pshgo-gen. DO NOT EDIT.
 
 package pshgo
 
 import (
+	"database/sql/driver"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
 
 	errors "github.com/pkg/errors"
 	logrus "github.com/sirupsen/logrus"
@@ -34,14 +38,26 @@ var (
 	}
 )
 
+func AccessLevelValues() []AccessLevel {
+	rv := make([]AccessLevel, totalAccessLevels)
+	for idx := range rv {
+		rv[idx] = AccessLevel(idx)
+	}
+	return rv
+}
+
 func NewAccessLevel(name string) (AccessLevel, error) {
-	if v, ok := accessLevelsMap[name]; ok {
+	if v, ok := accessLevelsMap[strings.ToLower(name)]; ok {
 		return v, nil
 	}
 
 	return 0, fmt.Errorf("unknown AccessLevel name %q", name)
 }
 
+func (v AccessLevel) IsValid() bool {
+	return v < totalAccessLevels
+}
+
 func (v AccessLevel) String() string {
 	if v < totalAccessLevels {
 		return accessLevels[v]
@@ -63,6 +79,38 @@ func (v AccessLevel) MarshalText() ([]byte, error) {
 	return nil, errors.New(v.String())
 }
 
+func (v *AccessLevel) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var text string
+	if err := unmarshal(&text); err != nil {
+		return err
+	}
+	return v.UnmarshalText([]byte(text))
+}
+
+func (v AccessLevel) MarshalYAML() (interface{}, error) {
+	text, err := v.MarshalText()
+	return string(text), err
+}
+
+func (v *AccessLevel) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case string:
+		return v.UnmarshalText([]byte(src))
+	case []byte:
+		return v.UnmarshalText(src)
+	default:
+		return fmt.Errorf("cannot scan %T into AccessLevel", src)
+	}
+}
+
+func (v AccessLevel) Value() (driver.Value, error) {
+	text, err := v.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return string(text), nil
+}
+
 type AccessType uint8
 
 const (
@@ -80,14 +128,26 @@ var (
 	}
 )
 
+func AccessTypeValues() []AccessType {
+	rv := make([]AccessType, totalAccessTypes)
+	for idx := range rv {
+		rv[idx] = AccessType(idx)
+	}
+	return rv
+}
+
 func NewAccessType(name string) (AccessType, error) {
-	if v, ok := accessTypesMap[name]; ok {
+	if v, ok := accessTypesMap[strings.ToLower(name)]; ok {
 		return v, nil
 	}
 
 	return 0, fmt.Errorf("unknown AccessType name %q", name)
 }
 
+func (v AccessType) IsValid() bool {
+	return v < totalAccessTypes
+}
+
 func (v AccessType) String() string {
 	if v < totalAccessTypes {
 		return accessTypes[v]
@@ -109,6 +169,38 @@ func (v AccessType) MarshalText() ([]byte, error) {
 	return nil, errors.New(v.String())
 }
 
+func (v *AccessType) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var text string
+	if err := unmarshal(&text); err != nil {
+		return err
+	}
+	return v.UnmarshalText([]byte(text))
+}
+
+func (v AccessType) MarshalYAML() (interface{}, error) {
+	text, err := v.MarshalText()
+	return string(text), err
+}
+
+func (v *AccessType) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case string:
+		return v.UnmarshalText([]byte(src))
+	case []byte:
+		return v.UnmarshalText(src)
+	default:
+		return fmt.Errorf("cannot scan %T into AccessType", src)
+	}
+}
+
+func (v AccessType) Value() (driver.Value, error) {
+	text, err := v.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return string(text), nil
+}
+
 type ApplicationMount uint8
 
 const (
@@ -132,14 +224,26 @@ var (
 	}
 )
 
+func ApplicationMountValues() []ApplicationMount {
+	rv := make([]ApplicationMount, totalApplicationMounts)
+	for idx := range rv {
+		rv[idx] = ApplicationMount(idx)
+	}
+	return rv
+}
+
 func NewApplicationMount(name string) (ApplicationMount, error) {
-	if v, ok := applicationMountsMap[name]; ok {
+	if v, ok := applicationMountsMap[strings.ToLower(name)]; ok {
 		return v, nil
 	}
 
 	return 0, fmt.Errorf("unknown ApplicationMount name %q", name)
 }
 
+func (v ApplicationMount) IsValid() bool {
+	return v < totalApplicationMounts
+}
+
 func (v ApplicationMount) String() string {
 	if v < totalApplicationMounts {
 		return applicationMounts[v]
@@ -161,6 +265,230 @@ func (v ApplicationMount) MarshalText() ([]byte, error) {
 	return nil, errors.New(v.String())
 }
 
+func (v *ApplicationMount) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var text string
+	if err := unmarshal(&text); err != nil {
+		return err
+	}
+	return v.UnmarshalText([]byte(text))
+}
+
+func (v ApplicationMount) MarshalYAML() (interface{}, error) {
+	text, err := v.MarshalText()
+	return string(text), err
+}
+
+func (v *ApplicationMount) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case string:
+		return v.UnmarshalText([]byte(src))
+	case []byte:
+		return v.UnmarshalText(src)
+	default:
+		return fmt.Errorf("cannot scan %T into ApplicationMount", src)
+	}
+}
+
+func (v ApplicationMount) Value() (driver.Value, error) {
+	text, err := v.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return string(text), nil
+}
+
+type ClientVerify uint8
+
+const (
+	ClientVerifySuccess ClientVerify = iota
+	ClientVerifyFailed
+	ClientVerifyNone
+	totalClientVerifies
+)
+
+var (
+	clientVerifies = [totalClientVerifies]string{
+		"SUCCESS",
+		"FAILED",
+		"NONE",
+	}
+
+	clientVerifiesMap = map[string]ClientVerify{
+		"success": ClientVerifySuccess,
+		"failed":  ClientVerifyFailed,
+		"none":    ClientVerifyNone,
+	}
+)
+
+func ClientVerifyValues() []ClientVerify {
+	rv := make([]ClientVerify, totalClientVerifies)
+	for idx := range rv {
+		rv[idx] = ClientVerify(idx)
+	}
+	return rv
+}
+
+func NewClientVerify(name string) (ClientVerify, error) {
+	if v, ok := clientVerifiesMap[strings.ToLower(name)]; ok {
+		return v, nil
+	}
+
+	return 0, fmt.Errorf("unknown ClientVerify name %q", name)
+}
+
+func (v ClientVerify) IsValid() bool {
+	return v < totalClientVerifies
+}
+
+func (v ClientVerify) String() string {
+	if v < totalClientVerifies {
+		return clientVerifies[v]
+	}
+
+	return fmt.Sprintf("unknown ClientVerify value %02x", uint8(v))
+}
+
+func (v *ClientVerify) UnmarshalText(text []byte) (err error) {
+	*v, err = NewClientVerify(string(text))
+	return err
+}
+
+func (v ClientVerify) MarshalText() ([]byte, error) {
+	if v < totalClientVerifies {
+		return []byte(clientVerifies[v]), nil
+	}
+
+	return nil, errors.New(v.String())
+}
+
+func (v *ClientVerify) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var text string
+	if err := unmarshal(&text); err != nil {
+		return err
+	}
+	return v.UnmarshalText([]byte(text))
+}
+
+func (v ClientVerify) MarshalYAML() (interface{}, error) {
+	text, err := v.MarshalText()
+	return string(text), err
+}
+
+func (v *ClientVerify) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case string:
+		return v.UnmarshalText([]byte(src))
+	case []byte:
+		return v.UnmarshalText(src)
+	default:
+		return fmt.Errorf("cannot scan %T into ClientVerify", src)
+	}
+}
+
+func (v ClientVerify) Value() (driver.Value, error) {
+	text, err := v.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return string(text), nil
+}
+
+type EnvironmentType uint8
+
+const (
+	EnvironmentTypeDevelopment EnvironmentType = iota
+	EnvironmentTypeStaging
+	EnvironmentTypeProduction
+	totalEnvironmentTypes
+)
+
+var (
+	environmentTypes = [totalEnvironmentTypes]string{
+		"development",
+		"staging",
+		"production",
+	}
+
+	environmentTypesMap = map[string]EnvironmentType{
+		"development": EnvironmentTypeDevelopment,
+		"staging":     EnvironmentTypeStaging,
+		"production":  EnvironmentTypeProduction,
+	}
+)
+
+func EnvironmentTypeValues() []EnvironmentType {
+	rv := make([]EnvironmentType, totalEnvironmentTypes)
+	for idx := range rv {
+		rv[idx] = EnvironmentType(idx)
+	}
+	return rv
+}
+
+func NewEnvironmentType(name string) (EnvironmentType, error) {
+	if v, ok := environmentTypesMap[strings.ToLower(name)]; ok {
+		return v, nil
+	}
+
+	return 0, fmt.Errorf("unknown EnvironmentType name %q", name)
+}
+
+func (v EnvironmentType) IsValid() bool {
+	return v < totalEnvironmentTypes
+}
+
+func (v EnvironmentType) String() string {
+	if v < totalEnvironmentTypes {
+		return environmentTypes[v]
+	}
+
+	return fmt.Sprintf("unknown EnvironmentType value %02x", uint8(v))
+}
+
+func (v *EnvironmentType) UnmarshalText(text []byte) (err error) {
+	*v, err = NewEnvironmentType(string(text))
+	return err
+}
+
+func (v EnvironmentType) MarshalText() ([]byte, error) {
+	if v < totalEnvironmentTypes {
+		return []byte(environmentTypes[v]), nil
+	}
+
+	return nil, errors.New(v.String())
+}
+
+func (v *EnvironmentType) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var text string
+	if err := unmarshal(&text); err != nil {
+		return err
+	}
+	return v.UnmarshalText([]byte(text))
+}
+
+func (v EnvironmentType) MarshalYAML() (interface{}, error) {
+	text, err := v.MarshalText()
+	return string(text), err
+}
+
+func (v *EnvironmentType) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case string:
+		return v.UnmarshalText([]byte(src))
+	case []byte:
+		return v.UnmarshalText(src)
+	default:
+		return fmt.Errorf("cannot scan %T into EnvironmentType", src)
+	}
+}
+
+func (v EnvironmentType) Value() (driver.Value, error) {
+	text, err := v.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return string(text), nil
+}
+
 type ServiceSize uint8
 
 const (
@@ -186,24 +514,36 @@ var (
 	}
 
 	serviceSizesMap = map[string]ServiceSize{
-		"AUTO": ServiceSizeAuto,
-		"S":    ServiceSizeSmall,
-		"M":    ServiceSizeMedium,
-		"L":    ServiceSizeLarge,
-		"XL":   ServiceSizeExtraLarge,
-		"2XL":  ServiceSizeDoubleExtraLarge,
-		"4XL":  ServiceSizeQuadrupleExtraLarge,
+		"auto": ServiceSizeAuto,
+		"s":    ServiceSizeSmall,
+		"m":    ServiceSizeMedium,
+		"l":    ServiceSizeLarge,
+		"xl":   ServiceSizeExtraLarge,
+		"2xl":  ServiceSizeDoubleExtraLarge,
+		"4xl":  ServiceSizeQuadrupleExtraLarge,
 	}
 )
 
+func ServiceSizeValues() []ServiceSize {
+	rv := make([]ServiceSize, totalServiceSizes)
+	for idx := range rv {
+		rv[idx] = ServiceSize(idx)
+	}
+	return rv
+}
+
 func NewServiceSize(name string) (ServiceSize, error) {
-	if v, ok := serviceSizesMap[name]; ok {
+	if v, ok := serviceSizesMap[strings.ToLower(name)]; ok {
 		return v, nil
 	}
 
 	return 0, fmt.Errorf("unknown ServiceSize name %q", name)
 }
 
+func (v ServiceSize) IsValid() bool {
+	return v < totalServiceSizes
+}
+
 func (v ServiceSize) String() string {
 	if v < totalServiceSizes {
 		return serviceSizes[v]
@@ -225,6 +565,38 @@ func (v ServiceSize) MarshalText() ([]byte, error) {
 	return nil, errors.New(v.String())
 }
 
+func (v *ServiceSize) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var text string
+	if err := unmarshal(&text); err != nil {
+		return err
+	}
+	return v.UnmarshalText([]byte(text))
+}
+
+func (v ServiceSize) MarshalYAML() (interface{}, error) {
+	text, err := v.MarshalText()
+	return string(text), err
+}
+
+func (v *ServiceSize) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case string:
+		return v.UnmarshalText([]byte(src))
+	case []byte:
+		return v.UnmarshalText(src)
+	default:
+		return fmt.Errorf("cannot scan %T into ServiceSize", src)
+	}
+}
+
+func (v ServiceSize) Value() (driver.Value, error) {
+	text, err := v.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return string(text), nil
+}
+
 type SocketFamily uint8
 
 const (
@@ -245,14 +617,26 @@ var (
 	}
 )
 
+func SocketFamilyValues() []SocketFamily {
+	rv := make([]SocketFamily, totalSocketFamilies)
+	for idx := range rv {
+		rv[idx] = SocketFamily(idx)
+	}
+	return rv
+}
+
 func NewSocketFamily(name string) (SocketFamily, error) {
-	if v, ok := socketFamiliesMap[name]; ok {
+	if v, ok := socketFamiliesMap[strings.ToLower(name)]; ok {
 		return v, nil
 	}
 
 	return 0, fmt.Errorf("unknown SocketFamily name %q", name)
 }
 
+func (v SocketFamily) IsValid() bool {
+	return v < totalSocketFamilies
+}
+
 func (v SocketFamily) String() string {
 	if v < totalSocketFamilies {
 		return socketFamilies[v]
@@ -274,6 +658,38 @@ func (v SocketFamily) MarshalText() ([]byte, error) {
 	return nil, errors.New(v.String())
 }
 
+func (v *SocketFamily) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var text string
+	if err := unmarshal(&text); err != nil {
+		return err
+	}
+	return v.UnmarshalText([]byte(text))
+}
+
+func (v SocketFamily) MarshalYAML() (interface{}, error) {
+	text, err := v.MarshalText()
+	return string(text), err
+}
+
+func (v *SocketFamily) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case string:
+		return v.UnmarshalText([]byte(src))
+	case []byte:
+		return v.UnmarshalText(src)
+	default:
+		return fmt.Errorf("cannot scan %T into SocketFamily", src)
+	}
+}
+
+func (v SocketFamily) Value() (driver.Value, error) {
+	text, err := v.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return string(text), nil
+}
+
 type SocketProtocol uint8
 
 const (
@@ -297,14 +713,26 @@ var (
 	}
 )
 
+func SocketProtocolValues() []SocketProtocol {
+	rv := make([]SocketProtocol, totalSocketProtocols)
+	for idx := range rv {
+		rv[idx] = SocketProtocol(idx)
+	}
+	return rv
+}
+
 func NewSocketProtocol(name string) (SocketProtocol, error) {
-	if v, ok := socketProtocolsMap[name]; ok {
+	if v, ok := socketProtocolsMap[strings.ToLower(name)]; ok {
 		return v, nil
 	}
 
 	return 0, fmt.Errorf("unknown SocketProtocol name %q", name)
 }
 
+func (v SocketProtocol) IsValid() bool {
+	return v < totalSocketProtocols
+}
+
 func (v SocketProtocol) String() string {
 	if v < totalSocketProtocols {
 		return socketProtocols[v]
@@ -326,6 +754,38 @@ func (v SocketProtocol) MarshalText() ([]byte, error) {
 	return nil, errors.New(v.String())
 }
 
+func (v *SocketProtocol) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var text string
+	if err := unmarshal(&text); err != nil {
+		return err
+	}
+	return v.UnmarshalText([]byte(text))
+}
+
+func (v SocketProtocol) MarshalYAML() (interface{}, error) {
+	text, err := v.MarshalText()
+	return string(text), err
+}
+
+func (v *SocketProtocol) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case string:
+		return v.UnmarshalText([]byte(src))
+	case []byte:
+		return v.UnmarshalText(src)
+	default:
+		return fmt.Errorf("cannot scan %T into SocketProtocol", src)
+	}
+}
+
+func (v SocketProtocol) Value() (driver.Value, error) {
+	text, err := v.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return string(text), nil
+}
+
 func LookupAppCommand(p PlatformProvider) (string, bool) {
 	name := p.Prefix() + "APP_COMMAND"
 	value, ok := p.Lookup(name)
@@ -364,7 +824,10 @@ func (e *Environment) GetApplicationCommand() string {
 func LookupAppDir(p PlatformProvider) (string, bool) {
 	name := p.Prefix() + "APP_DIR"
 	value, ok := p.Lookup(name)
-	return value, ok
+	if !ok || value == "" {
+		return "", false
+	}
+	return filepath.Clean(value), true
 }
 
 func GetAppDir(p PlatformProvider) string {
@@ -467,10 +930,35 @@ func (e *Environment) GetBranch() string {
 	return GetBranch(e)
 }
 
+func LookupCacheDir(p PlatformProvider) (string, bool) {
+	name := p.Prefix() + "CACHE_DIR"
+	value, ok := p.Lookup(name)
+	if !ok || value == "" {
+		return "", false
+	}
+	return filepath.Clean(value), true
+}
+
+func GetCacheDir(p PlatformProvider) string {
+	v, _ := LookupCacheDir(p)
+	return v
+}
+
+func (e *Environment) LookupCacheDir() (string, bool) {
+	return LookupCacheDir(e)
+}
+
+func (e *Environment) GetCacheDir() string {
+	return GetCacheDir(e)
+}
+
 func LookupDir(p PlatformProvider) (string, bool) {
 	name := p.Prefix() + "DIR"
 	value, ok := p.Lookup(name)
-	return value, ok
+	if !ok || value == "" {
+		return "", false
+	}
+	return filepath.Clean(value), true
 }
 
 func GetDir(p PlatformProvider) string {
@@ -489,7 +977,10 @@ func (e *Environment) GetDir() string {
 func LookupDocumentRoot(p PlatformProvider) (string, bool) {
 	name := p.Prefix() + "DOCUMENT_ROOT"
 	value, ok := p.Lookup(name)
-	return value, ok
+	if !ok || value == "" {
+		return "", false
+	}
+	return filepath.Clean(value), true
 }
 
 func GetDocumentRoot(p PlatformProvider) string {
@@ -524,6 +1015,75 @@ func (e *Environment) GetEnvironment() string {
 	return GetEnvironment(e)
 }
 
+func LookupEnvironmentType(p PlatformProvider) (EnvironmentType, bool) {
+	name := p.Prefix() + "ENVIRONMENT_TYPE"
+	value, ok := p.Lookup(name)
+	if !ok {
+		return *new(EnvironmentType), false
+	}
+	obj := new(EnvironmentType)
+	err := obj.UnmarshalText([]byte(value))
+	if err != nil {
+		logrus.WithError(err).Warn("unable to unmarshal value")
+		return *new(EnvironmentType), false
+	}
+	return *obj, true
+}
+
+func GetEnvironmentType(p PlatformProvider) EnvironmentType {
+	v, _ := LookupEnvironmentType(p)
+	return v
+}
+
+func (e *Environment) LookupEnvironmentType() (EnvironmentType, bool) {
+	return LookupEnvironmentType(e)
+}
+
+func (e *Environment) GetEnvironmentType() EnvironmentType {
+	return GetEnvironmentType(e)
+}
+
+func LookupMode(p PlatformProvider) (string, bool) {
+	name := p.Prefix() + "MODE"
+	value, ok := p.Lookup(name)
+	return value, ok
+}
+
+func GetMode(p PlatformProvider) string {
+	v, _ := LookupMode(p)
+	return v
+}
+
+func (e *Environment) LookupMode() (string, bool) {
+	return LookupMode(e)
+}
+
+func (e *Environment) GetMode() string {
+	return GetMode(e)
+}
+
+func LookupOutputDir(p PlatformProvider) (string, bool) {
+	name := p.Prefix() + "OUTPUT_DIR"
+	value, ok := p.Lookup(name)
+	if !ok || value == "" {
+		return "", false
+	}
+	return filepath.Clean(value), true
+}
+
+func GetOutputDir(p PlatformProvider) string {
+	v, _ := LookupOutputDir(p)
+	return v
+}
+
+func (e *Environment) LookupOutputDir() (string, bool) {
+	return LookupOutputDir(e)
+}
+
+func (e *Environment) GetOutputDir() string {
+	return GetOutputDir(e)
+}
+
 func LookupPort(p PlatformProvider) (string, bool) {
 	name := "PORT"
 	value, ok := p.Lookup(name)
@@ -685,6 +1245,28 @@ func (e *Environment) GetSocket() string {
 	return GetSocket(e)
 }
 
+func LookupSourceDir(p PlatformProvider) (string, bool) {
+	name := p.Prefix() + "SOURCE_DIR"
+	value, ok := p.Lookup(name)
+	if !ok || value == "" {
+		return "", false
+	}
+	return filepath.Clean(value), true
+}
+
+func GetSourceDir(p PlatformProvider) string {
+	v, _ := LookupSourceDir(p)
+	return v
+}
+
+func (e *Environment) LookupSourceDir() (string, bool) {
+	return LookupSourceDir(e)
+}
+
+func (e *Environment) GetSourceDir() string {
+	return GetSourceDir(e)
+}
+
 func LookupTreeID(p PlatformProvider) (string, bool) {
 	name := p.Prefix() + "TREE_ID"
 	value, ok := p.Lookup(name)
@@ -828,3 +1410,922 @@ func (e *Environment) LookupXClientVerify() (string, bool) {
 func (e *Environment) GetXClientVerify() string {
 	return GetXClientVerify(e)
 }
+
+// PlatformAccessor lists the generated accessors of Environment so code can
+// depend on an interface and be tested with FakePlatformAccessor.
+type PlatformAccessor interface {
+	LookupAppCommand() (string, bool)
+	GetAppCommand() string
+	LookupApplicationCommand() (string, bool)
+	GetApplicationCommand() string
+	LookupAppDir() (string, bool)
+	GetAppDir() string
+	LookupApplication() (*Application, bool)
+	GetApplication() *Application
+	LookupApplicationName() (string, bool)
+	GetApplicationName() string
+	LookupAppName() (string, bool)
+	GetAppName() string
+	LookupBranch() (string, bool)
+	GetBranch() string
+	LookupCacheDir() (string, bool)
+	GetCacheDir() string
+	LookupDir() (string, bool)
+	GetDir() string
+	LookupDocumentRoot() (string, bool)
+	GetDocumentRoot() string
+	LookupEnvironment() (string, bool)
+	GetEnvironment() string
+	LookupEnvironmentType() (EnvironmentType, bool)
+	GetEnvironmentType() EnvironmentType
+	LookupMode() (string, bool)
+	GetMode() string
+	LookupOutputDir() (string, bool)
+	GetOutputDir() string
+	LookupPort() (string, bool)
+	GetPort() string
+	LookupProject() (string, bool)
+	GetProject() string
+	LookupProjectEntropy() (string, bool)
+	GetProjectEntropy() string
+	LookupRelationships() (Relationships, bool)
+	GetRelationships() Relationships
+	LookupRoutes() (Routes, bool)
+	GetRoutes() Routes
+	LookupSMTPHost() (string, bool)
+	GetSMTPHost() string
+	LookupSocket() (string, bool)
+	GetSocket() string
+	LookupSourceDir() (string, bool)
+	GetSourceDir() string
+	LookupTreeID() (string, bool)
+	GetTreeID() string
+	LookupVariables() (Variables, bool)
+	GetVariables() Variables
+	LookupVars() (Variables, bool)
+	GetVars() Variables
+	LookupXClientCert() (string, bool)
+	GetXClientCert() string
+	LookupXClientDN() (string, bool)
+	GetXClientDN() string
+	LookupXClientIP() (string, bool)
+	GetXClientIP() string
+	LookupXClientVerify() (string, bool)
+	GetXClientVerify() string
+}
+
+// FakePlatformAccessor implements PlatformAccessor for tests.
+//
+// Each method calls its override when set, then the LookupX override for
+// GetX methods, then reads Provider when set, and otherwise returns the zero
+// value. Aliases share the overrides of the variable they alias. Every call
+// is recorded.
+type FakePlatformAccessor struct {
+	Provider PlatformProvider
+
+	LookupAppCommandFunc      func() (string, bool)
+	GetAppCommandFunc         func() string
+	LookupAppDirFunc          func() (string, bool)
+	GetAppDirFunc             func() string
+	LookupApplicationFunc     func() (*Application, bool)
+	GetApplicationFunc        func() *Application
+	LookupApplicationNameFunc func() (string, bool)
+	GetApplicationNameFunc    func() string
+	LookupBranchFunc          func() (string, bool)
+	GetBranchFunc             func() string
+	LookupCacheDirFunc        func() (string, bool)
+	GetCacheDirFunc           func() string
+	LookupDirFunc             func() (string, bool)
+	GetDirFunc                func() string
+	LookupDocumentRootFunc    func() (string, bool)
+	GetDocumentRootFunc       func() string
+	LookupEnvironmentFunc     func() (string, bool)
+	GetEnvironmentFunc        func() string
+	LookupEnvironmentTypeFunc func() (EnvironmentType, bool)
+	GetEnvironmentTypeFunc    func() EnvironmentType
+	LookupModeFunc            func() (string, bool)
+	GetModeFunc               func() string
+	LookupOutputDirFunc       func() (string, bool)
+	GetOutputDirFunc          func() string
+	LookupPortFunc            func() (string, bool)
+	GetPortFunc               func() string
+	LookupProjectFunc         func() (string, bool)
+	GetProjectFunc            func() string
+	LookupProjectEntropyFunc  func() (string, bool)
+	GetProjectEntropyFunc     func() string
+	LookupRelationshipsFunc   func() (Relationships, bool)
+	GetRelationshipsFunc      func() Relationships
+	LookupRoutesFunc          func() (Routes, bool)
+	GetRoutesFunc             func() Routes
+	LookupSMTPHostFunc        func() (string, bool)
+	GetSMTPHostFunc           func() string
+	LookupSocketFunc          func() (string, bool)
+	GetSocketFunc             func() string
+	LookupSourceDirFunc       func() (string, bool)
+	GetSourceDirFunc          func() string
+	LookupTreeIDFunc          func() (string, bool)
+	GetTreeIDFunc             func() string
+	LookupVariablesFunc       func() (Variables, bool)
+	GetVariablesFunc          func() Variables
+	LookupXClientCertFunc     func() (string, bool)
+	GetXClientCertFunc        func() string
+	LookupXClientDNFunc       func() (string, bool)
+	GetXClientDNFunc          func() string
+	LookupXClientIPFunc       func() (string, bool)
+	GetXClientIPFunc          func() string
+	LookupXClientVerifyFunc   func() (string, bool)
+	GetXClientVerifyFunc      func() string
+
+	mu    sync.Mutex
+	calls []string
+}
+
+var (
+	_ PlatformAccessor = (*Environment)(nil)
+	_ PlatformAccessor = (*FakePlatformAccessor)(nil)
+)
+
+func (f *FakePlatformAccessor) record(method string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, method)
+}
+
+// Calls returns the names of the methods called so far, in order.
+func (f *FakePlatformAccessor) Calls() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.calls...)
+}
+
+// CallCount returns how many times the named method was called.
+func (f *FakePlatformAccessor) CallCount(method string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var rv int
+	for _, call := range f.calls {
+		if call == method {
+			rv++
+		}
+	}
+	return rv
+}
+
+// Reset forgets the recorded calls.
+func (f *FakePlatformAccessor) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = nil
+}
+
+func (f *FakePlatformAccessor) LookupAppCommand() (string, bool) {
+	f.record("LookupAppCommand")
+	if f.LookupAppCommandFunc != nil {
+		return f.LookupAppCommandFunc()
+	}
+	if f.Provider != nil {
+		return LookupAppCommand(f.Provider)
+	}
+	var v string
+	return v, false
+}
+
+func (f *FakePlatformAccessor) GetAppCommand() string {
+	f.record("GetAppCommand")
+	if f.GetAppCommandFunc != nil {
+		return f.GetAppCommandFunc()
+	}
+	if f.LookupAppCommandFunc != nil {
+		v, _ := f.LookupAppCommandFunc()
+		return v
+	}
+	if f.Provider != nil {
+		return GetAppCommand(f.Provider)
+	}
+	var v string
+	return v
+}
+
+func (f *FakePlatformAccessor) LookupApplicationCommand() (string, bool) {
+	return f.LookupAppCommand()
+}
+
+func (f *FakePlatformAccessor) GetApplicationCommand() string {
+	return f.GetAppCommand()
+}
+
+func (f *FakePlatformAccessor) LookupAppDir() (string, bool) {
+	f.record("LookupAppDir")
+	if f.LookupAppDirFunc != nil {
+		return f.LookupAppDirFunc()
+	}
+	if f.Provider != nil {
+		return LookupAppDir(f.Provider)
+	}
+	var v string
+	return v, false
+}
+
+func (f *FakePlatformAccessor) GetAppDir() string {
+	f.record("GetAppDir")
+	if f.GetAppDirFunc != nil {
+		return f.GetAppDirFunc()
+	}
+	if f.LookupAppDirFunc != nil {
+		v, _ := f.LookupAppDirFunc()
+		return v
+	}
+	if f.Provider != nil {
+		return GetAppDir(f.Provider)
+	}
+	var v string
+	return v
+}
+
+func (f *FakePlatformAccessor) LookupApplication() (*Application, bool) {
+	f.record("LookupApplication")
+	if f.LookupApplicationFunc != nil {
+		return f.LookupApplicationFunc()
+	}
+	if f.Provider != nil {
+		return LookupApplication(f.Provider)
+	}
+	var v *Application
+	return v, false
+}
+
+func (f *FakePlatformAccessor) GetApplication() *Application {
+	f.record("GetApplication")
+	if f.GetApplicationFunc != nil {
+		return f.GetApplicationFunc()
+	}
+	if f.LookupApplicationFunc != nil {
+		v, _ := f.LookupApplicationFunc()
+		return v
+	}
+	if f.Provider != nil {
+		return GetApplication(f.Provider)
+	}
+	var v *Application
+	return v
+}
+
+func (f *FakePlatformAccessor) LookupApplicationName() (string, bool) {
+	f.record("LookupApplicationName")
+	if f.LookupApplicationNameFunc != nil {
+		return f.LookupApplicationNameFunc()
+	}
+	if f.Provider != nil {
+		return LookupApplicationName(f.Provider)
+	}
+	var v string
+	return v, false
+}
+
+func (f *FakePlatformAccessor) GetApplicationName() string {
+	f.record("GetApplicationName")
+	if f.GetApplicationNameFunc != nil {
+		return f.GetApplicationNameFunc()
+	}
+	if f.LookupApplicationNameFunc != nil {
+		v, _ := f.LookupApplicationNameFunc()
+		return v
+	}
+	if f.Provider != nil {
+		return GetApplicationName(f.Provider)
+	}
+	var v string
+	return v
+}
+
+func (f *FakePlatformAccessor) LookupAppName() (string, bool) {
+	return f.LookupApplicationName()
+}
+
+func (f *FakePlatformAccessor) GetAppName() string {
+	return f.GetApplicationName()
+}
+
+func (f *FakePlatformAccessor) LookupBranch() (string, bool) {
+	f.record("LookupBranch")
+	if f.LookupBranchFunc != nil {
+		return f.LookupBranchFunc()
+	}
+	if f.Provider != nil {
+		return LookupBranch(f.Provider)
+	}
+	var v string
+	return v, false
+}
+
+func (f *FakePlatformAccessor) GetBranch() string {
+	f.record("GetBranch")
+	if f.GetBranchFunc != nil {
+		return f.GetBranchFunc()
+	}
+	if f.LookupBranchFunc != nil {
+		v, _ := f.LookupBranchFunc()
+		return v
+	}
+	if f.Provider != nil {
+		return GetBranch(f.Provider)
+	}
+	var v string
+	return v
+}
+
+func (f *FakePlatformAccessor) LookupCacheDir() (string, bool) {
+	f.record("LookupCacheDir")
+	if f.LookupCacheDirFunc != nil {
+		return f.LookupCacheDirFunc()
+	}
+	if f.Provider != nil {
+		return LookupCacheDir(f.Provider)
+	}
+	var v string
+	return v, false
+}
+
+func (f *FakePlatformAccessor) GetCacheDir() string {
+	f.record("GetCacheDir")
+	if f.GetCacheDirFunc != nil {
+		return f.GetCacheDirFunc()
+	}
+	if f.LookupCacheDirFunc != nil {
+		v, _ := f.LookupCacheDirFunc()
+		return v
+	}
+	if f.Provider != nil {
+		return GetCacheDir(f.Provider)
+	}
+	var v string
+	return v
+}
+
+func (f *FakePlatformAccessor) LookupDir() (string, bool) {
+	f.record("LookupDir")
+	if f.LookupDirFunc != nil {
+		return f.LookupDirFunc()
+	}
+	if f.Provider != nil {
+		return LookupDir(f.Provider)
+	}
+	var v string
+	return v, false
+}
+
+func (f *FakePlatformAccessor) GetDir() string {
+	f.record("GetDir")
+	if f.GetDirFunc != nil {
+		return f.GetDirFunc()
+	}
+	if f.LookupDirFunc != nil {
+		v, _ := f.LookupDirFunc()
+		return v
+	}
+	if f.Provider != nil {
+		return GetDir(f.Provider)
+	}
+	var v string
+	return v
+}
+
+func (f *FakePlatformAccessor) LookupDocumentRoot() (string, bool) {
+	f.record("LookupDocumentRoot")
+	if f.LookupDocumentRootFunc != nil {
+		return f.LookupDocumentRootFunc()
+	}
+	if f.Provider != nil {
+		return LookupDocumentRoot(f.Provider)
+	}
+	var v string
+	return v, false
+}
+
+func (f *FakePlatformAccessor) GetDocumentRoot() string {
+	f.record("GetDocumentRoot")
+	if f.GetDocumentRootFunc != nil {
+		return f.GetDocumentRootFunc()
+	}
+	if f.LookupDocumentRootFunc != nil {
+		v, _ := f.LookupDocumentRootFunc()
+		return v
+	}
+	if f.Provider != nil {
+		return GetDocumentRoot(f.Provider)
+	}
+	var v string
+	return v
+}
+
+func (f *FakePlatformAccessor) LookupEnvironment() (string, bool) {
+	f.record("LookupEnvironment")
+	if f.LookupEnvironmentFunc != nil {
+		return f.LookupEnvironmentFunc()
+	}
+	if f.Provider != nil {
+		return LookupEnvironment(f.Provider)
+	}
+	var v string
+	return v, false
+}
+
+func (f *FakePlatformAccessor) GetEnvironment() string {
+	f.record("GetEnvironment")
+	if f.GetEnvironmentFunc != nil {
+		return f.GetEnvironmentFunc()
+	}
+	if f.LookupEnvironmentFunc != nil {
+		v, _ := f.LookupEnvironmentFunc()
+		return v
+	}
+	if f.Provider != nil {
+		return GetEnvironment(f.Provider)
+	}
+	var v string
+	return v
+}
+
+func (f *FakePlatformAccessor) LookupEnvironmentType() (EnvironmentType, bool) {
+	f.record("LookupEnvironmentType")
+	if f.LookupEnvironmentTypeFunc != nil {
+		return f.LookupEnvironmentTypeFunc()
+	}
+	if f.Provider != nil {
+		return LookupEnvironmentType(f.Provider)
+	}
+	var v EnvironmentType
+	return v, false
+}
+
+func (f *FakePlatformAccessor) GetEnvironmentType() EnvironmentType {
+	f.record("GetEnvironmentType")
+	if f.GetEnvironmentTypeFunc != nil {
+		return f.GetEnvironmentTypeFunc()
+	}
+	if f.LookupEnvironmentTypeFunc != nil {
+		v, _ := f.LookupEnvironmentTypeFunc()
+		return v
+	}
+	if f.Provider != nil {
+		return GetEnvironmentType(f.Provider)
+	}
+	var v EnvironmentType
+	return v
+}
+
+func (f *FakePlatformAccessor) LookupMode() (string, bool) {
+	f.record("LookupMode")
+	if f.LookupModeFunc != nil {
+		return f.LookupModeFunc()
+	}
+	if f.Provider != nil {
+		return LookupMode(f.Provider)
+	}
+	var v string
+	return v, false
+}
+
+func (f *FakePlatformAccessor) GetMode() string {
+	f.record("GetMode")
+	if f.GetModeFunc != nil {
+		return f.GetModeFunc()
+	}
+	if f.LookupModeFunc != nil {
+		v, _ := f.LookupModeFunc()
+		return v
+	}
+	if f.Provider != nil {
+		return GetMode(f.Provider)
+	}
+	var v string
+	return v
+}
+
+func (f *FakePlatformAccessor) LookupOutputDir() (string, bool) {
+	f.record("LookupOutputDir")
+	if f.LookupOutputDirFunc != nil {
+		return f.LookupOutputDirFunc()
+	}
+	if f.Provider != nil {
+		return LookupOutputDir(f.Provider)
+	}
+	var v string
+	return v, false
+}
+
+func (f *FakePlatformAccessor) GetOutputDir() string {
+	f.record("GetOutputDir")
+	if f.GetOutputDirFunc != nil {
+		return f.GetOutputDirFunc()
+	}
+	if f.LookupOutputDirFunc != nil {
+		v, _ := f.LookupOutputDirFunc()
+		return v
+	}
+	if f.Provider != nil {
+		return GetOutputDir(f.Provider)
+	}
+	var v string
+	return v
+}
+
+func (f *FakePlatformAccessor) LookupPort() (string, bool) {
+	f.record("LookupPort")
+	if f.LookupPortFunc != nil {
+		return f.LookupPortFunc()
+	}
+	if f.Provider != nil {
+		return LookupPort(f.Provider)
+	}
+	var v string
+	return v, false
+}
+
+func (f *FakePlatformAccessor) GetPort() string {
+	f.record("GetPort")
+	if f.GetPortFunc != nil {
+		return f.GetPortFunc()
+	}
+	if f.LookupPortFunc != nil {
+		v, _ := f.LookupPortFunc()
+		return v
+	}
+	if f.Provider != nil {
+		return GetPort(f.Provider)
+	}
+	var v string
+	return v
+}
+
+func (f *FakePlatformAccessor) LookupProject() (string, bool) {
+	f.record("LookupProject")
+	if f.LookupProjectFunc != nil {
+		return f.LookupProjectFunc()
+	}
+	if f.Provider != nil {
+		return LookupProject(f.Provider)
+	}
+	var v string
+	return v, false
+}
+
+func (f *FakePlatformAccessor) GetProject() string {
+	f.record("GetProject")
+	if f.GetProjectFunc != nil {
+		return f.GetProjectFunc()
+	}
+	if f.LookupProjectFunc != nil {
+		v, _ := f.LookupProjectFunc()
+		return v
+	}
+	if f.Provider != nil {
+		return GetProject(f.Provider)
+	}
+	var v string
+	return v
+}
+
+func (f *FakePlatformAccessor) LookupProjectEntropy() (string, bool) {
+	f.record("LookupProjectEntropy")
+	if f.LookupProjectEntropyFunc != nil {
+		return f.LookupProjectEntropyFunc()
+	}
+	if f.Provider != nil {
+		return LookupProjectEntropy(f.Provider)
+	}
+	var v string
+	return v, false
+}
+
+func (f *FakePlatformAccessor) GetProjectEntropy() string {
+	f.record("GetProjectEntropy")
+	if f.GetProjectEntropyFunc != nil {
+		return f.GetProjectEntropyFunc()
+	}
+	if f.LookupProjectEntropyFunc != nil {
+		v, _ := f.LookupProjectEntropyFunc()
+		return v
+	}
+	if f.Provider != nil {
+		return GetProjectEntropy(f.Provider)
+	}
+	var v string
+	return v
+}
+
+func (f *FakePlatformAccessor) LookupRelationships() (Relationships, bool) {
+	f.record("LookupRelationships")
+	if f.LookupRelationshipsFunc != nil {
+		return f.LookupRelationshipsFunc()
+	}
+	if f.Provider != nil {
+		return LookupRelationships(f.Provider)
+	}
+	var v Relationships
+	return v, false
+}
+
+func (f *FakePlatformAccessor) GetRelationships() Relationships {
+	f.record("GetRelationships")
+	if f.GetRelationshipsFunc != nil {
+		return f.GetRelationshipsFunc()
+	}
+	if f.LookupRelationshipsFunc != nil {
+		v, _ := f.LookupRelationshipsFunc()
+		return v
+	}
+	if f.Provider != nil {
+		return GetRelationships(f.Provider)
+	}
+	var v Relationships
+	return v
+}
+
+func (f *FakePlatformAccessor) LookupRoutes() (Routes, bool) {
+	f.record("LookupRoutes")
+	if f.LookupRoutesFunc != nil {
+		return f.LookupRoutesFunc()
+	}
+	if f.Provider != nil {
+		return LookupRoutes(f.Provider)
+	}
+	var v Routes
+	return v, false
+}
+
+func (f *FakePlatformAccessor) GetRoutes() Routes {
+	f.record("GetRoutes")
+	if f.GetRoutesFunc != nil {
+		return f.GetRoutesFunc()
+	}
+	if f.LookupRoutesFunc != nil {
+		v, _ := f.LookupRoutesFunc()
+		return v
+	}
+	if f.Provider != nil {
+		return GetRoutes(f.Provider)
+	}
+	var v Routes
+	return v
+}
+
+func (f *FakePlatformAccessor) LookupSMTPHost() (string, bool) {
+	f.record("LookupSMTPHost")
+	if f.LookupSMTPHostFunc != nil {
+		return f.LookupSMTPHostFunc()
+	}
+	if f.Provider != nil {
+		return LookupSMTPHost(f.Provider)
+	}
+	var v string
+	return v, false
+}
+
+func (f *FakePlatformAccessor) GetSMTPHost() string {
+	f.record("GetSMTPHost")
+	if f.GetSMTPHostFunc != nil {
+		return f.GetSMTPHostFunc()
+	}
+	if f.LookupSMTPHostFunc != nil {
+		v, _ := f.LookupSMTPHostFunc()
+		return v
+	}
+	if f.Provider != nil {
+		return GetSMTPHost(f.Provider)
+	}
+	var v string
+	return v
+}
+
+func (f *FakePlatformAccessor) LookupSocket() (string, bool) {
+	f.record("LookupSocket")
+	if f.LookupSocketFunc != nil {
+		return f.LookupSocketFunc()
+	}
+	if f.Provider != nil {
+		return LookupSocket(f.Provider)
+	}
+	var v string
+	return v, false
+}
+
+func (f *FakePlatformAccessor) GetSocket() string {
+	f.record("GetSocket")
+	if f.GetSocketFunc != nil {
+		return f.GetSocketFunc()
+	}
+	if f.LookupSocketFunc != nil {
+		v, _ := f.LookupSocketFunc()
+		return v
+	}
+	if f.Provider != nil {
+		return GetSocket(f.Provider)
+	}
+	var v string
+	return v
+}
+
+func (f *FakePlatformAccessor) LookupSourceDir() (string, bool) {
+	f.record("LookupSourceDir")
+	if f.LookupSourceDirFunc != nil {
+		return f.LookupSourceDirFunc()
+	}
+	if f.Provider != nil {
+		return LookupSourceDir(f.Provider)
+	}
+	var v string
+	return v, false
+}
+
+func (f *FakePlatformAccessor) GetSourceDir() string {
+	f.record("GetSourceDir")
+	if f.GetSourceDirFunc != nil {
+		return f.GetSourceDirFunc()
+	}
+	if f.LookupSourceDirFunc != nil {
+		v, _ := f.LookupSourceDirFunc()
+		return v
+	}
+	if f.Provider != nil {
+		return GetSourceDir(f.Provider)
+	}
+	var v string
+	return v
+}
+
+func (f *FakePlatformAccessor) LookupTreeID() (string, bool) {
+	f.record("LookupTreeID")
+	if f.LookupTreeIDFunc != nil {
+		return f.LookupTreeIDFunc()
+	}
+	if f.Provider != nil {
+		return LookupTreeID(f.Provider)
+	}
+	var v string
+	return v, false
+}
+
+func (f *FakePlatformAccessor) GetTreeID() string {
+	f.record("GetTreeID")
+	if f.GetTreeIDFunc != nil {
+		return f.GetTreeIDFunc()
+	}
+	if f.LookupTreeIDFunc != nil {
+		v, _ := f.LookupTreeIDFunc()
+		return v
+	}
+	if f.Provider != nil {
+		return GetTreeID(f.Provider)
+	}
+	var v string
+	return v
+}
+
+func (f *FakePlatformAccessor) LookupVariables() (Variables, bool) {
+	f.record("LookupVariables")
+	if f.LookupVariablesFunc != nil {
+		return f.LookupVariablesFunc()
+	}
+	if f.Provider != nil {
+		return LookupVariables(f.Provider)
+	}
+	var v Variables
+	return v, false
+}
+
+func (f *FakePlatformAccessor) GetVariables() Variables {
+	f.record("GetVariables")
+	if f.GetVariablesFunc != nil {
+		return f.GetVariablesFunc()
+	}
+	if f.LookupVariablesFunc != nil {
+		v, _ := f.LookupVariablesFunc()
+		return v
+	}
+	if f.Provider != nil {
+		return GetVariables(f.Provider)
+	}
+	var v Variables
+	return v
+}
+
+func (f *FakePlatformAccessor) LookupVars() (Variables, bool) {
+	return f.LookupVariables()
+}
+
+func (f *FakePlatformAccessor) GetVars() Variables {
+	return f.GetVariables()
+}
+
+func (f *FakePlatformAccessor) LookupXClientCert() (string, bool) {
+	f.record("LookupXClientCert")
+	if f.LookupXClientCertFunc != nil {
+		return f.LookupXClientCertFunc()
+	}
+	if f.Provider != nil {
+		return LookupXClientCert(f.Provider)
+	}
+	var v string
+	return v, false
+}
+
+func (f *FakePlatformAccessor) GetXClientCert() string {
+	f.record("GetXClientCert")
+	if f.GetXClientCertFunc != nil {
+		return f.GetXClientCertFunc()
+	}
+	if f.LookupXClientCertFunc != nil {
+		v, _ := f.LookupXClientCertFunc()
+		return v
+	}
+	if f.Provider != nil {
+		return GetXClientCert(f.Provider)
+	}
+	var v string
+	return v
+}
+
+func (f *FakePlatformAccessor) LookupXClientDN() (string, bool) {
+	f.record("LookupXClientDN")
+	if f.LookupXClientDNFunc != nil {
+		return f.LookupXClientDNFunc()
+	}
+	if f.Provider != nil {
+		return LookupXClientDN(f.Provider)
+	}
+	var v string
+	return v, false
+}
+
+func (f *FakePlatformAccessor) GetXClientDN() string {
+	f.record("GetXClientDN")
+	if f.GetXClientDNFunc != nil {
+		return f.GetXClientDNFunc()
+	}
+	if f.LookupXClientDNFunc != nil {
+		v, _ := f.LookupXClientDNFunc()
+		return v
+	}
+	if f.Provider != nil {
+		return GetXClientDN(f.Provider)
+	}
+	var v string
+	return v
+}
+
+func (f *FakePlatformAccessor) LookupXClientIP() (string, bool) {
+	f.record("LookupXClientIP")
+	if f.LookupXClientIPFunc != nil {
+		return f.LookupXClientIPFunc()
+	}
+	if f.Provider != nil {
+		return LookupXClientIP(f.Provider)
+	}
+	var v string
+	return v, false
+}
+
+func (f *FakePlatformAccessor) GetXClientIP() string {
+	f.record("GetXClientIP")
+	if f.GetXClientIPFunc != nil {
+		return f.GetXClientIPFunc()
+	}
+	if f.LookupXClientIPFunc != nil {
+		v, _ := f.LookupXClientIPFunc()
+		return v
+	}
+	if f.Provider != nil {
+		return GetXClientIP(f.Provider)
+	}
+	var v string
+	return v
+}
+
+func (f *FakePlatformAccessor) LookupXClientVerify() (string, bool) {
+	f.record("LookupXClientVerify")
+	if f.LookupXClientVerifyFunc != nil {
+		return f.LookupXClientVerifyFunc()
+	}
+	if f.Provider != nil {
+		return LookupXClientVerify(f.Provider)
+	}
+	var v string
+	return v, false
+}
+
+func (f *FakePlatformAccessor) GetXClientVerify() string {
+	f.record("GetXClientVerify")
+	if f.GetXClientVerifyFunc != nil {
+		return f.GetXClientVerifyFunc()
+	}
+	if f.LookupXClientVerifyFunc != nil {
+		v, _ := f.LookupXClientVerifyFunc()
+		return v
+	}
+	if f.Provider != nil {
+		return GetXClientVerify(f.Provider)
+	}
+	var v string
+	return v
+}
@@ -0,0 +1,182 @@
+package pshgo_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+
+	. "github.com/demosdemon/pshgo"
+)
+
+func TestNewAccessLevel(t *testing.T) {
+	cases := []struct {
+		name    string
+		want    AccessLevel
+		wantErr bool
+	}{
+		{name: "viewer", want: AccessLevelViewer},
+		{name: "Contributor", want: AccessLevelContributor},
+		{name: "ADMIN", want: AccessLevelAdmin},
+		{name: "owner", wantErr: true},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			got, err := NewAccessLevel(c.name)
+			assert.Equal(t, c.wantErr, err != nil)
+			assert.Equal(t, c.want, got)
+		})
+	}
+}
+
+func TestAccessLevelValues(t *testing.T) {
+	values := AccessLevelValues()
+	assert.Equal(t, []AccessLevel{AccessLevelViewer, AccessLevelContributor, AccessLevelAdmin}, values)
+	for _, v := range values {
+		assert.True(t, v.IsValid())
+	}
+	assert.False(t, AccessLevel(len(values)).IsValid())
+}
+
+func TestAccessLevel_YAML(t *testing.T) {
+	var v struct {
+		Level AccessLevel `yaml:"level"`
+	}
+	require.NoError(t, yaml.Unmarshal([]byte("level: Admin\n"), &v))
+	assert.Equal(t, AccessLevelAdmin, v.Level)
+
+	data, err := yaml.Marshal(v)
+	require.NoError(t, err)
+	assert.Equal(t, "level: admin\n", string(data))
+
+	assert.Error(t, yaml.Unmarshal([]byte("level: owner\n"), &v))
+	assert.Error(t, yaml.Unmarshal([]byte("level: [admin]\n"), &v))
+}
+
+func TestAccessLevel_SQL(t *testing.T) {
+	var v AccessLevel
+	require.NoError(t, v.Scan("contributor"))
+	assert.Equal(t, AccessLevelContributor, v)
+	require.NoError(t, v.Scan([]byte("admin")))
+	assert.Equal(t, AccessLevelAdmin, v)
+	assert.Error(t, v.Scan(42))
+	assert.Error(t, v.Scan(nil))
+
+	value, err := AccessLevelViewer.Value()
+	require.NoError(t, err)
+	assert.Equal(t, "viewer", value)
+
+	_, err = AccessLevel(42).Value()
+	assert.Error(t, err)
+}
+
+func TestServiceSize(t *testing.T) {
+	v, err := NewServiceSize("xl")
+	require.NoError(t, err)
+	assert.Equal(t, ServiceSizeExtraLarge, v)
+	assert.True(t, v.IsValid())
+
+	_, err = NewServiceSize("8XL")
+	assert.EqualError(t, err, `unknown ServiceSize name "8XL"`)
+
+	var app struct {
+		Size ServiceSize `json:"size"`
+	}
+	assert.Equal(t, ServiceSizeAuto, app.Size)
+	assert.Error(t, json.Unmarshal([]byte(`{"size": "8XL"}`), &app))
+
+	require.NoError(t, json.Unmarshal([]byte(`{"size": "2xl"}`), &app))
+	assert.Equal(t, ServiceSizeDoubleExtraLarge, app.Size)
+
+	data, err := json.Marshal(app)
+	require.NoError(t, err)
+	assert.Equal(t, `{"size":"2XL"}`, string(data))
+
+	require.NoError(t, v.Scan("m"))
+	assert.Equal(t, ServiceSizeMedium, v)
+
+	value, err := v.Value()
+	require.NoError(t, err)
+	assert.Equal(t, "M", value)
+
+	assert.Len(t, ServiceSizeValues(), 7)
+}
+
+// enumName is a case of testdata/enum_names.json, shared by the Go and
+// TypeScript parsers: name parses into value, or fails when value is empty.
+type enumName struct {
+	Enum  string `json:"enum"`
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+func loadEnumNames(t *testing.T) []enumName {
+	data, err := ioutil.ReadFile("testdata/enum_names.json")
+	require.NoError(t, err)
+
+	var rv []enumName
+	require.NoError(t, json.Unmarshal(data, &rv))
+	return rv
+}
+
+func TestEnumNames(t *testing.T) {
+	parsers := map[string]func(string) (fmt.Stringer, error){
+		"AccessLevel":      func(s string) (fmt.Stringer, error) { return NewAccessLevel(s) },
+		"AccessType":       func(s string) (fmt.Stringer, error) { return NewAccessType(s) },
+		"ApplicationMount": func(s string) (fmt.Stringer, error) { return NewApplicationMount(s) },
+		"ClientVerify":     func(s string) (fmt.Stringer, error) { return NewClientVerify(s) },
+		"EnvironmentType":  func(s string) (fmt.Stringer, error) { return NewEnvironmentType(s) },
+		"ServiceSize":      func(s string) (fmt.Stringer, error) { return NewServiceSize(s) },
+		"SocketFamily":     func(s string) (fmt.Stringer, error) { return NewSocketFamily(s) },
+		"SocketProtocol":   func(s string) (fmt.Stringer, error) { return NewSocketProtocol(s) },
+	}
+
+	for _, c := range loadEnumNames(t) {
+		parse, ok := parsers[c.Enum]
+		require.True(t, ok, c.Enum)
+
+		v, err := parse(c.Name)
+		if c.Value == "" {
+			assert.Error(t, err, "%s %q", c.Enum, c.Name)
+			continue
+		}
+		if assert.NoError(t, err, "%s %q", c.Enum, c.Name) {
+			assert.Equal(t, c.Value, v.String(), "%s %q", c.Enum, c.Name)
+		}
+	}
+}
+
+func TestEnumNames_TypeScript(t *testing.T) {
+	data, err := ioutil.ReadFile("typescript/pshgo.ts")
+	require.NoError(t, err)
+	ts := string(data)
+
+	entry := regexp.MustCompile(`\[("[^"]*"), ("[^"]*")\]`)
+
+	for _, c := range loadEnumNames(t) {
+		assert.Contains(t, ts, "export function parse"+c.Enum+"(value: string): "+c.Enum+" | undefined {\n  return "+c.Enum+"Names.get(value.toLowerCase());\n}")
+
+		start := strings.Index(ts, "const "+c.Enum+"Names")
+		require.True(t, start >= 0, c.Enum)
+		end := strings.Index(ts[start:], "]);")
+		require.True(t, end >= 0, c.Enum)
+
+		names := make(map[string]string)
+		for _, m := range entry.FindAllStringSubmatch(ts[start:start+end], -1) {
+			k, _ := strconv.Unquote(m[1])
+			v, _ := strconv.Unquote(m[2])
+			names[k] = v
+		}
+
+		assert.Equal(t, c.Value, names[strings.ToLower(c.Name)], "%s %q", c.Enum, c.Name)
+	}
+}
@@ -1,42 +0,0 @@
-package main
-
-import (
-	"bytes"
-	"testing"
-
-	"github.com/stretchr/testify/assert"
-)
-
-func TestSchema_Render(t *testing.T) {
-	cases := []struct {
-		name    string
-		schema  Schema
-		wantW   string
-		wantErr bool
-	}{
-		{
-			name:    "empty",
-			schema:  Schema{},
-			wantW:   "",
-			wantErr: true,
-		},
-		{
-			name:   "EmptyWithPackage",
-			schema: Schema{Package: "main"},
-			wantW: `// This file is generated by the ./internal/gen command -- do not edit!
-
-package main
-`,
-		},
-	}
-
-	for _, c := range cases {
-		c := c
-		t.Run(c.name, func(t *testing.T) {
-			var buf bytes.Buffer
-			err := c.schema.Render(&buf)
-			assert.True(t, (err != nil) == c.wantErr)
-			assert.Equal(t, c.wantW, string(buf.Bytes()))
-		})
-	}
-}
@@ -0,0 +1,323 @@
+package pshgo
+
+import (
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	AppConfigFile      = ".platform.app.yaml"
+	ServicesConfigFile = ".platform/services.yaml"
+	RoutesConfigFile   = ".platform/routes.yaml"
+)
+
+// LocalConfig describes how a fake Platform.sh environment is synthesized from
+// the configuration files of a project checked out on the local machine.
+type LocalConfig struct {
+	// Root is the project root containing the .platform directory.
+	Root string
+	// AppDir is the directory containing .platform.app.yaml; defaults to Root.
+	AppDir string
+	// Host replaces the {default} and {all} placeholders in routes.yaml;
+	// defaults to localhost:Port.
+	Host string
+	// Port is the value of PORT.
+	Port int
+	// ServiceHost is the host every relationship points at.
+	ServiceHost string
+	// ServicePorts overrides the default port of a relationship, keyed by
+	// either the relationship name or the service name.
+	ServicePorts map[string]int
+
+	Project        string
+	Environment    string
+	Branch         string
+	ProjectEntropy string
+}
+
+type localService struct {
+	Scheme   string
+	Port     int
+	Username string
+	Password string
+	Path     string
+}
+
+var localServices = map[string]localService{
+	"chrome-headless":  {Scheme: "http", Port: 9222},
+	"elasticsearch":    {Scheme: "http", Port: 9200},
+	"influxdb":         {Scheme: "http", Port: 8086},
+	"kafka":            {Scheme: "kafka", Port: 9092},
+	"mariadb":          {Scheme: "mysql", Port: 3306, Username: "user", Path: "main"},
+	"memcached":        {Scheme: "memcached", Port: 11211},
+	"mongodb":          {Scheme: "mongodb", Port: 27017, Username: "main", Password: "main", Path: "main"},
+	"mysql":            {Scheme: "mysql", Port: 3306, Username: "user", Path: "main"},
+	"oracle-mysql":     {Scheme: "mysql", Port: 3306, Username: "user", Path: "main"},
+	"postgresql":       {Scheme: "pgsql", Port: 5432, Username: "main", Path: "main"},
+	"rabbitmq":         {Scheme: "amqp", Port: 5672, Username: "guest", Password: "guest"},
+	"redis":            {Scheme: "redis", Port: 6379},
+	"redis-persistent": {Scheme: "redis", Port: 6379},
+	"solr":             {Scheme: "solr", Port: 8080, Path: "solr/collection1"},
+	"varnish":          {Scheme: "http", Port: 8080},
+}
+
+func DefaultLocalConfig() LocalConfig {
+	return LocalConfig{
+		Root:        ".",
+		Port:        8080,
+		ServiceHost: "127.0.0.1",
+		Project:     "local",
+		Environment: "master",
+		Branch:      "master",
+	}
+}
+
+// NewLocalProvider reads the Platform.sh configuration files of a local
+// project and returns a provider holding the environment the platform would
+// have generated for it.
+func NewLocalProvider(prefix string, cfg LocalConfig) (MapProvider, error) {
+	logrus.WithField("config", cfg).Trace("NewLocalProvider")
+
+	root, err := filepath.Abs(cfg.Root)
+	if err != nil {
+		return nil, errors.Wrap(err, "error resolving project root")
+	}
+
+	appDir := cfg.AppDir
+	if appDir == "" {
+		appDir = root
+	}
+	appDir, err = filepath.Abs(appDir)
+	if err != nil {
+		return nil, errors.Wrap(err, "error resolving application directory")
+	}
+
+	if cfg.Host == "" {
+		cfg.Host = "localhost"
+		if cfg.Port > 0 {
+			cfg.Host = net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))
+		}
+	}
+
+	if cfg.ProjectEntropy == "" {
+		cfg.ProjectEntropy, err = NewProjectEntropy()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(appDir, AppConfigFile))
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading application config")
+	}
+
+	cfgFile, err := ParseApplicationConfig(filepath.Join(appDir, AppConfigFile), data)
+	if err != nil {
+		return nil, errors.Wrap(err, "error parsing application config")
+	}
+	app := cfgFile.Application
+
+	tree := sha1.Sum(data)
+	app.TreeID = hex.EncodeToString(tree[:])
+	app.AppDir = appDir
+
+	services, err := readLocalServices(filepath.Join(root, ServicesConfigFile))
+	if err != nil {
+		return nil, err
+	}
+
+	routes, err := readLocalRoutes(filepath.Join(root, RoutesConfigFile), cfg.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	rels, err := cfg.relationships(app.Relationships, services)
+	if err != nil {
+		return nil, err
+	}
+
+	vars := flattenVariables(app.Variables)
+
+	env := MapProvider{
+		"PORT":                      strconv.Itoa(cfg.Port),
+		prefix + "APPLICATION_NAME": app.Name,
+		prefix + "APP_DIR":          appDir,
+		prefix + "BRANCH":           cfg.Branch,
+		prefix + "DIR":              appDir,
+		prefix + "ENVIRONMENT":      cfg.Environment,
+		prefix + "ENVIRONMENT_TYPE": EnvironmentTypeDevelopment.String(),
+		prefix + "PROJECT":          cfg.Project,
+		prefix + "PROJECT_ENTROPY":  cfg.ProjectEntropy,
+		prefix + "TREE_ID":          app.TreeID,
+	}
+
+	if loc, ok := app.Web.Locations["/"]; ok && loc.Root != "" {
+		env[prefix+"DOCUMENT_ROOT"] = filepath.Join(appDir, loc.Root)
+	}
+
+	for k, v := range vars {
+		if name := strings.TrimPrefix(k, "env:"); name != k {
+			env[name] = variableString(v)
+		}
+	}
+
+	encoded := map[string]interface{}{
+		"APPLICATION":   app,
+		"RELATIONSHIPS": rels,
+		"ROUTES":        routes,
+		"VARIABLES":     vars,
+	}
+
+	for k, v := range encoded {
+		s, err := encodeJSONBase64(v)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error encoding %s", k)
+		}
+		env[prefix+k] = s
+	}
+
+	return env, nil
+}
+
+// NewProjectEntropy generates a random value suitable for PROJECT_ENTROPY.
+func NewProjectEntropy() (string, error) {
+	s, err := randomSlug(35)
+	if err != nil {
+		return "", errors.Wrap(err, "error generating project entropy")
+	}
+	return s, nil
+}
+
+func randomSlug(n int) (string, error) {
+	slug := make([]byte, n)
+	if _, err := rand.Read(slug); err != nil {
+		return "", err
+	}
+	s := base32.StdEncoding.EncodeToString(slug)
+	s = strings.ToLower(s)
+	s = strings.TrimRight(s, "=")
+	return s, nil
+}
+
+func readLocalServices(path string) (Services, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		logrus.WithField("path", path).Debug("services config not found")
+		return nil, nil
+	}
+
+	rv, err := LoadServices(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "error parsing services config")
+	}
+	return rv, nil
+}
+
+func readLocalRoutes(path, host string) (Routes, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		logrus.WithField("path", path).Debug("routes config not found")
+		return Routes{}, nil
+	}
+
+	rv, err := LoadRoutes(path, RouteExpansion{Domains: []string{host}})
+	if err != nil {
+		return nil, errors.Wrap(err, "error parsing routes config")
+	}
+	return rv, nil
+}
+
+func (c LocalConfig) relationships(rels StringMap, services Services) (Relationships, error) {
+	links, err := services.Link(rels)
+	if err != nil {
+		return nil, err
+	}
+
+	rv := make(Relationships, len(rels))
+	for name, link := range links {
+		service, endpoint, svc := link.Service, link.Endpoint, link.Definition
+		kind := svc.Kind()
+
+		defaults, ok := localServices[kind]
+		if !ok {
+			logrus.WithField("type", svc.Type).Debug("no local defaults for service type")
+			defaults = localService{Scheme: kind}
+		}
+
+		port := defaults.Port
+		if p, ok := c.ServicePorts[service]; ok {
+			port = p
+		}
+		if p, ok := c.ServicePorts[name]; ok {
+			port = p
+		}
+
+		var ip string
+		if parsed := net.ParseIP(c.ServiceHost); parsed != nil {
+			ip = parsed.String()
+		}
+
+		rv[name] = []Relationship{
+			{
+				Cluster:  c.Project + "-" + c.Environment,
+				Host:     c.ServiceHost,
+				Hostname: c.ServiceHost,
+				IP:       ip,
+				Password: defaults.Password,
+				Path:     defaults.Path,
+				Port:     port,
+				Query:    JSONObject{"is_master": true},
+				Rel:      endpoint,
+				Scheme:   defaults.Scheme,
+				Service:  service,
+				Type:     svc.Type,
+				Username: defaults.Username,
+			},
+		}
+	}
+
+	return rv, nil
+}
+
+func flattenVariables(v Variables) Variables {
+	rv := make(Variables, len(v))
+	for group, val := range v {
+		obj, ok := val.(JSONObject)
+		if !ok {
+			rv[group] = val
+			continue
+		}
+
+		for name, val := range obj {
+			rv[group+":"+name] = val
+		}
+	}
+	return rv
+}
+
+func variableString(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+func encodeJSONBase64(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
@@ -0,0 +1,151 @@
+package pshgo_test
+
+import (
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	. "github.com/demosdemon/pshgo"
+)
+
+const localAppYAML = `name: app
+type: golang:1.12
+disk: 256
+relationships:
+  database: db:mysql
+  cache: redis:redis
+variables:
+  env:
+    GREETING: hello
+  app:
+    debug: true
+web:
+  locations:
+    /:
+      root: public
+      passthru: true
+`
+
+const localServicesYAML = `db:
+  type: mariadb:10.2
+  disk: 512
+redis:
+  type: redis:5.0
+`
+
+const localRoutesYAML = `https://{default}/:
+  type: upstream
+  upstream: app:http
+http://{default}/:
+  type: redirect
+  to: https://{default}/
+`
+
+func writeLocalProject(tb testing.TB, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "pshgo")
+	require.NoError(tb, err)
+
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(tb, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(tb, ioutil.WriteFile(path, []byte(data), 0644))
+	}
+
+	return dir
+}
+
+func TestNewLocalProvider(t *testing.T) {
+	dir := writeLocalProject(t, map[string]string{
+		AppConfigFile:      localAppYAML,
+		ServicesConfigFile: localServicesYAML,
+		RoutesConfigFile:   localRoutesYAML,
+	})
+	defer os.RemoveAll(dir)
+
+	cfg := DefaultLocalConfig()
+	cfg.Root = dir
+	cfg.Port = 3000
+	cfg.ServicePorts = map[string]int{"redis": 16379}
+
+	p, err := NewLocalProvider("PLATFORM_", cfg)
+	require.NoError(t, err)
+
+	env := NewEnvironmentWithProvider("PLATFORM_", p)
+	assert.Equal(t, "3000", env.GetPort())
+	assert.Equal(t, "app", env.GetApplicationName())
+	assert.Equal(t, filepath.Join(dir, "public"), env.GetDocumentRoot())
+	assert.Len(t, env.GetProjectEntropy(), 56)
+	assert.Equal(t, "hello", env.GetEnv("GREETING"))
+	assert.Equal(t, EnvironmentTypeDevelopment, env.GetEnvironmentType())
+
+	app := env.GetApplication()
+	require.NotNil(t, app)
+	assert.Equal(t, uint32(256), app.Disk)
+	assert.Equal(t, dir, app.AppDir)
+
+	rels := env.GetRelationships()
+	require.Len(t, rels, 2)
+	assert.Equal(t, "mysql://user@127.0.0.1:3306/main", rels["database"][0].URL(true, false))
+	assert.Equal(t, "redis://127.0.0.1:16379", rels["cache"][0].URL(true, false))
+
+	vars := env.GetVariables()
+	assert.Equal(t, "hello", vars["env:GREETING"])
+	assert.Equal(t, true, vars["app:debug"])
+
+	routes := env.GetRoutes()
+	require.Len(t, routes, 2)
+	https := routes[url.URL{Scheme: "https", Host: "localhost:3000", Path: "/"}]
+	assert.Equal(t, "https://{default}/", https.OriginalURL)
+	assert.Equal(t, "app:http", https.Upstream)
+	http := routes[url.URL{Scheme: "http", Host: "localhost:3000", Path: "/"}]
+	assert.Equal(t, "https://localhost:3000/", http.To)
+}
+
+func TestNewLocalProvider_Errors(t *testing.T) {
+	cases := []struct {
+		name  string
+		files map[string]string
+	}{
+		{
+			name:  "missing app",
+			files: map[string]string{},
+		},
+		{
+			name: "invalid app",
+			files: map[string]string{
+				AppConfigFile: "disk: [",
+			},
+		},
+		{
+			name: "unknown service",
+			files: map[string]string{
+				AppConfigFile: localAppYAML,
+			},
+		},
+		{
+			name: "invalid relationship",
+			files: map[string]string{
+				AppConfigFile:      "relationships:\n  database: db\n",
+				ServicesConfigFile: localServicesYAML,
+			},
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			dir := writeLocalProject(t, c.files)
+			defer os.RemoveAll(dir)
+
+			cfg := DefaultLocalConfig()
+			cfg.Root = dir
+			_, err := NewLocalProvider("PLATFORM_", cfg)
+			assert.Error(t, err)
+		})
+	}
+}
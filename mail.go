@@ -0,0 +1,385 @@
+package pshgo
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	DefaultSMTPPort    = "25"
+	DefaultMailTimeout = 30 * time.Second
+)
+
+var (
+	ErrMailDisabled = errors.New("SMTP_HOST is missing or empty; outgoing mail is disabled")
+	ErrNoRecipients = errors.New("message has no recipients")
+)
+
+type (
+	// Mailer sends messages through the outgoing mail host of the environment.
+	Mailer struct {
+		Addr      string
+		LocalName string
+		Timeout   time.Duration
+		Dialer    net.Dialer
+	}
+
+	Message struct {
+		From        string
+		To          []string
+		Cc          []string
+		Bcc         []string
+		ReplyTo     string
+		Subject     string
+		Text        string
+		HTML        string
+		Headers     StringMap
+		Attachments []Attachment
+		Date        time.Time
+		MessageID   string
+	}
+
+	Attachment struct {
+		Name        string
+		ContentType string
+		Data        []byte
+	}
+)
+
+func NewMailer(p PlatformProvider) (*Mailer, error) {
+	logrus.Trace("NewMailer")
+
+	host, ok := LookupSMTPHost(p)
+	host = strings.TrimSpace(host)
+	if !ok || host == "" {
+		return nil, ErrMailDisabled
+	}
+
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		host = net.JoinHostPort(host, DefaultSMTPPort)
+	}
+
+	return &Mailer{
+		Addr:    host,
+		Timeout: DefaultMailTimeout,
+	}, nil
+}
+
+func (e *Environment) Mailer() (*Mailer, error) {
+	return NewMailer(e)
+}
+
+func (e *Environment) SendMail(ctx context.Context, msg *Message) error {
+	m, err := e.Mailer()
+	if err != nil {
+		return err
+	}
+	return m.Send(ctx, msg)
+}
+
+func (m *Mailer) Send(ctx context.Context, msg *Message) (err error) {
+	log := logrus.WithField("addr", m.Addr).WithField("subject", msg.Subject)
+	log.Trace("Mailer.Send")
+
+	from, err := mail.ParseAddress(msg.From)
+	if err != nil {
+		return errors.Wrap(err, "invalid sender")
+	}
+
+	rcpts, err := msg.Recipients()
+	if err != nil {
+		return err
+	}
+
+	var data bytes.Buffer
+	if _, err := msg.WriteTo(&data); err != nil {
+		return errors.Wrap(err, "error composing message")
+	}
+
+	if m.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.Timeout)
+		defer cancel()
+	}
+
+	conn, err := m.Dialer.DialContext(ctx, "tcp", m.Addr)
+	if err != nil {
+		return errors.Wrap(err, "error connecting to SMTP host")
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	// abort any blocking network operation once the context is done
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = conn.Close()
+		case <-done:
+		}
+	}()
+
+	defer func() {
+		if ctxErr := ctx.Err(); err != nil && ctxErr != nil {
+			err = errors.Wrap(ctxErr, err.Error())
+		}
+	}()
+
+	host, _, _ := net.SplitHostPort(m.Addr)
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		_ = conn.Close()
+		return errors.Wrap(err, "error starting SMTP session")
+	}
+	defer c.Close()
+
+	if m.LocalName != "" {
+		if err := c.Hello(m.LocalName); err != nil {
+			return errors.Wrap(err, "error sending HELO")
+		}
+	}
+
+	if err := c.Mail(from.Address); err != nil {
+		return errors.Wrap(err, "error sending MAIL")
+	}
+
+	for _, rcpt := range rcpts {
+		if err := c.Rcpt(rcpt); err != nil {
+			return errors.Wrapf(err, "error sending RCPT for %s", rcpt)
+		}
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return errors.Wrap(err, "error sending DATA")
+	}
+
+	if _, err := data.WriteTo(w); err != nil {
+		return errors.Wrap(err, "error writing message")
+	}
+
+	if err := w.Close(); err != nil {
+		return errors.Wrap(err, "error finishing message")
+	}
+
+	log.WithField("recipients", len(rcpts)).Debug("sent message")
+	return c.Quit()
+}
+
+// Recipients returns the envelope addresses of every To, Cc and Bcc recipient.
+func (msg *Message) Recipients() ([]string, error) {
+	var rv []string
+	for _, list := range [][]string{msg.To, msg.Cc, msg.Bcc} {
+		for _, v := range list {
+			addr, err := mail.ParseAddress(v)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid recipient %q", v)
+			}
+			rv = append(rv, addr.Address)
+		}
+	}
+
+	if len(rv) == 0 {
+		return nil, ErrNoRecipients
+	}
+
+	return rv, nil
+}
+
+// Attach reads a file from disk and attaches it to the message.
+func (msg *Message) Attach(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	name := filepath.Base(path)
+	msg.Attachments = append(msg.Attachments, Attachment{
+		Name:        name,
+		ContentType: mime.TypeByExtension(filepath.Ext(name)),
+		Data:        data,
+	})
+	return nil
+}
+
+// WriteTo writes the message in MIME format. Bcc recipients are omitted.
+func (msg *Message) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+
+	date := msg.Date
+	if date.IsZero() {
+		date = time.Now()
+	}
+
+	id := msg.MessageID
+	if id == "" {
+		slug, err := randomSlug(16)
+		if err != nil {
+			return 0, errors.Wrap(err, "error generating message id")
+		}
+		id = fmt.Sprintf("<%s@pshgo>", slug)
+	}
+
+	h := make(textproto.MIMEHeader)
+	for k, v := range msg.Headers {
+		h.Set(k, v)
+	}
+	h.Set("MIME-Version", "1.0")
+	h.Set("Date", date.Format(time.RFC1123Z))
+	h.Set("Message-ID", id)
+	h.Set("From", msg.From)
+	h.Set("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	if len(msg.To) > 0 {
+		h.Set("To", strings.Join(msg.To, ", "))
+	}
+	if len(msg.Cc) > 0 {
+		h.Set("Cc", strings.Join(msg.Cc, ", "))
+	}
+	if msg.ReplyTo != "" {
+		if _, err := mail.ParseAddress(msg.ReplyTo); err != nil {
+			return 0, errors.Wrap(err, "invalid reply-to address")
+		}
+		h.Set("Reply-To", msg.ReplyTo)
+	}
+
+	for k, vs := range h {
+		for _, v := range vs {
+			if err := checkHeader(k, v); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	body, bh := msg.body()
+
+	if len(msg.Attachments) == 0 {
+		for k, v := range bh {
+			h[k] = v
+		}
+		writeHeader(&buf, h)
+		_, _ = body.WriteTo(&buf)
+	} else {
+		mw := multipart.NewWriter(&buf)
+		h.Set("Content-Type", "multipart/mixed; boundary="+mw.Boundary())
+		writeHeader(&buf, h)
+
+		pw, _ := mw.CreatePart(bh)
+		_, _ = body.WriteTo(pw)
+
+		for _, a := range msg.Attachments {
+			a.write(mw)
+		}
+		_ = mw.Close()
+	}
+
+	return buf.WriteTo(w)
+}
+
+func (msg *Message) body() (*bytes.Buffer, textproto.MIMEHeader) {
+	var buf bytes.Buffer
+	h := make(textproto.MIMEHeader)
+
+	switch {
+	case msg.Text != "" && msg.HTML != "":
+		mw := multipart.NewWriter(&buf)
+		h.Set("Content-Type", "multipart/alternative; boundary="+mw.Boundary())
+		writeTextPart(mw, "text/plain; charset=utf-8", msg.Text)
+		writeTextPart(mw, "text/html; charset=utf-8", msg.HTML)
+		_ = mw.Close()
+	case msg.HTML != "":
+		h.Set("Content-Type", "text/html; charset=utf-8")
+		h.Set("Content-Transfer-Encoding", "quoted-printable")
+		writeQuotedPrintable(&buf, msg.HTML)
+	default:
+		h.Set("Content-Type", "text/plain; charset=utf-8")
+		h.Set("Content-Transfer-Encoding", "quoted-printable")
+		writeQuotedPrintable(&buf, msg.Text)
+	}
+
+	return &buf, h
+}
+
+func (a Attachment) write(mw *multipart.Writer) {
+	ct := a.ContentType
+	if ct == "" {
+		ct = "application/octet-stream"
+	}
+
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Type", ct)
+	h.Set("Content-Transfer-Encoding", "base64")
+	h.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": a.Name}))
+
+	w, _ := mw.CreatePart(h)
+	enc := base64.StdEncoding.EncodeToString(a.Data)
+	for len(enc) > 76 {
+		_, _ = io.WriteString(w, enc[:76]+"\r\n")
+		enc = enc[76:]
+	}
+	_, _ = io.WriteString(w, enc+"\r\n")
+}
+
+func writeTextPart(mw *multipart.Writer, ct, body string) {
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Type", ct)
+	h.Set("Content-Transfer-Encoding", "quoted-printable")
+	w, _ := mw.CreatePart(h)
+	writeQuotedPrintable(w, body)
+}
+
+func writeQuotedPrintable(w io.Writer, body string) {
+	qp := quotedprintable.NewWriter(w)
+	_, _ = io.WriteString(qp, body)
+	_ = qp.Close()
+}
+
+// checkHeader rejects the header fields that would not fit on their own
+// line, so that values cannot inject headers of their own.
+func checkHeader(k, v string) error {
+	invalid := func(r rune) bool {
+		return r <= ' ' || r > '~' || r == ':'
+	}
+	if k == "" || strings.IndexFunc(k, invalid) >= 0 {
+		return errors.Errorf("invalid header name %q", k)
+	}
+	if strings.ContainsAny(v, "\r\n") {
+		return errors.Errorf("invalid value for header %s: contains a line break", k)
+	}
+	return nil
+}
+
+func writeHeader(buf *bytes.Buffer, h textproto.MIMEHeader) {
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		for _, v := range h[k] {
+			_, _ = fmt.Fprintf(buf, "%s: %s\r\n", k, v)
+		}
+	}
+	buf.WriteString("\r\n")
+}
@@ -0,0 +1,258 @@
+package pshgo_test
+
+import (
+	"context"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	. "github.com/demosdemon/pshgo"
+)
+
+type smtpEnvelope struct {
+	From string
+	To   []string
+	Data []byte
+}
+
+// smtpStandIn is a minimal in-process SMTP server that records every message
+// it receives.
+type smtpStandIn struct {
+	net.Listener
+	received chan smtpEnvelope
+	silent   bool
+}
+
+func newSMTPStandIn(tb testing.TB, silent bool) *smtpStandIn {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(tb, err)
+
+	s := &smtpStandIn{Listener: l, received: make(chan smtpEnvelope, 1), silent: silent}
+	go s.serve()
+	return s
+}
+
+func (s *smtpStandIn) serve() {
+	for {
+		conn, err := s.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(textproto.NewConn(conn))
+	}
+}
+
+func (s *smtpStandIn) handle(c *textproto.Conn) {
+	defer c.Close()
+	if s.silent {
+		_, _ = c.ReadLine()
+		return
+	}
+
+	_ = c.PrintfLine("220 localhost ESMTP stand-in")
+
+	var env smtpEnvelope
+	for {
+		line, err := c.ReadLine()
+		if err != nil {
+			return
+		}
+
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch verb {
+		case "EHLO", "HELO":
+			_ = c.PrintfLine("250 localhost")
+		case "MAIL":
+			env.From = addrOf(line)
+			_ = c.PrintfLine("250 OK")
+		case "RCPT":
+			env.To = append(env.To, addrOf(line))
+			_ = c.PrintfLine("250 OK")
+		case "DATA":
+			_ = c.PrintfLine("354 go ahead")
+			env.Data, err = c.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			_ = c.PrintfLine("250 queued")
+			s.received <- env
+			env = smtpEnvelope{}
+		case "QUIT":
+			_ = c.PrintfLine("221 bye")
+			return
+		default:
+			_ = c.PrintfLine("250 OK")
+		}
+	}
+}
+
+func addrOf(line string) string {
+	start, end := strings.Index(line, "<"), strings.Index(line, ">")
+	return line[start+1 : end]
+}
+
+func TestNewMailer(t *testing.T) {
+	cases := []struct {
+		name string
+		env  MapProvider
+		addr string
+		err  error
+	}{
+		{
+			name: "missing",
+			env:  MapProvider{},
+			err:  ErrMailDisabled,
+		},
+		{
+			name: "empty",
+			env:  MapProvider{"PLATFORM_SMTP_HOST": " "},
+			err:  ErrMailDisabled,
+		},
+		{
+			name: "host",
+			env:  MapProvider{"PLATFORM_SMTP_HOST": "169.254.169.254"},
+			addr: "169.254.169.254:25",
+		},
+		{
+			name: "host port",
+			env:  MapProvider{"PLATFORM_SMTP_HOST": "localhost:2525"},
+			addr: "localhost:2525",
+		},
+	}
+
+	t.Parallel()
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			env := NewEnvironmentWithProvider("PLATFORM_", c.env)
+			m, err := env.Mailer()
+			assert.Equal(t, c.err, err)
+			if c.err == nil {
+				assert.Equal(t, c.addr, m.Addr)
+			}
+		})
+	}
+}
+
+func TestEnvironment_SendMail(t *testing.T) {
+	s := newSMTPStandIn(t, false)
+	defer s.Close()
+
+	env := NewEnvironmentWithProvider("PLATFORM_", MapProvider{"PLATFORM_SMTP_HOST": s.Addr().String()})
+
+	msg := &Message{
+		From:    "App <app@example.com>",
+		To:      []string{"Alice <alice@example.com>"},
+		Cc:      []string{"bob@example.com"},
+		Bcc:     []string{"carol@example.com"},
+		Subject: "Héllo",
+		Text:    "plain body",
+		HTML:    "<p>html body</p>",
+		Attachments: []Attachment{
+			{Name: "report.txt", ContentType: "text/plain", Data: []byte("attached")},
+		},
+	}
+
+	require.NoError(t, env.SendMail(context.Background(), msg))
+
+	got := <-s.received
+	assert.Equal(t, "app@example.com", got.From)
+	assert.Equal(t, []string{"alice@example.com", "bob@example.com", "carol@example.com"}, got.To)
+
+	parsed, err := mail.ReadMessage(strings.NewReader(string(got.Data)))
+	require.NoError(t, err)
+	assert.Equal(t, "Alice <alice@example.com>", parsed.Header.Get("To"))
+	assert.Equal(t, "", parsed.Header.Get("Bcc"))
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "Héllo", subject)
+
+	mt, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/mixed", mt)
+
+	mr := multipart.NewReader(parsed.Body, params["boundary"])
+
+	body, err := mr.NextPart()
+	require.NoError(t, err)
+	mt, params, err = mime.ParseMediaType(body.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/alternative", mt)
+
+	alt := multipart.NewReader(body, params["boundary"])
+	for _, want := range []string{"plain body", "<p>html body</p>"} {
+		part, err := alt.NextPart()
+		require.NoError(t, err)
+		data, err := ioutil.ReadAll(part)
+		require.NoError(t, err)
+		assert.Equal(t, want, string(data))
+	}
+
+	attachment, err := mr.NextPart()
+	require.NoError(t, err)
+	assert.Equal(t, "report.txt", attachment.FileName())
+	assert.Equal(t, "base64", attachment.Header.Get("Content-Transfer-Encoding"))
+}
+
+func TestMailer_Send_Timeout(t *testing.T) {
+	s := newSMTPStandIn(t, true)
+	defer s.Close()
+
+	m := &Mailer{Addr: s.Addr().String(), Timeout: 50 * time.Millisecond}
+	msg := &Message{From: "app@example.com", To: []string{"alice@example.com"}, Text: "body"}
+
+	start := time.Now()
+	err := m.Send(context.Background(), msg)
+	assert.Error(t, err)
+	assert.True(t, time.Since(start) < 5*time.Second)
+}
+
+func TestMailer_Send_Invalid(t *testing.T) {
+	m := &Mailer{Addr: "127.0.0.1:1"}
+
+	err := m.Send(context.Background(), &Message{From: "not an address"})
+	assert.Error(t, err)
+
+	err = m.Send(context.Background(), &Message{From: "app@example.com"})
+	assert.Equal(t, ErrNoRecipients, err)
+}
+
+func TestMessage_WriteTo_HeaderInjection(t *testing.T) {
+	cases := []struct {
+		msg      Message
+		expected string
+	}{
+		{Message{Headers: StringMap{"X-Bad\r\nBcc": "eve@example.com"}}, `invalid header name "X-Bad\r\nBcc"`},
+		{Message{Headers: StringMap{"X Bad": "value"}}, `invalid header name "X Bad"`},
+		{Message{Headers: StringMap{"X-Tag": "a\r\nBcc: eve@example.com"}}, "invalid value for header X-Tag: contains a line break"},
+		{Message{To: []string{"alice@example.com\nBcc: eve@example.com"}}, "invalid value for header To: contains a line break"},
+		{Message{ReplyTo: "alice@example.com\r\nBcc: eve@example.com"}, "invalid reply-to address: "},
+		{Message{ReplyTo: "not an address"}, "invalid reply-to address: "},
+	}
+
+	for _, c := range cases {
+		c.msg.From = "app@example.com"
+		_, err := c.msg.WriteTo(ioutil.Discard)
+		if assert.Error(t, err, c.expected) {
+			assert.True(t, strings.HasPrefix(err.Error(), c.expected), err.Error())
+		}
+	}
+
+	msg := &Message{
+		From:    "app@example.com",
+		ReplyTo: "Support <support@example.com>",
+		Headers: StringMap{"X-Tag": "ok"},
+	}
+	_, err := msg.WriteTo(ioutil.Discard)
+	assert.NoError(t, err)
+}
@@ -23,3 +23,21 @@ func (p Passthru) MarshalJSON() ([]byte, error) {
 
 	return json.Marshal(p.Path)
 }
+
+func (p *Passthru) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	if err := unmarshal(&p.Enabled); err == nil {
+		p.Path = ""
+		return nil
+	}
+
+	p.Enabled = true
+	return unmarshal(&p.Path)
+}
+
+func (p Passthru) MarshalYAML() (interface{}, error) {
+	if p.Path == "" {
+		return p.Enabled, nil
+	}
+
+	return p.Path, nil
+}
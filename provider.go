@@ -45,6 +45,21 @@ func ReadEnviron(r io.Reader) (MapProvider, error) {
 	return MapProvider(hash), err
 }
 
+// ReadDotEnv reads the .env file at path, returning an empty provider when it
+// does not exist.
+func ReadDotEnv(path string) (MapProvider, error) {
+	logrus.WithField("path", path).Trace("ReadDotEnv")
+	hash, err := godotenv.Read(path)
+	if os.IsNotExist(err) {
+		logrus.WithField("path", path).Info(".env file not found")
+		return MapProvider{}, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read .env file")
+	}
+	return MapProvider(hash), nil
+}
+
 func ParseEnviron(s []string) (MapProvider, error) {
 	var buf bytes.Buffer
 	for _, line := range s {
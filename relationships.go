@@ -9,22 +9,22 @@ import (
 type Relationships map[string][]Relationship
 
 type Relationship struct {
-	Cluster  string     `json:"cluster"`
-	Fragment string     `json:"fragment"`
-	Host     string     `json:"host"`
-	Hostname string     `json:"hostname"`
-	IP       string     `json:"ip"`
-	Password string     `json:"password"`
-	Path     string     `json:"path"`
-	Port     int        `json:"port"`
-	Public   bool       `json:"public"`
-	Query    JSONObject `json:"query"`
-	Rel      string     `json:"rel"`
-	Scheme   string     `json:"scheme"`
-	Service  string     `json:"service"`
-	SSL      JSONObject `json:"ssl"`
-	Type     string     `json:"type"`
-	Username string     `json:"username"`
+	Cluster  string     `json:"cluster" yaml:"cluster"`
+	Fragment string     `json:"fragment" yaml:"fragment"`
+	Host     string     `json:"host" yaml:"host"`
+	Hostname string     `json:"hostname" yaml:"hostname"`
+	IP       string     `json:"ip" yaml:"ip"`
+	Password string     `json:"password" yaml:"password"`
+	Path     string     `json:"path" yaml:"path"`
+	Port     int        `json:"port" yaml:"port"`
+	Public   bool       `json:"public" yaml:"public"`
+	Query    JSONObject `json:"query" yaml:"query"`
+	Rel      string     `json:"rel" yaml:"rel"`
+	Scheme   string     `json:"scheme" yaml:"scheme"`
+	Service  string     `json:"service" yaml:"service"`
+	SSL      JSONObject `json:"ssl" yaml:"ssl"`
+	Type     string     `json:"type" yaml:"type"`
+	Username string     `json:"username" yaml:"username"`
 }
 
 func (r Relationship) URL(user, query bool) string {
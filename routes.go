@@ -2,8 +2,13 @@ package pshgo
 
 import (
 	"encoding/json"
+	"math"
+	"net/http"
 	"net/url"
+	gopath "path"
+	"strings"
 
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 )
 
@@ -42,3 +47,255 @@ func (r *Routes) UnmarshalJSON(text []byte) error {
 
 	return nil
 }
+
+// IsRedirect reports whether the route only redirects to another URL.
+func (r Route) IsRedirect() bool {
+	return r.Type == "redirect"
+}
+
+// Match returns the route serving u with its representation: the route with
+// the scheme of u, the most specific host matching it (wildcards such as
+// *.example.com match any of its subdomains) and the longest path prefix.
+func (r Routes) Match(u *url.URL) (Route, RouteRepresentation, bool) {
+	logrus.WithField("url", u).Trace("Routes.Match")
+
+	var (
+		best      url.URL
+		bestHost  = -1
+		bestPath  = -1
+		hostname  = strings.ToLower(u.Hostname())
+		fullHost  = strings.ToLower(u.Host)
+		path      = u.Path
+		found     bool
+		candidate Route
+	)
+
+	if path == "" {
+		path = "/"
+	}
+
+	for k, v := range r {
+		if k.Scheme != u.Scheme {
+			continue
+		}
+
+		host := matchRouteHost(strings.ToLower(k.Host), hostname, fullHost)
+		if host < 0 || host < bestHost {
+			continue
+		}
+
+		prefix := k.Path
+		if prefix == "" {
+			prefix = "/"
+		}
+		if !MatchRoutePath(prefix, path) {
+			continue
+		}
+
+		if host == bestHost && len(prefix) <= bestPath {
+			continue
+		}
+
+		best, bestHost, bestPath, candidate, found = k, host, len(prefix), v, true
+	}
+
+	if !found {
+		return Route{}, RouteRepresentation{}, false
+	}
+
+	return candidate, RouteRepresentation{
+		Route: RouteIdentification{
+			Scheme: best.Scheme,
+			Host:   best.Host,
+			Path:   best.Path,
+		},
+	}, true
+}
+
+// MatchRequest returns the route serving req, using the scheme forwarded by
+// the router when the request did not arrive over TLS.
+func (r Routes) MatchRequest(req *http.Request) (Route, RouteRepresentation, bool) {
+	u := url.URL{Scheme: "http", Host: req.Host, Path: req.URL.Path}
+	if req.TLS != nil || strings.EqualFold(req.Header.Get("X-Forwarded-Proto"), "https") {
+		u.Scheme = "https"
+	}
+	return r.Match(&u)
+}
+
+// matchRouteHost returns how specific a route host is for a request host, or
+// -1 when it does not match. Exact hosts beat wildcards and longer wildcards
+// beat shorter ones.
+func matchRouteHost(pattern, hostname, host string) int {
+	if pattern == host || pattern == hostname {
+		return math.MaxInt32
+	}
+
+	if strings.HasPrefix(pattern, "*.") {
+		suffix := pattern[1:]
+		if strings.Contains(suffix, ":") {
+			hostname = host
+		}
+		if strings.HasSuffix(hostname, suffix) && len(hostname) > len(suffix) {
+			return len(suffix)
+		}
+	}
+
+	return -1
+}
+
+// MatchRoutePath reports whether prefix is a prefix of path ending on a
+// segment boundary, the way the router matches the paths of routes and
+// locations.
+func MatchRoutePath(prefix, path string) bool {
+	if !strings.HasPrefix(path, prefix) {
+		return false
+	}
+	return len(path) == len(prefix) || strings.HasSuffix(prefix, "/") || path[len(prefix)] == '/'
+}
+
+// Primary returns the primary route.
+func (r Routes) Primary() (url.URL, Route, bool) {
+	for k, v := range r {
+		if v.Primary {
+			return k, v, true
+		}
+	}
+	return url.URL{}, Route{}, false
+}
+
+// ByID returns the route with the given id. When several URLs share the id,
+// https is preferred.
+func (r Routes) ByID(id string) (url.URL, Route, bool) {
+	return r.filter(func(v Route) bool {
+		return v.ID != nil && *v.ID == id
+	}).first()
+}
+
+// ForUpstream returns the routes served by upstream, such as app:http. An
+// upstream without endpoint matches its http endpoint.
+func (r Routes) ForUpstream(upstream string) Routes {
+	if !strings.Contains(upstream, ":") {
+		upstream += ":http"
+	}
+
+	return r.filter(func(v Route) bool {
+		return v.Type == "upstream" && v.Upstream == upstream
+	})
+}
+
+func (r Routes) filter(fn func(Route) bool) Routes {
+	rv := make(Routes)
+	for k, v := range r {
+		if fn(v) {
+			rv[k] = v
+		}
+	}
+	return rv
+}
+
+// first returns the preferred route: the primary one, then https, then the
+// shortest URL, so the choice is stable.
+func (r Routes) first() (url.URL, Route, bool) {
+	var (
+		best  url.URL
+		route Route
+		found bool
+	)
+
+	for k, v := range r {
+		if !found || routeLess(k, v, best, route) {
+			best, route, found = k, v, true
+		}
+	}
+
+	return best, route, found
+}
+
+func routeLess(ak url.URL, a Route, bk url.URL, b Route) bool {
+	if a.Primary != b.Primary {
+		return a.Primary
+	}
+	if (ak.Scheme == "https") != (bk.Scheme == "https") {
+		return ak.Scheme == "https"
+	}
+	as, bs := ak.String(), bk.String()
+	if len(as) != len(bs) {
+		return len(as) < len(bs)
+	}
+	return as < bs
+}
+
+// MatchRoute returns the route of the environment serving u, with the project
+// and environment of its representation filled in.
+func (e *Environment) MatchRoute(u *url.URL) (Route, RouteRepresentation, bool) {
+	route, repr, ok := e.GetRoutes().Match(u)
+	if ok {
+		repr.Project = e.GetProject()
+		repr.Environment = e.GetEnvironment()
+	}
+	return route, repr, ok
+}
+
+var (
+	ErrRouteNotFound = errors.New("route not found")
+	ErrRouteRedirect = errors.New("route is a redirect")
+	ErrRouteWildcard = errors.New("route has a wildcard host")
+)
+
+// URLFor returns the absolute URL of path on the route with the id, or else
+// served by the upstream, idOrUpstream. The primary route is preferred, then
+// https.
+func (r Routes) URLFor(idOrUpstream, path string, query url.Values) (*url.URL, error) {
+	logrus.WithField("target", idOrUpstream).WithField("path", path).Trace("Routes.URLFor")
+
+	candidates := r.filter(func(v Route) bool {
+		return v.ID != nil && *v.ID == idOrUpstream
+	})
+	if len(candidates) == 0 {
+		candidates = r.ForUpstream(idOrUpstream)
+	}
+	if len(candidates) == 0 {
+		return nil, errors.Wrapf(ErrRouteNotFound, "no route has the id or upstream %q", idOrUpstream)
+	}
+
+	// routes with a wildcard host have no URL of their own
+	for k := range candidates {
+		if strings.HasPrefix(k.Host, "*.") {
+			delete(candidates, k)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, errors.Wrapf(ErrRouteWildcard, "every route of %q has a wildcard host", idOrUpstream)
+	}
+
+	if upstreams := candidates.filter(func(v Route) bool { return !v.IsRedirect() }); len(upstreams) > 0 {
+		candidates = upstreams
+	}
+
+	base, route, _ := candidates.first()
+	if route.IsRedirect() {
+		return nil, errors.Wrapf(ErrRouteRedirect, "route %q redirects to %s", idOrUpstream, route.To)
+	}
+
+	rv := base
+	rv.Path = joinRoutePath(base.Path, path)
+	rv.RawPath = ""
+	rv.RawQuery = query.Encode()
+	rv.Fragment = ""
+	return &rv, nil
+}
+
+// joinRoutePath appends p to the path of a route, resolving dot segments so
+// the result cannot escape base, and keeping a trailing slash.
+func joinRoutePath(base, p string) string {
+	base = strings.TrimSuffix(base, "/")
+	if p == "" {
+		return base + "/"
+	}
+
+	clean := gopath.Clean("/" + p)
+	if strings.HasSuffix(p, "/") && clean != "/" {
+		clean += "/"
+	}
+	return base + clean
+}
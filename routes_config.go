@@ -0,0 +1,360 @@
+package pshgo
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+)
+
+// RouteExpansion describes the hosts the placeholders of routes.yaml expand
+// to.
+type RouteExpansion struct {
+	// Domains are the domains of the project: {default} is replaced by the
+	// first one and {all} by each of them.
+	Domains []string
+	// Environment, when set, previews the routes of a non-production
+	// environment: every host is moved under the host of the environment,
+	// Environment-PreviewDomain (such as master-7rqtwti-abc.eu-3.platformsh.site).
+	Environment   string
+	PreviewDomain string
+}
+
+var (
+	routePlaceholder = regexp.MustCompile(`\{[^}]*\}`)
+	slugInvalid      = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+type routeExpansion struct {
+	URL    string
+	Domain string
+}
+
+// Expand returns the URLs a key of routes.yaml expands to.
+func (x RouteExpansion) Expand(pattern string) ([]string, error) {
+	expanded, err := x.expand(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	rv := make([]string, len(expanded))
+	for idx, e := range expanded {
+		rv[idx] = e.URL
+	}
+	return rv, nil
+}
+
+func (x RouteExpansion) expand(pattern string) ([]routeExpansion, error) {
+	idx := strings.Index(pattern, "://")
+	if idx <= 0 {
+		return nil, errors.Errorf("route %q has no scheme", pattern)
+	}
+	scheme, host, path := pattern[:idx+3], pattern[idx+3:], ""
+	if idx := strings.Index(host, "/"); idx >= 0 {
+		host, path = host[:idx], host[idx:]
+	}
+
+	if routePlaceholder.MatchString(path) {
+		return nil, errors.Errorf("route %q has a placeholder outside of its host", pattern)
+	}
+
+	placeholder := ""
+	domains := []string{""}
+	switch m := routePlaceholder.FindAllString(host, -1); {
+	case len(m) == 0:
+	case len(m) > 1 || !strings.HasSuffix(host, m[0]):
+		return nil, errors.Errorf("route %q must end its host with its placeholder", pattern)
+	case m[0] != "{default}" && m[0] != "{all}":
+		return nil, errors.Errorf("route %q has an unknown placeholder %s", pattern, m[0])
+	case len(x.Domains) == 0:
+		return nil, errors.Errorf("route %q has a placeholder but no domain is configured", pattern)
+	default:
+		placeholder = m[0]
+		domains = x.Domains[:1]
+		if placeholder == "{all}" && x.Environment == "" {
+			domains = x.Domains
+		}
+	}
+
+	rv := make([]routeExpansion, 0, len(domains))
+	for _, domain := range domains {
+		h := strings.TrimSuffix(host, placeholder) + domain
+		if x.Environment != "" {
+			h = x.previewHost(h)
+		}
+		rv = append(rv, routeExpansion{URL: scheme + h + path, Domain: domain})
+	}
+
+	return rv, nil
+}
+
+// expandTo expands the target of a redirect route, replacing {all} by the
+// domain its route was expanded for.
+func (x RouteExpansion) expandTo(to, domain string) (string, error) {
+	if !routePlaceholder.MatchString(to) {
+		return to, nil
+	}
+
+	if domain != "" {
+		x.Domains = append([]string{domain}, x.Domains...)
+	}
+
+	expanded, err := x.expand(strings.Replace(to, "{all}", "{default}", -1))
+	if err != nil {
+		return "", err
+	}
+	return expanded[0].URL, nil
+}
+
+func (x RouteExpansion) previewHost(host string) string {
+	env := strings.Trim(slugInvalid.ReplaceAllString(strings.ToLower(x.Environment), "-"), "-")
+	env += "-" + x.PreviewDomain
+
+	for _, domain := range x.Domains {
+		if host == domain {
+			return env
+		}
+		if strings.HasSuffix(host, "."+domain) {
+			return strings.TrimSuffix(host, domain) + env
+		}
+	}
+	return host + "." + env
+}
+
+// LoadRoutes reads a routes.yaml file and expands it into the routes the
+// router would generate.
+func LoadRoutes(path string, x RouteExpansion) (Routes, error) {
+	logrus.WithField("path", path).Trace("LoadRoutes")
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading routes config")
+	}
+
+	return ParseRoutes(path, data, x)
+}
+
+// ParseRoutes decodes the content of a routes.yaml file, expands the
+// placeholders of every route, fills in the defaults the router applies and
+// selects the primary route. Every error found is reported as a *ConfigError.
+func ParseRoutes(filename string, data []byte, x RouteExpansion) (Routes, error) {
+	logrus.WithField("filename", filename).WithField("expansion", x).Trace("ParseRoutes")
+
+	d := newYAMLDecoder(filename, data)
+
+	// the primary route depends on the order of the routes
+	var order yaml.MapSlice
+	if err := d.parse(data, &order); err != nil {
+		return nil, err
+	}
+	var nodes map[interface{}]interface{}
+	if err := d.parse(data, &nodes); err != nil {
+		return nil, err
+	}
+
+	var (
+		rv       = make(Routes)
+		keys     []url.URL
+		primary  = -1
+		exact    = -1
+		fallback = -1
+	)
+
+	for _, item := range order {
+		key := fmt.Sprint(item.Key)
+		path := []string{key}
+
+		route := defaultRoute()
+		if !d.decode(path, nodes[item.Key], reflect.ValueOf(&route).Elem()) {
+			continue
+		}
+		route.OriginalURL = key
+		redirectDefaults(nodes[item.Key], &route)
+
+		if !validateRoute(&route, func(field []string, err error) {
+			d.fail(append(path, field...), err)
+		}) {
+			continue
+		}
+
+		expanded, err := x.expand(key)
+		if err != nil {
+			d.fail(path, err)
+			continue
+		}
+
+		for idx, e := range expanded {
+			u, err := url.Parse(e.URL)
+			if err != nil {
+				d.fail(path, err)
+				break
+			}
+
+			if _, ok := rv[*u]; ok {
+				d.fail(path, fmt.Errorf("duplicate route %s", u))
+				continue
+			}
+
+			r := route
+			r.Primary = false
+			if r.To, err = x.expandTo(route.To, e.Domain); err != nil {
+				d.fail(append(path, "to"), err)
+				break
+			}
+
+			switch {
+			case route.Primary && primary >= 0 && idx == 0:
+				d.fail(append(path, "primary"), fmt.Errorf("%s is already the primary route", keys[primary].String()))
+			case route.Primary && idx == 0:
+				primary = len(keys)
+			case exact < 0 && r.Type == "upstream" && key == "https://{default}/":
+				exact = len(keys)
+			case fallback < 0 && r.Type == "upstream" && strings.Contains(key, "{default}") && !strings.HasPrefix(u.Host, "*."):
+				fallback = len(keys)
+			}
+
+			rv[*u] = r
+			keys = append(keys, *u)
+		}
+	}
+
+	if d.errs != nil {
+		return nil, sortConfigErrors(d.errs)
+	}
+
+	// without an explicit primary route, prefer https://{default}/, then the
+	// first upstream of {default} without a wildcard host
+	switch {
+	case primary >= 0:
+	case exact >= 0:
+		primary = exact
+	case fallback >= 0:
+		primary = fallback
+	case len(keys) > 0:
+		primary = 0
+	}
+
+	if primary >= 0 {
+		r := rv[keys[primary]]
+		r.Primary = true
+		rv[keys[primary]] = r
+	}
+
+	// plain http is redirected to https unless routed explicitly
+	for _, u := range keys {
+		if u.Scheme != "https" {
+			continue
+		}
+
+		plain := u
+		plain.Scheme = "http"
+		if _, ok := rv[plain]; ok {
+			continue
+		}
+
+		rv[plain] = Route{
+			OriginalURL: "http://" + strings.TrimPrefix(rv[u].OriginalURL, "https://"),
+			Type:        "redirect",
+			To:          u.String(),
+		}
+	}
+
+	return rv, nil
+}
+
+// defaultRoute returns the settings of a route not given by routes.yaml.
+func defaultRoute() Route {
+	return Route{
+		Attributes: map[string]string{},
+		Cache: Cache{
+			Enabled: true,
+			Cookies: []string{"*"},
+			Headers: []string{"Accept", "Accept-Language"},
+		},
+	}
+}
+
+// redirectDefaults turns on prefix and append_suffix for the redirect paths
+// of node which do not set them, as the router does.
+func redirectDefaults(node interface{}, r *Route) {
+	for path, rule := range r.Redirects.Paths {
+		if _, ok := yamlLookup(node, "redirects", "paths", path, "prefix"); !ok {
+			rule.Prefix = true
+		}
+		if _, ok := yamlLookup(node, "redirects", "paths", path, "append_suffix"); !ok {
+			rule.AppendSuffix = true
+		}
+		r.Redirects.Paths[path] = rule
+	}
+}
+
+var redirectCodes = map[int]bool{301: true, 302: true, 307: true, 308: true}
+
+// validateRoute checks and normalizes a route as read from routes.yaml.
+func validateRoute(r *Route, fail func(field []string, err error)) bool {
+	ok := true
+	failf := func(field []string, format string, args ...interface{}) {
+		fail(field, fmt.Errorf(format, args...))
+		ok = false
+	}
+
+	switch r.Type {
+	case "":
+		failf([]string{"type"}, "type is required")
+	case "upstream":
+		switch {
+		case r.Upstream == "":
+			failf([]string{"upstream"}, "upstream is required")
+		case !strings.Contains(r.Upstream, ":"):
+			r.Upstream += ":http"
+		case !isTypeVersion(r.Upstream):
+			failf([]string{"upstream"}, "expected app:endpoint, found %q", r.Upstream)
+		}
+		if r.To != "" {
+			failf([]string{"to"}, "upstream routes cannot redirect")
+		}
+	case "redirect":
+		if r.To == "" {
+			failf([]string{"to"}, "to is required")
+		}
+		if r.Upstream != "" {
+			failf([]string{"upstream"}, "redirect routes have no upstream")
+		}
+		r.Cache = Cache{}
+	default:
+		failf([]string{"type"}, "unknown route type %q; expected upstream or redirect", r.Type)
+	}
+
+	for path, p := range r.Redirects.Paths {
+		if p.Code == 0 {
+			p.Code = 301
+		}
+		if !redirectCodes[p.Code] {
+			failf([]string{"redirects", "paths", path, "code"}, "invalid redirect code %d", p.Code)
+		}
+		if p.To == "" {
+			failf([]string{"redirects", "paths", path, "to"}, "to is required")
+		}
+		if p.Regexp {
+			if _, err := regexp.Compile(path); err != nil {
+				failf([]string{"redirects", "paths", path}, "%v", err)
+			}
+		}
+		r.Redirects.Paths[path] = p
+	}
+
+	for idx, addr := range r.HTTPAccess.Addresses {
+		if _, err := ParseAccessRule(addr); err != nil {
+			fail([]string{"http_access", "addresses", indexSegment(idx)}, err)
+			ok = false
+		}
+	}
+
+	return ok
+}
@@ -0,0 +1,187 @@
+package pshgo_test
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	. "github.com/demosdemon/pshgo"
+)
+
+const routesYAML = `"https://www.{default}/":
+  type: upstream
+  upstream: app
+  id: main
+  cache:
+    enabled: false
+
+"https://{all}/":
+  type: redirect
+  to: "https://www.{all}/"
+
+"https://*.{default}/":
+  type: upstream
+  upstream: "app:http"
+
+"https://api.example.org/":
+  type: upstream
+  upstream: "api:http"
+  redirects:
+    expires: 1h
+    paths:
+      "/v1":
+        to: "/v2"
+        prefix: true
+`
+
+func TestRouteExpansion_Expand(t *testing.T) {
+	cases := []struct {
+		name      string
+		expansion RouteExpansion
+		pattern   string
+		expected  []string
+	}{
+		{"default", RouteExpansion{Domains: []string{"example.com", "example.net"}}, "https://{default}/", []string{"https://example.com/"}},
+		{"all", RouteExpansion{Domains: []string{"example.com", "example.net"}}, "https://www.{all}/blog", []string{"https://www.example.com/blog", "https://www.example.net/blog"}},
+		{"literal", RouteExpansion{Domains: []string{"example.com"}}, "http://example.org/", []string{"http://example.org/"}},
+		{"preview", RouteExpansion{Domains: []string{"example.com"}, Environment: "Feature/Login", PreviewDomain: "abc.eu.platformsh.site"}, "https://www.{default}/", []string{"https://www.feature-login-abc.eu.platformsh.site/"}},
+		{"preview all", RouteExpansion{Domains: []string{"example.com", "example.net"}, Environment: "dev", PreviewDomain: "abc.eu.platformsh.site"}, "https://{all}/", []string{"https://dev-abc.eu.platformsh.site/"}},
+		{"preview literal", RouteExpansion{Domains: []string{"example.com"}, Environment: "dev", PreviewDomain: "abc.eu.platformsh.site"}, "https://api.example.com/", []string{"https://api.dev-abc.eu.platformsh.site/"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := tc.expansion.Expand(tc.pattern)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+
+	for _, pattern := range []string{"{default}/", "https://{default}/{all}", "https://{default}.com/", "https://{branch}/"} {
+		_, err := RouteExpansion{Domains: []string{"example.com"}}.Expand(pattern)
+		assert.Error(t, err, pattern)
+	}
+}
+
+func TestParseRoutes(t *testing.T) {
+	routes, err := ParseRoutes("routes.yaml", []byte(routesYAML), RouteExpansion{Domains: []string{"example.com", "example.net"}})
+	require.NoError(t, err)
+	require.Len(t, routes, 10)
+
+	www := routes[url.URL{Scheme: "https", Host: "www.example.com", Path: "/"}]
+	assert.True(t, www.Primary)
+	require.NotNil(t, www.ID)
+	assert.Equal(t, "main", *www.ID)
+	assert.Equal(t, "https://www.{default}/", www.OriginalURL)
+	assert.Equal(t, "app:http", www.Upstream)
+	assert.False(t, www.Cache.Enabled)
+	assert.Equal(t, []string{"*"}, www.Cache.Cookies)
+
+	net := routes[url.URL{Scheme: "https", Host: "example.net", Path: "/"}]
+	assert.False(t, net.Primary)
+	assert.Equal(t, "redirect", net.Type)
+	assert.Equal(t, "https://www.example.net/", net.To)
+
+	wildcard := routes[url.URL{Scheme: "https", Host: "*.example.com", Path: "/"}]
+	assert.Equal(t, "app:http", wildcard.Upstream)
+	assert.True(t, wildcard.Cache.Enabled)
+
+	api := routes[url.URL{Scheme: "https", Host: "api.example.org", Path: "/"}]
+	assert.Equal(t, time.Hour, api.Redirects.Expires.Duration)
+	assert.Equal(t, RedirectPath{To: "/v2", Prefix: true, AppendSuffix: true, Code: 301}, api.Redirects.Paths["/v1"])
+
+	plain := routes[url.URL{Scheme: "http", Host: "www.example.com", Path: "/"}]
+	assert.Equal(t, Route{OriginalURL: "http://www.{default}/", Type: "redirect", To: "https://www.example.com/"}, plain)
+}
+
+func TestParseRoutes_Primary(t *testing.T) {
+	const data = `"https://api.{default}/":
+  type: upstream
+  upstream: api:http
+"https://{default}/":
+  type: upstream
+  upstream: app:http
+`
+
+	x := RouteExpansion{Domains: []string{"example.com"}}
+	routes, err := ParseRoutes("routes.yaml", []byte(data), x)
+	require.NoError(t, err)
+	assert.False(t, routes[url.URL{Scheme: "https", Host: "api.example.com", Path: "/"}].Primary)
+	assert.True(t, routes[url.URL{Scheme: "https", Host: "example.com", Path: "/"}].Primary)
+
+	const wildcard = `"https://*.{default}/":
+  type: upstream
+  upstream: tenant:http
+"https://api.{default}/":
+  type: upstream
+  upstream: api:http
+`
+
+	routes, err = ParseRoutes("routes.yaml", []byte(wildcard), x)
+	require.NoError(t, err)
+	assert.False(t, routes[url.URL{Scheme: "https", Host: "*.example.com", Path: "/"}].Primary)
+	assert.True(t, routes[url.URL{Scheme: "https", Host: "api.example.com", Path: "/"}].Primary)
+
+	routes, err = ParseRoutes("routes.yaml", []byte(wildcard+`"https://{default}/":
+  type: upstream
+  upstream: app:http
+`), x)
+	require.NoError(t, err)
+	assert.True(t, routes[url.URL{Scheme: "https", Host: "example.com", Path: "/"}].Primary)
+}
+
+func TestParseRoutes_Errors(t *testing.T) {
+	const data = `"https://{default}/":
+  type: upstream
+  upstream: app:http
+  primary: true
+"https://www.{default}/":
+  type: upstream
+  upstream: app:http
+  primary: true
+"https://{branch}/":
+  type: redirect
+  to: https://{default}/
+"http://{default}/":
+  type: proxy
+"https://cdn.{default}/":
+  type: upstream
+"https://api.{default}/":
+  type: upstream
+  upstream: api
+  redirects:
+    paths:
+      "/old":
+        to: /new
+        code: 200
+"https://admin.{default}/":
+  type: upstream
+  upstream: admin
+  http_access:
+    addresses:
+      - allow 192.0.2.0/24
+      - deny everyone
+`
+
+	_, err := ParseRoutes("routes.yaml", []byte(data), RouteExpansion{Domains: []string{"example.com"}})
+	require.Error(t, err)
+	require.IsType(t, &multierror.Error{}, err)
+
+	var errs []string
+	for _, err := range err.(*multierror.Error).Errors {
+		errs = append(errs, err.Error())
+	}
+
+	assert.Equal(t, []string{
+		`routes.yaml:8:3: ["https://www.{default}/"].primary: https://example.com/ is already the primary route`,
+		`routes.yaml:9:1: ["https://{branch}/"]: route "https://{branch}/" has an unknown placeholder {branch}`,
+		`routes.yaml:13:3: ["http://{default}/"].type: unknown route type "proxy"; expected upstream or redirect`,
+		`routes.yaml:14:1: ["https://cdn.{default}/"].upstream: upstream is required`,
+		`routes.yaml:23:9: ["https://api.{default}/"].redirects.paths["/old"].code: invalid redirect code 200`,
+		`routes.yaml:30:7: ["https://admin.{default}/"].http_access.addresses[1]: invalid address "deny everyone"`,
+	}, errs)
+}
@@ -0,0 +1,183 @@
+package pshgo_test
+
+import (
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	. "github.com/demosdemon/pshgo"
+)
+
+func testRoutes(t *testing.T) Routes {
+	const data = `"https://{default}/":
+  type: upstream
+  upstream: app:http
+  id: main
+"https://{default}/api":
+  type: upstream
+  upstream: api:http
+"https://*.{default}/":
+  type: upstream
+  upstream: tenant:http
+"https://admin.{default}/":
+  type: upstream
+  upstream: admin:http
+"https://www.{default}/":
+  type: redirect
+  to: https://{default}/
+`
+
+	routes, err := ParseRoutes("routes.yaml", []byte(data), RouteExpansion{Domains: []string{"example.com"}})
+	require.NoError(t, err)
+	return routes
+}
+
+func TestRoutes_Match(t *testing.T) {
+	routes := testRoutes(t)
+
+	cases := []struct {
+		url      string
+		upstream string
+		route    string
+	}{
+		{"https://example.com/", "app:http", "https://example.com/"},
+		{"https://example.com/about", "app:http", "https://example.com/"},
+		{"https://example.com/api", "api:http", "https://example.com/api"},
+		{"https://example.com/api/v1/users", "api:http", "https://example.com/api"},
+		{"https://example.com/apis", "app:http", "https://example.com/"},
+		{"https://EXAMPLE.com:443/api", "api:http", "https://example.com/api"},
+		{"https://acme.example.com/login", "tenant:http", "https://*.example.com/"},
+		{"https://a.b.example.com/", "tenant:http", "https://*.example.com/"},
+		{"https://admin.example.com/", "admin:http", "https://admin.example.com/"},
+		{"https://www.example.com/", "", "https://www.example.com/"},
+		{"http://example.com/", "", "http://example.com/"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.url, func(t *testing.T) {
+			u, err := url.Parse(tc.url)
+			require.NoError(t, err)
+
+			route, repr, ok := routes.Match(u)
+			require.True(t, ok)
+			assert.Equal(t, tc.upstream, route.Upstream)
+			assert.Equal(t, tc.upstream == "", route.IsRedirect())
+
+			matched := url.URL{Scheme: repr.Route.Scheme, Host: repr.Route.Host, Path: repr.Route.Path}
+			assert.Equal(t, tc.route, matched.String())
+		})
+	}
+
+	for _, missing := range []string{"https://example.org/", "ftp://example.com/", "https://example.com.evil/"} {
+		u, err := url.Parse(missing)
+		require.NoError(t, err)
+		_, _, ok := routes.Match(u)
+		assert.False(t, ok, missing)
+	}
+}
+
+func TestRoutes_MatchRequest(t *testing.T) {
+	routes := testRoutes(t)
+
+	req := httptest.NewRequest("GET", "http://example.com/api/users", nil)
+	route, _, ok := routes.MatchRequest(req)
+	require.True(t, ok)
+	assert.True(t, route.IsRedirect())
+
+	req.Header.Set("X-Forwarded-Proto", "https")
+	route, _, ok = routes.MatchRequest(req)
+	require.True(t, ok)
+	assert.Equal(t, "api:http", route.Upstream)
+
+	req = httptest.NewRequest("GET", "http://example.com/", nil)
+	req.TLS = &tls.ConnectionState{}
+	route, _, ok = routes.MatchRequest(req)
+	require.True(t, ok)
+	assert.Equal(t, "app:http", route.Upstream)
+}
+
+func TestRoutes_Queries(t *testing.T) {
+	routes := testRoutes(t)
+
+	u, route, ok := routes.Primary()
+	require.True(t, ok)
+	assert.Equal(t, "https://example.com/", u.String())
+	assert.True(t, route.Primary)
+
+	u, route, ok = routes.ByID("main")
+	require.True(t, ok)
+	assert.Equal(t, "https://example.com/", u.String())
+	assert.Equal(t, "app:http", route.Upstream)
+
+	_, _, ok = routes.ByID("missing")
+	assert.False(t, ok)
+
+	assert.Len(t, routes.ForUpstream("api:http"), 1)
+	assert.Len(t, routes.ForUpstream("admin"), 1)
+	assert.Empty(t, routes.ForUpstream("db:mysql"))
+}
+
+func TestEnvironment_MatchRoute(t *testing.T) {
+	data, err := json.Marshal(testRoutes(t))
+	require.NoError(t, err)
+
+	env := NewEnvironmentWithProvider("PLATFORM_", MapProvider{
+		"PLATFORM_PROJECT":     "abc",
+		"PLATFORM_ENVIRONMENT": "master",
+		"PLATFORM_ROUTES":      base64.StdEncoding.EncodeToString(data),
+	})
+
+	_, repr, ok := env.MatchRoute(&url.URL{Scheme: "https", Host: "example.com", Path: "/api"})
+	require.True(t, ok)
+	assert.Equal(t, RouteRepresentation{
+		Project:     "abc",
+		Environment: "master",
+		Route:       RouteIdentification{Scheme: "https", Host: "example.com", Path: "/api"},
+	}, repr)
+}
+
+func TestRoutes_URLFor(t *testing.T) {
+	routes := testRoutes(t)
+	id := "home"
+	routes[url.URL{Scheme: "https", Host: "www.example.com", Path: "/"}] = Route{Type: "redirect", To: "https://example.com/", ID: &id}
+
+	cases := []struct {
+		target   string
+		path     string
+		query    url.Values
+		expected string
+		err      error
+	}{
+		{"main", "", nil, "https://example.com/", nil},
+		{"main", "/users/", url.Values{"page": {"2"}}, "https://example.com/users/?page=2", nil},
+		{"app:http", "a/../../b", nil, "https://example.com/b", nil},
+		{"app", "/users", nil, "https://example.com/users", nil},
+		{"api:http", "v1/users", nil, "https://example.com/api/v1/users", nil},
+		{"admin:http", "/", nil, "https://admin.example.com/", nil},
+		{"tenant:http", "/", nil, "", ErrRouteWildcard},
+		{"home", "/", nil, "", ErrRouteRedirect},
+		{"db:mysql", "/", nil, "", ErrRouteNotFound},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.target+tc.path, func(t *testing.T) {
+			u, err := routes.URLFor(tc.target, tc.path, tc.query)
+			if tc.err != nil {
+				require.Error(t, err)
+				assert.Equal(t, tc.err, errors.Cause(err))
+				assert.Contains(t, err.Error(), tc.target)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, u.String())
+		})
+	}
+}
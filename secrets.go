@@ -0,0 +1,90 @@
+package pshgo
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/hkdf"
+)
+
+// Keys are derived with HKDF-SHA256 using PROJECT_ENTROPY as the input key
+// material, no salt, and KeyDerivationVersion followed by the purpose as the
+// info parameter. For the same entropy and purpose the derived key never
+// changes between releases; a shorter key is always a prefix of a longer one.
+// Changing either the entropy or the purpose yields an unrelated key.
+const (
+	KeyDerivationVersion = "pshgo/v1/"
+	MaxDerivedKeyLength  = 255 * sha256.Size
+)
+
+var (
+	ErrNoProjectEntropy = errors.New("PROJECT_ENTROPY is missing or empty; run ./cmd/localenv to generate a local environment")
+)
+
+func DeriveKey(p PlatformProvider, purpose string, length int) ([]byte, error) {
+	logrus.WithField("purpose", purpose).Trace("DeriveKey")
+
+	if length <= 0 || length > MaxDerivedKeyLength {
+		return nil, fmt.Errorf("invalid derived key length %d", length)
+	}
+
+	entropy, ok := LookupProjectEntropy(p)
+	if !ok || entropy == "" {
+		return nil, ErrNoProjectEntropy
+	}
+
+	r := hkdf.New(sha256.New, []byte(entropy), nil, []byte(KeyDerivationVersion+purpose))
+	key := make([]byte, length)
+	if _, err := io.ReadFull(r, key); err != nil {
+		return nil, errors.Wrap(err, "error deriving key")
+	}
+
+	return key, nil
+}
+
+func DeriveKeyHex(p PlatformProvider, purpose string, length int) (string, error) {
+	key, err := DeriveKey(p, purpose, length)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(key), nil
+}
+
+func DeriveKeyBase64(p PlatformProvider, purpose string, length int) (string, error) {
+	key, err := DeriveKey(p, purpose, length)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// MustDeriveKey is like DeriveKey but panics instead of returning an error so
+// a missing PROJECT_ENTROPY aborts start up rather than yielding a weak key.
+func MustDeriveKey(p PlatformProvider, purpose string, length int) []byte {
+	key, err := DeriveKey(p, purpose, length)
+	if err != nil {
+		logrus.WithError(err).WithField("purpose", purpose).Panic("unable to derive key")
+	}
+	return key
+}
+
+func (e *Environment) DeriveKey(purpose string, length int) ([]byte, error) {
+	return DeriveKey(e, purpose, length)
+}
+
+func (e *Environment) DeriveKeyHex(purpose string, length int) (string, error) {
+	return DeriveKeyHex(e, purpose, length)
+}
+
+func (e *Environment) DeriveKeyBase64(purpose string, length int) (string, error) {
+	return DeriveKeyBase64(e, purpose, length)
+}
+
+func (e *Environment) MustDeriveKey(purpose string, length int) []byte {
+	return MustDeriveKey(e, purpose, length)
+}
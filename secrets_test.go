@@ -0,0 +1,95 @@
+package pshgo_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/demosdemon/pshgo"
+)
+
+const testEntropy = "qt4vwicvsngix2zpdekdvjl65xhl7fwiyrjfholuell6n2rfc33m7juo"
+
+func TestEnvironment_DeriveKey(t *testing.T) {
+	cases := []struct {
+		name    string
+		env     MapProvider
+		purpose string
+		length  int
+		hex     string
+		base64  string
+		err     error
+	}{
+		{
+			name:    "session",
+			env:     MapProvider{"PLATFORM_PROJECT_ENTROPY": testEntropy},
+			purpose: "session",
+			length:  32,
+			hex:     "f5ceaadd11a48f00465d636791981df7e8d048ae30eac2960a1861e79ecbbff6",
+			base64:  "9c6q3RGkjwBGXWNnkZgd9+jQSK4w6sKWChhh557Lv/Y=",
+		},
+		{
+			name:    "prefix",
+			env:     MapProvider{"PLATFORM_PROJECT_ENTROPY": testEntropy},
+			purpose: "session",
+			length:  16,
+			hex:     "f5ceaadd11a48f00465d636791981df7",
+			base64:  "9c6q3RGkjwBGXWNnkZgd9w==",
+		},
+		{
+			name:    "csrf",
+			env:     MapProvider{"PLATFORM_PROJECT_ENTROPY": testEntropy},
+			purpose: "csrf",
+			length:  24,
+			hex:     "63d74d04d04c035f3954d089d145252ab149a8beffe072ec",
+			base64:  "Y9dNBNBMA185VNCJ0UUlKrFJqL7/4HLs",
+		},
+		{
+			name:    "missing",
+			env:     MapProvider{},
+			purpose: "session",
+			length:  32,
+			err:     ErrNoProjectEntropy,
+		},
+		{
+			name:    "empty",
+			env:     MapProvider{"PLATFORM_PROJECT_ENTROPY": ""},
+			purpose: "session",
+			length:  32,
+			err:     ErrNoProjectEntropy,
+		},
+	}
+
+	t.Parallel()
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			env := NewEnvironmentWithProvider("PLATFORM_", c.env)
+
+			h, err := env.DeriveKeyHex(c.purpose, c.length)
+			assert.Equal(t, c.err, err)
+			assert.Equal(t, c.hex, h)
+
+			b, err := env.DeriveKeyBase64(c.purpose, c.length)
+			assert.Equal(t, c.err, err)
+			assert.Equal(t, c.base64, b)
+
+			if c.err != nil {
+				assert.Panics(t, func() { env.MustDeriveKey(c.purpose, c.length) })
+			}
+		})
+	}
+}
+
+func TestEnvironment_DeriveKey_Length(t *testing.T) {
+	env := NewEnvironmentWithProvider("PLATFORM_", MapProvider{"PLATFORM_PROJECT_ENTROPY": testEntropy})
+
+	for _, length := range []int{-1, 0, MaxDerivedKeyLength + 1} {
+		_, err := env.DeriveKey("session", length)
+		assert.Error(t, err)
+	}
+
+	key, err := env.DeriveKey("session", MaxDerivedKeyLength)
+	assert.NoError(t, err)
+	assert.Len(t, key, MaxDerivedKeyLength)
+}
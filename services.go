@@ -0,0 +1,306 @@
+package pshgo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// Services is the content of .platform/services.yaml keyed by service name.
+type Services map[string]Service
+
+type Service struct {
+	Type          string               `json:"type" yaml:"type"`
+	Disk          uint32               `json:"disk,omitempty" yaml:"disk,omitempty"`
+	Size          ServiceSize          `json:"size,omitempty" yaml:"size,omitempty"`
+	Configuration ServiceConfiguration `json:"configuration,omitempty" yaml:"configuration,omitempty"`
+	Relationships StringMap            `json:"relationships,omitempty" yaml:"relationships,omitempty"`
+}
+
+// ServiceConfiguration holds the settings shared by several services; the
+// settings specific to a single service are kept in Extra.
+type ServiceConfiguration struct {
+	Schemas   []string                   `json:"schemas,omitempty" yaml:"schemas,omitempty"`
+	Endpoints map[string]ServiceEndpoint `json:"endpoints,omitempty" yaml:"endpoints,omitempty"`
+	Queues    JSONObject                 `json:"queues,omitempty" yaml:"queues,omitempty"`
+	Extra     JSONObject                 `json:"-" yaml:",inline"`
+}
+
+type ServiceEndpoint struct {
+	DefaultSchema string    `json:"default_schema,omitempty" yaml:"default_schema,omitempty"`
+	Privileges    StringMap `json:"privileges,omitempty" yaml:"privileges,omitempty"`
+	Core          string    `json:"core,omitempty" yaml:"core,omitempty"`
+}
+
+// ServiceLink is a relationship of the application resolved to the service
+// endpoint it refers to.
+type ServiceLink struct {
+	Relationship string
+	Service      string
+	Endpoint     string
+	Definition   Service
+}
+
+// defaultEndpoints is the endpoint exposed by each kind of service when its
+// configuration declares none.
+var defaultEndpoints = map[string]string{
+	"chrome-headless":  "http",
+	"elasticsearch":    "elasticsearch",
+	"influxdb":         "influxdb",
+	"kafka":            "kafka",
+	"mariadb":          "mysql",
+	"memcached":        "memcached",
+	"mongodb":          "mongodb",
+	"mysql":            "mysql",
+	"network-storage":  "network-storage",
+	"oracle-mysql":     "mysql",
+	"postgresql":       "postgresql",
+	"rabbitmq":         "rabbitmq",
+	"redis":            "redis",
+	"redis-persistent": "redis",
+	"solr":             "solr",
+	"varnish":          "http",
+}
+
+// SplitServiceType splits a type such as mariadb:10.2 into its kind and
+// version.
+func SplitServiceType(s string) (kind, version string, err error) {
+	idx := strings.Index(s, ":")
+	if idx <= 0 || idx == len(s)-1 || strings.Contains(s[idx+1:], ":") {
+		return "", "", errors.Errorf("expected type:version, found %q", s)
+	}
+	return s[:idx], s[idx+1:], nil
+}
+
+// Kind returns the type of the service without its version.
+func (s Service) Kind() string {
+	kind, _, _ := SplitServiceType(s.Type)
+	return kind
+}
+
+// Version returns the version part of the type of the service.
+func (s Service) Version() string {
+	_, version, _ := SplitServiceType(s.Type)
+	return version
+}
+
+// Endpoints returns the names of the endpoints exposed by the service, or nil
+// when they are unknown.
+func (s Service) Endpoints() []string {
+	if len(s.Configuration.Endpoints) > 0 {
+		rv := make([]string, 0, len(s.Configuration.Endpoints))
+		for name := range s.Configuration.Endpoints {
+			rv = append(rv, name)
+		}
+		sort.Strings(rv)
+		return rv
+	}
+
+	if endpoint, ok := defaultEndpoints[s.Kind()]; ok {
+		return []string{endpoint}
+	}
+
+	return nil
+}
+
+// HasEndpoint reports whether the service exposes the named endpoint. It is
+// always true for services of an unknown kind.
+func (s Service) HasEndpoint(name string) bool {
+	endpoints := s.Endpoints()
+	if endpoints == nil {
+		return true
+	}
+
+	for _, endpoint := range endpoints {
+		if endpoint == name {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadServices reads and validates a services.yaml file.
+func LoadServices(path string) (Services, error) {
+	logrus.WithField("path", path).Trace("LoadServices")
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading services config")
+	}
+
+	return ParseServices(path, data)
+}
+
+// ParseServices decodes and validates the content of a services.yaml file,
+// reporting every error as a *ConfigError.
+func ParseServices(filename string, data []byte) (Services, error) {
+	logrus.WithField("filename", filename).Trace("ParseServices")
+
+	var rv Services
+	d := newYAMLDecoder(filename, data)
+	if err := d.Decode(data, &rv); err != nil {
+		if _, ok := err.(*ConfigError); ok {
+			return nil, err
+		}
+	}
+
+	rv.validate(d.fail)
+
+	if d.errs != nil {
+		return nil, sortConfigErrors(d.errs)
+	}
+
+	return rv, nil
+}
+
+// Validate checks the type, size and endpoints of every service.
+func (s Services) Validate() error {
+	var rv error
+	s.validate(func(path []string, err error) {
+		rv = multierror.Append(rv, &ConfigError{Path: formatPath(path), Err: err})
+	})
+	return rv
+}
+
+func (s Services) validate(fail func(path []string, err error)) {
+	for _, name := range s.names() {
+		svc := s[name]
+
+		kind, _, err := SplitServiceType(svc.Type)
+		switch {
+		case svc.Type == "":
+			fail([]string{name, "type"}, errors.New("type is required"))
+		case err != nil:
+			fail([]string{name, "type"}, err)
+		default:
+			if _, ok := defaultEndpoints[kind]; !ok {
+				logrus.WithField("service", name).WithField("type", svc.Type).Warn("unknown service type")
+			}
+		}
+
+		if !svc.Size.IsValid() {
+			fail([]string{name, "size"}, fmt.Errorf("unknown size %d; expected one of %v", uint8(svc.Size), ServiceSizeValues()))
+		}
+
+		schemas := make(map[string]bool, len(svc.Configuration.Schemas))
+		for _, schema := range svc.Configuration.Schemas {
+			schemas[schema] = true
+		}
+
+		for endpoint, e := range svc.Configuration.Endpoints {
+			path := []string{name, "configuration", "endpoints", endpoint}
+			if e.DefaultSchema != "" && len(schemas) > 0 && !schemas[e.DefaultSchema] {
+				fail(append(path, "default_schema"), fmt.Errorf("unknown schema %q", e.DefaultSchema))
+			}
+			for schema := range e.Privileges {
+				if len(schemas) > 0 && !schemas[schema] {
+					fail(append(path, "privileges", schema), fmt.Errorf("unknown schema %q", schema))
+				}
+			}
+		}
+	}
+}
+
+// Link resolves every relationship of the application to the service
+// endpoint it refers to. The errors of every unresolved relationship are
+// returned as *ConfigError.
+func (s Services) Link(rels StringMap) (map[string]ServiceLink, error) {
+	var errs error
+	fail := func(name string, format string, args ...interface{}) {
+		errs = multierror.Append(errs, &ConfigError{
+			Path: formatPath([]string{"relationships", name}),
+			Err:  fmt.Errorf(format, args...),
+		})
+	}
+
+	rv := make(map[string]ServiceLink, len(rels))
+	for _, name := range sortedKeys(rels) {
+		target := rels[name]
+		service, endpoint, err := SplitServiceType(target)
+		if err != nil {
+			fail(name, "expected service:endpoint, found %q", target)
+			continue
+		}
+
+		svc, ok := s[service]
+		if !ok {
+			fail(name, "unknown service %q", service)
+			continue
+		}
+
+		if !svc.HasEndpoint(endpoint) {
+			fail(name, "service %q has no endpoint %q; expected one of %v", service, endpoint, svc.Endpoints())
+			continue
+		}
+
+		rv[name] = ServiceLink{
+			Relationship: name,
+			Service:      service,
+			Endpoint:     endpoint,
+			Definition:   svc,
+		}
+	}
+
+	if errs != nil {
+		return nil, errs
+	}
+
+	return rv, nil
+}
+
+func (s Services) names() []string {
+	rv := make([]string, 0, len(s))
+	for name := range s {
+		rv = append(rv, name)
+	}
+	sort.Strings(rv)
+	return rv
+}
+
+func (c ServiceConfiguration) MarshalJSON() ([]byte, error) {
+	type plain ServiceConfiguration
+	data, err := json.Marshal(plain(c))
+	if err != nil || len(c.Extra) == 0 {
+		return data, err
+	}
+
+	var rv JSONObject
+	if err := json.Unmarshal(data, &rv); err != nil {
+		return nil, err
+	}
+	for k, v := range c.Extra {
+		if _, ok := rv[k]; !ok {
+			rv[k] = v
+		}
+	}
+
+	return json.Marshal(rv)
+}
+
+func (c *ServiceConfiguration) UnmarshalJSON(data []byte) error {
+	type plain ServiceConfiguration
+	if err := json.Unmarshal(data, (*plain)(c)); err != nil {
+		return err
+	}
+
+	var extra JSONObject
+	if err := json.Unmarshal(data, &extra); err != nil {
+		return err
+	}
+	for _, k := range []string{"schemas", "endpoints", "queues"} {
+		delete(extra, k)
+	}
+
+	c.Extra = nil
+	if len(extra) > 0 {
+		c.Extra = extra
+	}
+
+	return nil
+}
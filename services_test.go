@@ -0,0 +1,126 @@
+package pshgo_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	. "github.com/demosdemon/pshgo"
+)
+
+const servicesYAML = `db:
+  type: mariadb:10.2
+  disk: 2048
+  size: S
+  configuration:
+    schemas:
+      - main
+      - legacy
+    endpoints:
+      admin:
+        default_schema: main
+        privileges:
+          main: admin
+          legacy: ro
+search:
+  type: solr:7.7
+  disk: 512
+  configuration:
+    cores:
+      main:
+        conf_dir: !archive "core"
+    endpoints:
+      solr:
+        core: main
+cache:
+  type: redis:5.0
+`
+
+func TestParseServices(t *testing.T) {
+	services, err := ParseServices("services.yaml", []byte(servicesYAML))
+	require.NoError(t, err)
+	require.Len(t, services, 3)
+
+	db := services["db"]
+	assert.Equal(t, "mariadb", db.Kind())
+	assert.Equal(t, "10.2", db.Version())
+	assert.Equal(t, ServiceSizeSmall, db.Size)
+	assert.Equal(t, []string{"main", "legacy"}, db.Configuration.Schemas)
+	assert.Equal(t, ServiceEndpoint{DefaultSchema: "main", Privileges: StringMap{"main": "admin", "legacy": "ro"}}, db.Configuration.Endpoints["admin"])
+	assert.Equal(t, []string{"admin"}, db.Endpoints())
+
+	search := services["search"]
+	assert.Equal(t, JSONObject{"cores": JSONObject{"main": JSONObject{"conf_dir": "core"}}}, search.Configuration.Extra)
+	assert.Equal(t, []string{"redis"}, services["cache"].Endpoints())
+
+	data, err := json.Marshal(search)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"type":"solr:7.7","disk":512,"configuration":{"cores":{"main":{"conf_dir":"core"}},"endpoints":{"solr":{"core":"main"}}}}`, string(data))
+
+	var decoded Service
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, search, decoded)
+}
+
+func TestParseServices_Errors(t *testing.T) {
+	StrictConfig = true
+	defer func() { StrictConfig = false }()
+
+	const data = `db:
+  type: mariadb
+  size: huge
+  configuration:
+    schemas: [main]
+    endpoints:
+      admin:
+        default_schema: other
+cache:
+  disk: 128
+  tpye: redis:5.0
+`
+
+	_, err := ParseServices("services.yaml", []byte(data))
+	require.Error(t, err)
+	require.IsType(t, &multierror.Error{}, err)
+
+	var errs []string
+	for _, err := range err.(*multierror.Error).Errors {
+		errs = append(errs, err.Error())
+	}
+
+	assert.Equal(t, []string{
+		`services.yaml:2:3: db.type: expected type:version, found "mariadb"`,
+		`services.yaml:3:3: db.size: unknown ServiceSize name "huge"`,
+		`services.yaml:8:9: db.configuration.endpoints.admin.default_schema: unknown schema "other"`,
+		`services.yaml:9:1: cache.type: type is required`,
+		`services.yaml:11:3: cache.tpye: unknown field "tpye"`,
+	}, errs)
+}
+
+func TestServices_Link(t *testing.T) {
+	services, err := ParseServices("services.yaml", []byte(servicesYAML))
+	require.NoError(t, err)
+
+	links, err := services.Link(StringMap{
+		"database": "db:admin",
+		"solr":     "search:solr",
+		"redis":    "cache:redis",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, ServiceLink{Relationship: "database", Service: "db", Endpoint: "admin", Definition: services["db"]}, links["database"])
+	assert.Equal(t, "search", links["solr"].Service)
+
+	_, err = services.Link(StringMap{
+		"database": "db:mysql",
+		"queue":    "rabbit:rabbitmq",
+		"broken":   "cache",
+	})
+	require.Error(t, err)
+	assert.Equal(t, 3, len(err.(*multierror.Error).Errors))
+	assert.Contains(t, err.Error(), `relationships.broken: expected service:endpoint, found "cache"`)
+	assert.Contains(t, err.Error(), `relationships.database: service "db" has no endpoint "mysql"; expected one of [admin]`)
+	assert.Contains(t, err.Error(), `relationships.queue: unknown service "rabbit"`)
+}
@@ -0,0 +1,166 @@
+package pshgo
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// ClientAuthentication modes of TLSSettings. The router verifies the client
+// certificates it receives against the certificate authorities of the route;
+// request lets clients connect without one, require does not.
+const (
+	ClientAuthNone    = ""
+	ClientAuthRequest = "request"
+	ClientAuthRequire = "require"
+)
+
+// HSTSMaxAge is the max-age, in seconds, of the Strict-Transport-Security
+// header sent by the router.
+const HSTSMaxAge = 31536000
+
+var clientAuthMapping = map[string]tls.ClientAuthType{
+	ClientAuthNone:    tls.NoClientCert,
+	ClientAuthRequest: tls.VerifyClientCertIfGiven,
+	ClientAuthRequire: tls.RequireAndVerifyClientCert,
+}
+
+var tlsCryptoMapping = map[TLSVersion]uint16{
+	TLSv10: tls.VersionTLS10,
+	TLSv11: tls.VersionTLS11,
+	TLSv12: tls.VersionTLS12,
+	TLSv13: tls.VersionTLS13,
+}
+
+// CryptoVersion returns the crypto/tls constant of the version.
+func (v TLSVersion) CryptoVersion() (uint16, error) {
+	logrus.Trace("TLSVersion.CryptoVersion")
+	if rv, ok := tlsCryptoMapping[v]; ok {
+		return rv, nil
+	}
+
+	return 0, errors.Errorf("%s is not supported", v)
+}
+
+// ClientAuth returns the crypto/tls client authentication mode of the
+// settings.
+func (s TLSSettings) ClientAuth() (tls.ClientAuthType, error) {
+	logrus.Trace("TLSSettings.ClientAuth")
+	if rv, ok := clientAuthMapping[s.ClientAuthentication]; ok {
+		return rv, nil
+	}
+
+	return tls.NoClientCert, errors.Errorf("unknown client authentication %q", s.ClientAuthentication)
+}
+
+// Config returns the configuration of a TLS server applying the settings.
+// The certificates of the server are left for the caller to set.
+func (s TLSSettings) Config() (*tls.Config, error) {
+	logrus.Trace("TLSSettings.Config")
+
+	var cfg tls.Config
+
+	if s.MinVersion != nil {
+		version, err := s.MinVersion.CryptoVersion()
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid min_version")
+		}
+		cfg.MinVersion = version
+	}
+
+	auth, err := s.ClientAuth()
+	if err != nil {
+		return nil, err
+	}
+	cfg.ClientAuth = auth
+
+	if len(s.ClientCertificateAuthorities) > 0 {
+		cfg.ClientCAs = x509.NewCertPool()
+		for idx, ca := range s.ClientCertificateAuthorities {
+			if ca.Certificate == nil {
+				return nil, errors.Errorf("client certificate authority %d is empty", idx)
+			}
+			cfg.ClientCAs.AddCert(ca.Certificate)
+		}
+	}
+
+	return &cfg, nil
+}
+
+// Header returns the value of the Strict-Transport-Security header, or an
+// empty string when it is disabled.
+func (s TLSSTS) Header() string {
+	if !s.Enabled {
+		return ""
+	}
+
+	parts := []string{"max-age=" + strconv.Itoa(HSTSMaxAge)}
+	if s.IncludeSubdomains {
+		parts = append(parts, "includeSubDomains")
+	}
+	if s.Preload {
+		parts = append(parts, "preload")
+	}
+	return strings.Join(parts, "; ")
+}
+
+// TLSConfig returns the configuration of a TLS server for the routes,
+// serving certificates. Each connection gets the TLS settings of the https
+// route of the server name requested by the client, or those of the primary
+// route when no route has that host.
+func (r Routes) TLSConfig(certificates []tls.Certificate) (*tls.Config, error) {
+	logrus.Trace("Routes.TLSConfig")
+
+	var (
+		byHost   = make(map[string]*tls.Config)
+		fallback = new(tls.Config)
+	)
+
+	for u, route := range r {
+		if u.Scheme != "https" || route.IsRedirect() {
+			continue
+		}
+
+		cfg, err := route.TLS.Config()
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid tls settings for route %s", route.OriginalURL)
+		}
+		cfg.Certificates = certificates
+
+		host := strings.ToLower(u.Hostname())
+		if existing, ok := byHost[host]; !ok || route.Primary {
+			byHost[host] = cfg
+		} else if existing.ClientAuth != cfg.ClientAuth || existing.MinVersion != cfg.MinVersion {
+			logrus.WithField("host", host).Warn("routes of the same host have different tls settings")
+		}
+
+		if route.Primary {
+			fallback = cfg
+		}
+	}
+	fallback.Certificates = certificates
+
+	return &tls.Config{
+		Certificates: certificates,
+		GetConfigForClient: func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+			if cfg, ok := byHost[strings.ToLower(hello.ServerName)]; ok {
+				return cfg, nil
+			}
+
+			// wildcard routes
+			u := url.URL{Scheme: "https", Host: hello.ServerName, Path: "/"}
+			if _, repr, ok := r.Match(&u); ok && repr.Route.Scheme == "https" {
+				if cfg, ok := byHost[strings.ToLower(repr.Route.Host)]; ok {
+					return cfg, nil
+				}
+			}
+
+			return fallback, nil
+		},
+	}, nil
+}
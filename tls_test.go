@@ -0,0 +1,128 @@
+package pshgo_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	. "github.com/demosdemon/pshgo"
+)
+
+func TestTLSVersion_CryptoVersion(t *testing.T) {
+	cases := map[TLSVersion]uint16{
+		TLSv10: tls.VersionTLS10,
+		TLSv11: tls.VersionTLS11,
+		TLSv12: tls.VersionTLS12,
+		TLSv13: tls.VersionTLS13,
+	}
+
+	for v, expected := range cases {
+		actual, err := v.CryptoVersion()
+		assert.NoError(t, err, v.String())
+		assert.Equal(t, expected, actual, v.String())
+	}
+
+	_, err := TLSv14.CryptoVersion()
+	assert.EqualError(t, err, "TLSv1.4 is not supported")
+}
+
+func TestTLSSettings_Config(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		cfg, err := TLSSettings{}.Config()
+		require.NoError(t, err)
+		assert.Equal(t, uint16(0), cfg.MinVersion)
+		assert.Equal(t, tls.NoClientCert, cfg.ClientAuth)
+		assert.Nil(t, cfg.ClientCAs)
+	})
+
+	t.Run("client authentication", func(t *testing.T) {
+		v := TLSv12
+		cfg, err := TLSSettings{
+			MinVersion:                   &v,
+			ClientAuthentication:         ClientAuthRequire,
+			ClientCertificateAuthorities: []Certificate{{Certificate: rootCertificate}, {Certificate: intermediateCertificate}},
+		}.Config()
+		require.NoError(t, err)
+		assert.Equal(t, uint16(tls.VersionTLS12), cfg.MinVersion)
+		assert.Equal(t, tls.RequireAndVerifyClientCert, cfg.ClientAuth)
+		require.NotNil(t, cfg.ClientCAs)
+
+		chains, err := xClientCert.Verify(x509.VerifyOptions{
+			Roots:       cfg.ClientCAs,
+			CurrentTime: time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC),
+			KeyUsages:   []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		})
+		assert.NoError(t, err)
+		assert.NotEmpty(t, chains)
+	})
+
+	t.Run("request", func(t *testing.T) {
+		cfg, err := TLSSettings{ClientAuthentication: ClientAuthRequest}.Config()
+		require.NoError(t, err)
+		assert.Equal(t, tls.VerifyClientCertIfGiven, cfg.ClientAuth)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		v := TLSv14
+		_, err := TLSSettings{MinVersion: &v}.Config()
+		assert.EqualError(t, err, "invalid min_version: TLSv1.4 is not supported")
+
+		_, err = TLSSettings{ClientAuthentication: "require_and_verify"}.Config()
+		assert.EqualError(t, err, `unknown client authentication "require_and_verify"`)
+
+		_, err = TLSSettings{ClientAuthentication: "sometimes"}.Config()
+		assert.EqualError(t, err, `unknown client authentication "sometimes"`)
+
+		_, err = TLSSettings{ClientCertificateAuthorities: []Certificate{{}}}.Config()
+		assert.EqualError(t, err, "client certificate authority 0 is empty")
+	})
+}
+
+func TestTLSSTS_Header(t *testing.T) {
+	assert.Equal(t, "", TLSSTS{IncludeSubdomains: true}.Header())
+	assert.Equal(t, "max-age=31536000", TLSSTS{Enabled: true}.Header())
+	assert.Equal(t, "max-age=31536000; includeSubDomains; preload", TLSSTS{Enabled: true, IncludeSubdomains: true, Preload: true}.Header())
+}
+
+func TestRoutes_TLSConfig(t *testing.T) {
+	const data = `"https://{default}/":
+  type: upstream
+  upstream: app:http
+  tls:
+    min_version: TLSv1.2
+"https://secure.{default}/":
+  type: upstream
+  upstream: app:http
+  tls:
+    client_authentication: require
+"https://*.tenant.{default}/":
+  type: upstream
+  upstream: app:http
+  tls:
+    min_version: TLSv1.3
+`
+
+	routes, err := ParseRoutes("routes.yaml", []byte(data), RouteExpansion{Domains: []string{"example.com"}})
+	require.NoError(t, err)
+
+	cert := tls.Certificate{Certificate: [][]byte{rootCertificate.Raw}}
+	cfg, err := routes.TLSConfig([]tls.Certificate{cert})
+	require.NoError(t, err)
+	require.NotNil(t, cfg.GetConfigForClient)
+
+	config := func(name string) *tls.Config {
+		c, err := cfg.GetConfigForClient(&tls.ClientHelloInfo{ServerName: name})
+		require.NoError(t, err)
+		require.Len(t, c.Certificates, 1)
+		return c
+	}
+
+	assert.Equal(t, uint16(tls.VersionTLS12), config("example.com").MinVersion)
+	assert.Equal(t, tls.RequireAndVerifyClientCert, config("SECURE.example.com").ClientAuth)
+	assert.Equal(t, uint16(tls.VersionTLS13), config("a.tenant.example.com").MinVersion)
+	assert.Equal(t, uint16(tls.VersionTLS12), config("unknown.org").MinVersion)
+}
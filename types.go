@@ -21,203 +21,203 @@ type (
 
 type (
 	Application struct {
-		ApplicationCore
-		Web     Web     `json:"web"`
-		Hooks   Hooks   `json:"hooks"`
-		Crons   Crons   `json:"crons"`
-		Workers Workers `json:"workers"`
-		TreeID  string  `json:"tree_id"`
-		SlugID  string  `json:"slug_id"`
-		AppDir  string  `json:"app_dir"`
+		ApplicationCore `yaml:",inline"`
+		Web             Web     `json:"web" yaml:"web"`
+		Hooks           Hooks   `json:"hooks" yaml:"hooks"`
+		Crons           Crons   `json:"crons" yaml:"crons"`
+		Workers         Workers `json:"workers" yaml:"workers"`
+		TreeID          string  `json:"tree_id" yaml:"tree_id"`
+		SlugID          string  `json:"slug_id" yaml:"slug_id"`
+		AppDir          string  `json:"app_dir" yaml:"app_dir"`
 	}
 
 	ApplicationBase struct {
-		Size          ServiceSize `json:"size"`
-		Disk          uint32      `json:"disk"`
-		Access        Access      `json:"access"`
-		Relationships StringMap   `json:"relationships"`
-		Mounts        Mounts      `json:"mounts"`
-		Timezone      string      `json:"timezone"` // TODO: replace with serializable time.Location
-		Variables     Variables   `json:"variables"`
+		Size          ServiceSize `json:"size" yaml:"size"`
+		Disk          uint32      `json:"disk" yaml:"disk"`
+		Access        Access      `json:"access" yaml:"access"`
+		Relationships StringMap   `json:"relationships" yaml:"relationships"`
+		Mounts        Mounts      `json:"mounts" yaml:"mounts"`
+		Timezone      string      `json:"timezone" yaml:"timezone"` // TODO: replace with serializable time.Location
+		Variables     Variables   `json:"variables" yaml:"variables"`
 	}
 
 	ApplicationBuilder struct {
-		ApplicationCore
-		Dependencies JSONObject `json:"dependencies"`
-		Build        Build      `json:"build"`
-		Source       Source     `json:"source"`
+		ApplicationCore `yaml:",inline"`
+		Dependencies    JSONObject `json:"dependencies" yaml:"dependencies"`
+		Build           Build      `json:"build" yaml:"build"`
+		Source          Source     `json:"source" yaml:"source"`
 	}
 
 	ApplicationCore struct {
-		ApplicationBase
-		Name      string      `json:"name"`
-		Type      string      `json:"type"`
-		Runtime   interface{} `json:"runtime"`
-		Preflight Preflight   `json:"preflight"`
+		ApplicationBase `yaml:",inline"`
+		Name            string      `json:"name" yaml:"name"`
+		Type            string      `json:"type" yaml:"type"`
+		Runtime         interface{} `json:"runtime" yaml:"runtime"`
+		Preflight       Preflight   `json:"preflight" yaml:"preflight"`
 	}
 
 	Build struct {
-		Flavor string `json:"flavor"`
-		Caches Caches `json:"caches"`
+		Flavor string `json:"flavor" yaml:"flavor"`
+		Caches Caches `json:"caches" yaml:"caches"`
 	}
 
 	Cache struct {
-		Enabled    bool     `json:"enabled"`
-		DefaultTTL int      `json:"default_ttl"`
-		Cookies    []string `json:"cookies"`
-		Headers    []string `json:"headers"`
+		Enabled    bool     `json:"enabled" yaml:"enabled"`
+		DefaultTTL int      `json:"default_ttl" yaml:"default_ttl"`
+		Cookies    []string `json:"cookies" yaml:"cookies"`
+		Headers    []string `json:"headers" yaml:"headers"`
 	}
 
 	CacheConfiguration struct {
-		Directory        string   `json:"directory"`
-		Watch            []string `json:"watch"`
-		AllowStale       bool     `json:"allow_stale"`
-		ShareBetweenApps bool     `json:"share_between_apps"`
+		Directory        string   `json:"directory" yaml:"directory"`
+		Watch            []string `json:"watch" yaml:"watch"`
+		AllowStale       bool     `json:"allow_stale" yaml:"allow_stale"`
+		ShareBetweenApps bool     `json:"share_between_apps" yaml:"share_between_apps"`
 	}
 
 	Commands struct {
-		Start string `json:"start"`
-		Stop  string `json:"stop,omitempty"`
+		Start string `json:"start" yaml:"start"`
+		Stop  string `json:"stop,omitempty" yaml:"stop,omitempty"`
 	}
 
 	Cron struct {
-		Spec string `json:"spec"`
-		Cmd  string `json:"cmd"`
+		Spec string `json:"spec" yaml:"spec"`
+		Cmd  string `json:"cmd" yaml:"cmd"`
 	}
 
 	HTTPAccess struct {
-		Addresses []string          `json:"addresses"`
-		BasicAuth map[string]string `json:"basic_auth"`
+		Addresses []string          `json:"addresses" yaml:"addresses"`
+		BasicAuth map[string]string `json:"basic_auth" yaml:"basic_auth"`
 	}
 
 	Hooks struct {
-		Build      string `json:"build"`
-		Deploy     string `json:"deploy"`
-		PostDeploy string `json:"post_deploy"`
+		Build      string `json:"build" yaml:"build"`
+		Deploy     string `json:"deploy" yaml:"deploy"`
+		PostDeploy string `json:"post_deploy" yaml:"post_deploy"`
 	}
 
 	Mount struct {
-		Source     ApplicationMount `json:"source"`
-		SourcePath string           `json:"path"`
-		Service    string           `json:"service,omitempty"`
+		Source     ApplicationMount `json:"source" yaml:"source"`
+		SourcePath string           `json:"path" yaml:"source_path"`
+		Service    string           `json:"service,omitempty" yaml:"service,omitempty"`
 	}
 
 	Preflight struct {
-		Enabled      bool     `json:"enabled"`
-		IgnoredRules []string `json:"ignored_rules"`
+		Enabled      bool     `json:"enabled" yaml:"enabled"`
+		IgnoredRules []string `json:"ignored_rules" yaml:"ignored_rules"`
 	}
 
 	Redirects struct {
-		Expires Duration      `json:"expires"`
-		Paths   RedirectPaths `json:"paths"`
+		Expires Duration      `json:"expires" yaml:"expires"`
+		Paths   RedirectPaths `json:"paths" yaml:"paths"`
 	}
 
 	RedirectPath struct {
-		Regexp       bool     `json:"regexp"`
-		To           string   `json:"to"`
-		Prefix       bool     `json:"prefix"`
-		AppendSuffix bool     `json:"append_suffix"`
-		Code         int      `json:"code"`
-		Expires      Duration `json:"expires"`
+		Regexp       bool     `json:"regexp" yaml:"regexp"`
+		To           string   `json:"to" yaml:"to"`
+		Prefix       bool     `json:"prefix" yaml:"prefix"`
+		AppendSuffix bool     `json:"append_suffix" yaml:"append_suffix"`
+		Code         int      `json:"code" yaml:"code"`
+		Expires      Duration `json:"expires" yaml:"expires"`
 	}
 
 	Route struct {
-		Primary        bool              `json:"primary"`
-		ID             *string           `json:"id"`
-		OriginalURL    string            `json:"original_url"`
-		Attributes     map[string]string `json:"attributes"`
-		Type           string            `json:"type"`
-		Redirects      Redirects         `json:"redirects"`
-		TLS            TLSSettings       `json:"tls"`
-		HTTPAccess     HTTPAccess        `json:"http_access"`
-		RestrictRobots bool              `json:"restrict_robots"`
+		Primary        bool              `json:"primary" yaml:"primary"`
+		ID             *string           `json:"id" yaml:"id"`
+		OriginalURL    string            `json:"original_url" yaml:"original_url"`
+		Attributes     map[string]string `json:"attributes" yaml:"attributes"`
+		Type           string            `json:"type" yaml:"type"`
+		Redirects      Redirects         `json:"redirects" yaml:"redirects"`
+		TLS            TLSSettings       `json:"tls" yaml:"tls"`
+		HTTPAccess     HTTPAccess        `json:"http_access" yaml:"http_access"`
+		RestrictRobots bool              `json:"restrict_robots" yaml:"restrict_robots"`
 
 		// Upstream Routes
-		Cache    Cache  `json:"cache"`
-		SSI      SSI    `json:"ssi"`
-		Upstream string `json:"upstream"`
+		Cache    Cache  `json:"cache" yaml:"cache"`
+		SSI      SSI    `json:"ssi" yaml:"ssi"`
+		Upstream string `json:"upstream" yaml:"upstream"`
 
 		// Redirect Routes
-		To string `json:"to"`
+		To string `json:"to" yaml:"to"`
 	}
 
 	RouteIdentification struct {
-		Scheme string `json:"scheme"`
-		Host   string `json:"host"`
-		Path   string `json:"path"`
+		Scheme string `json:"scheme" yaml:"scheme"`
+		Host   string `json:"host" yaml:"host"`
+		Path   string `json:"path" yaml:"path"`
 	}
 
 	RouteRepresentation struct {
-		Project     string              `json:"project"`
-		Environment string              `json:"environment"`
-		Route       RouteIdentification `json:"route"`
+		Project     string              `json:"project" yaml:"project"`
+		Environment string              `json:"environment" yaml:"environment"`
+		Route       RouteIdentification `json:"route" yaml:"route"`
 	}
 
 	SSI struct {
-		Enabled bool `json:"enabled"`
+		Enabled bool `json:"enabled" yaml:"enabled"`
 	}
 
 	Source struct {
-		Operations SourceOperations `json:"operations"`
+		Operations SourceOperations `json:"operations" yaml:"operations"`
 	}
 
 	SourceOperation struct {
-		Command string `json:"command"`
+		Command string `json:"command" yaml:"command"`
 	}
 
 	TLSSettings struct {
-		StrictTransportSecurity      TLSSTS        `json:"strict_transport_security"`
-		MinVersion                   *TLSVersion   `json:"min_version"`
-		ClientAuthentication         string        `json:"client_authentication"`
-		ClientCertificateAuthorities []Certificate `json:"client_certificate_authorities"`
+		StrictTransportSecurity      TLSSTS        `json:"strict_transport_security" yaml:"strict_transport_security"`
+		MinVersion                   *TLSVersion   `json:"min_version" yaml:"min_version"`
+		ClientAuthentication         string        `json:"client_authentication" yaml:"client_authentication"`
+		ClientCertificateAuthorities []Certificate `json:"client_certificate_authorities" yaml:"client_certificate_authorities"`
 	}
 
 	TLSSTS struct {
-		Enabled           bool `json:"enabled"`
-		IncludeSubdomains bool `json:"include_subdomains"`
-		Preload           bool `json:"preload"`
+		Enabled           bool `json:"enabled" yaml:"enabled"`
+		IncludeSubdomains bool `json:"include_subdomains" yaml:"include_subdomains"`
+		Preload           bool `json:"preload" yaml:"preload"`
 	}
 
 	Upstream struct {
-		SocketFamily SocketFamily   `json:"socket_family"`
-		Protocol     SocketProtocol `json:"socket_protocol"`
+		SocketFamily SocketFamily   `json:"socket_family" yaml:"socket_family"`
+		Protocol     SocketProtocol `json:"socket_protocol" yaml:"protocol"`
 	}
 
 	Web struct {
 		// ApplicationBase
-		Locations    WebLocations `json:"locations"`
-		Commands     Commands     `json:"commands"`
-		Upstream     Upstream     `json:"upstream"`
-		DocumentRoot *string      `json:"document_root,omitempty"` // deprecated
-		Passthru     *string      `json:"passthru,omitempty"`      // deprecated
-		IndexFiles   []string     `json:"index_files,omitempty"`   // deprecated
-		Whitelist    []string     `json:"whitelist,omitempty"`     // deprecated
-		Blacklist    []string     `json:"blacklist,omitempty"`     // deprecated
-		Expires      *Duration    `json:"expires,omitempty"`       // deprecated
-		MoveToRoot   *bool        `json:"move_to_root,omitempty"`  // deprecated
+		Locations    WebLocations `json:"locations" yaml:"locations"`
+		Commands     Commands     `json:"commands" yaml:"commands"`
+		Upstream     Upstream     `json:"upstream" yaml:"upstream"`
+		DocumentRoot *string      `json:"document_root,omitempty" yaml:"document_root,omitempty"` // deprecated
+		Passthru     *string      `json:"passthru,omitempty" yaml:"passthru,omitempty"`           // deprecated
+		IndexFiles   []string     `json:"index_files,omitempty" yaml:"index_files,omitempty"`     // deprecated
+		Whitelist    []string     `json:"whitelist,omitempty" yaml:"whitelist,omitempty"`         // deprecated
+		Blacklist    []string     `json:"blacklist,omitempty" yaml:"blacklist,omitempty"`         // deprecated
+		Expires      *Duration    `json:"expires,omitempty" yaml:"expires,omitempty"`             // deprecated
+		MoveToRoot   *bool        `json:"move_to_root,omitempty" yaml:"move_to_root,omitempty"`   // deprecated
 	}
 
 	WebLocation struct {
-		Root     string    `json:"root"`
-		Expires  Duration  `json:"expires"`
-		Passthru Passthru  `json:"passthru"`
-		Scripts  bool      `json:"scripts"`
-		Index    []string  `json:"index"`
-		Allow    bool      `json:"allow"`
-		Headers  StringMap `json:"headers"`
-		Rules    WebRules  `json:"rules"`
+		Root     string    `json:"root" yaml:"root"`
+		Expires  Duration  `json:"expires" yaml:"expires"`
+		Passthru Passthru  `json:"passthru" yaml:"passthru"`
+		Scripts  bool      `json:"scripts" yaml:"scripts"`
+		Index    []string  `json:"index" yaml:"index"`
+		Allow    bool      `json:"allow" yaml:"allow"`
+		Headers  StringMap `json:"headers" yaml:"headers"`
+		Rules    WebRules  `json:"rules" yaml:"rules"`
 	}
 
 	WebRule struct {
-		Expires  Duration  `json:"expires"`
-		Passthru Passthru  `json:"passthru"`
-		Scripts  bool      `json:"scripts"`
-		Allow    bool      `json:"allow"`
-		Headers  StringMap `json:"headers"`
+		Expires  Duration  `json:"expires" yaml:"expires"`
+		Passthru Passthru  `json:"passthru" yaml:"passthru"`
+		Scripts  bool      `json:"scripts" yaml:"scripts"`
+		Allow    bool      `json:"allow" yaml:"allow"`
+		Headers  StringMap `json:"headers" yaml:"headers"`
 	}
 
 	Worker struct {
 		// ApplicationBase
-		Commands Commands `json:"commands"`
+		Commands Commands `json:"commands" yaml:"commands"`
 	}
 )
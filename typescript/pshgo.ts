@@ -0,0 +1,535 @@
+// Code generated by pshgo-gen. DO NOT EDIT.
+
+/* eslint-disable */
+
+export type Env = { [name: string]: string | undefined };
+
+export const DEFAULT_PREFIX = "PLATFORM_";
+
+export function decodeBase64(value: string): Uint8Array | undefined {
+  try {
+    const binary = atob(value);
+    const bytes = new Uint8Array(binary.length);
+    for (let i = 0; i < binary.length; i++) {
+      bytes[i] = binary.charCodeAt(i);
+    }
+    return bytes;
+  } catch {
+    return undefined;
+  }
+}
+
+export function decodeJSON<T>(value: string): T | undefined {
+  const bytes = decodeBase64(value);
+  if (bytes === undefined) {
+    return undefined;
+  }
+  try {
+    return JSON.parse(new TextDecoder().decode(bytes)) as T;
+  } catch {
+    return undefined;
+  }
+}
+
+export function parseInteger(value: string): number | undefined {
+  const trimmed = value.trim();
+  return /^[+-]?[0-9]+$/.test(trimmed) ? Number(trimmed) : undefined;
+}
+
+export function parseBool(value: string): boolean | undefined {
+  switch (value.trim()) {
+    case "1": case "t": case "T": case "true": case "TRUE": case "True":
+      return true;
+    case "0": case "f": case "F": case "false": case "FALSE": case "False":
+      return false;
+  }
+  return undefined;
+}
+
+export function parseList(value: string): string[] {
+  return value.split(",").map((item) => item.trim()).filter((item) => item !== "");
+}
+
+const durationUnits: { [unit: string]: number } = {
+  ns: 1e-6, us: 1e-3, "µs": 1e-3, "μs": 1e-3, ms: 1, s: 1e3, m: 60e3, h: 3600e3,
+};
+
+// parseDuration parses a Go duration string into milliseconds.
+export function parseDuration(value: string): number | undefined {
+  const trimmed = value.trim();
+  const match = /^([+-]?)((?:[0-9]*\.?[0-9]+[a-zµμ]+)+|0)$/.exec(trimmed);
+  if (match === null) {
+    return undefined;
+  }
+  let total = 0;
+  const re = /([0-9]*\.?[0-9]+)([a-zµμ]+)/g;
+  for (let part = re.exec(match[2]); part !== null; part = re.exec(match[2])) {
+    const unit = durationUnits[part[2]];
+    if (unit === undefined) {
+      return undefined;
+    }
+    total += Number(part[1]) * unit;
+  }
+  return match[1] === "-" ? -total : total;
+}
+
+export function lookupVariable(env: Env, key: string, prefix: string = DEFAULT_PREFIX): string | undefined {
+  const value = env[prefix + "VARIABLES"];
+  const vars = value === undefined ? undefined : decodeJSON<{ [key: string]: unknown }>(value);
+  if (vars === undefined || vars === null || !(key in vars)) {
+    return undefined;
+  }
+  const v = vars[key];
+  return typeof v === "string" ? v : JSON.stringify(v);
+}
+
+export type Access = { [key: string]: AccessLevel };
+
+export type AccessLevel = "viewer" | "contributor" | "admin";
+
+export const AccessLevelValues: readonly AccessLevel[] = ["viewer", "contributor", "admin"];
+
+const AccessLevelNames: ReadonlyMap<string, AccessLevel> = new Map<string, AccessLevel>([
+  ["viewer", "viewer"],
+  ["contributor", "contributor"],
+  ["admin", "admin"],
+]);
+
+export function parseAccessLevel(value: string): AccessLevel | undefined {
+  return AccessLevelNames.get(value.toLowerCase());
+}
+
+export type AccessType = "ssh";
+
+export const AccessTypeValues: readonly AccessType[] = ["ssh"];
+
+const AccessTypeNames: ReadonlyMap<string, AccessType> = new Map<string, AccessType>([
+  ["ssh", "ssh"],
+]);
+
+export function parseAccessType(value: string): AccessType | undefined {
+  return AccessTypeNames.get(value.toLowerCase());
+}
+
+export interface Application {
+  size: ServiceSize;
+  disk: number;
+  access: Access;
+  relationships: StringMap;
+  mounts: Mounts;
+  timezone: string;
+  variables: Variables;
+  name: string;
+  type: string;
+  runtime: unknown;
+  preflight: Preflight;
+  web: Web;
+  hooks: Hooks;
+  crons: Crons;
+  workers: Workers;
+  tree_id: string;
+  slug_id: string;
+  app_dir: string;
+}
+
+export type ApplicationMount = "local" | "tmp" | "service";
+
+export const ApplicationMountValues: readonly ApplicationMount[] = ["local", "tmp", "service"];
+
+const ApplicationMountNames: ReadonlyMap<string, ApplicationMount> = new Map<string, ApplicationMount>([
+  ["local", "local"],
+  ["tmp", "tmp"],
+  ["service", "service"],
+]);
+
+export function parseApplicationMount(value: string): ApplicationMount | undefined {
+  return ApplicationMountNames.get(value.toLowerCase());
+}
+
+export interface Cache {
+  enabled: boolean;
+  default_ttl: number;
+  cookies: string[];
+  headers: string[];
+}
+
+export type Certificate = string;
+
+export type ClientVerify = "SUCCESS" | "FAILED" | "NONE";
+
+export const ClientVerifyValues: readonly ClientVerify[] = ["SUCCESS", "FAILED", "NONE"];
+
+const ClientVerifyNames: ReadonlyMap<string, ClientVerify> = new Map<string, ClientVerify>([
+  ["success", "SUCCESS"],
+  ["failed", "FAILED"],
+  ["none", "NONE"],
+]);
+
+export function parseClientVerify(value: string): ClientVerify | undefined {
+  return ClientVerifyNames.get(value.toLowerCase());
+}
+
+export interface Commands {
+  start: string;
+  stop?: string;
+}
+
+export interface Cron {
+  spec: string;
+  cmd: string;
+}
+
+export type Crons = { [key: string]: Cron };
+
+export type Duration = string;
+
+export type EnvironmentType = "development" | "staging" | "production";
+
+export const EnvironmentTypeValues: readonly EnvironmentType[] = ["development", "staging", "production"];
+
+const EnvironmentTypeNames: ReadonlyMap<string, EnvironmentType> = new Map<string, EnvironmentType>([
+  ["development", "development"],
+  ["staging", "staging"],
+  ["production", "production"],
+]);
+
+export function parseEnvironmentType(value: string): EnvironmentType | undefined {
+  return EnvironmentTypeNames.get(value.toLowerCase());
+}
+
+export interface HTTPAccess {
+  addresses: string[];
+  basic_auth: { [key: string]: string };
+}
+
+export interface Hooks {
+  build: string;
+  deploy: string;
+  post_deploy: string;
+}
+
+export type JSONObject = { [key: string]: unknown };
+
+export interface Mount {
+  source: ApplicationMount;
+  path: string;
+  service?: string;
+}
+
+export type Mounts = { [key: string]: Mount };
+
+/** Either false, true to pass every request to the application, or the path of the script handling them. */
+export type Passthru = boolean | string;
+
+export interface Preflight {
+  enabled: boolean;
+  ignored_rules: string[];
+}
+
+export interface RedirectPath {
+  regexp: boolean;
+  to: string;
+  prefix: boolean;
+  append_suffix: boolean;
+  code: number;
+  expires: Duration;
+}
+
+export type RedirectPaths = { [key: string]: RedirectPath };
+
+export interface Redirects {
+  expires: Duration;
+  paths: RedirectPaths;
+}
+
+export interface Relationship {
+  cluster: string;
+  fragment: string;
+  host: string;
+  hostname: string;
+  ip: string;
+  password: string;
+  path: string;
+  port: number;
+  public: boolean;
+  query: JSONObject;
+  rel: string;
+  scheme: string;
+  service: string;
+  ssl: JSONObject;
+  type: string;
+  username: string;
+}
+
+export type Relationships = { [key: string]: Relationship[] };
+
+export interface Route {
+  primary: boolean;
+  id: string | null;
+  original_url: string;
+  attributes: { [key: string]: string };
+  type: string;
+  redirects: Redirects;
+  tls: TLSSettings;
+  http_access: HTTPAccess;
+  restrict_robots: boolean;
+  cache: Cache;
+  ssi: SSI;
+  upstream: string;
+  /** Redirect Routes */
+  to: string;
+}
+
+/** The routes of the environment keyed by their URL. */
+export type Routes = { [key: string]: Route };
+
+export interface SSI {
+  enabled: boolean;
+}
+
+export type ServiceSize = "AUTO" | "S" | "M" | "L" | "XL" | "2XL" | "4XL";
+
+export const ServiceSizeValues: readonly ServiceSize[] = ["AUTO", "S", "M", "L", "XL", "2XL", "4XL"];
+
+const ServiceSizeNames: ReadonlyMap<string, ServiceSize> = new Map<string, ServiceSize>([
+  ["auto", "AUTO"],
+  ["s", "S"],
+  ["m", "M"],
+  ["l", "L"],
+  ["xl", "XL"],
+  ["2xl", "2XL"],
+  ["4xl", "4XL"],
+]);
+
+export function parseServiceSize(value: string): ServiceSize | undefined {
+  return ServiceSizeNames.get(value.toLowerCase());
+}
+
+export type SocketFamily = "tcp" | "unix";
+
+export const SocketFamilyValues: readonly SocketFamily[] = ["tcp", "unix"];
+
+const SocketFamilyNames: ReadonlyMap<string, SocketFamily> = new Map<string, SocketFamily>([
+  ["tcp", "tcp"],
+  ["unix", "unix"],
+]);
+
+export function parseSocketFamily(value: string): SocketFamily | undefined {
+  return SocketFamilyNames.get(value.toLowerCase());
+}
+
+export type SocketProtocol = "http" | "fastcgi" | "uwsgi";
+
+export const SocketProtocolValues: readonly SocketProtocol[] = ["http", "fastcgi", "uwsgi"];
+
+const SocketProtocolNames: ReadonlyMap<string, SocketProtocol> = new Map<string, SocketProtocol>([
+  ["http", "http"],
+  ["fastcgi", "fastcgi"],
+  ["uwsgi", "uwsgi"],
+]);
+
+export function parseSocketProtocol(value: string): SocketProtocol | undefined {
+  return SocketProtocolNames.get(value.toLowerCase());
+}
+
+export type StringMap = { [key: string]: string };
+
+export interface TLSSTS {
+  enabled: boolean;
+  include_subdomains: boolean;
+  preload: boolean;
+}
+
+export interface TLSSettings {
+  strict_transport_security: TLSSTS;
+  min_version: TLSVersion | null;
+  client_authentication: string;
+  client_certificate_authorities: Certificate[];
+}
+
+export type TLSVersion = string;
+
+export interface Upstream {
+  socket_family: SocketFamily;
+  socket_protocol: SocketProtocol;
+}
+
+export type Variables = JSONObject;
+
+export interface Web {
+  locations: WebLocations;
+  commands: Commands;
+  upstream: Upstream;
+  document_root?: string | null;
+  passthru?: string | null;
+  index_files?: string[];
+  whitelist?: string[];
+  blacklist?: string[];
+  expires?: Duration | null;
+  move_to_root?: boolean | null;
+}
+
+export interface WebLocation {
+  root: string;
+  expires: Duration;
+  passthru: Passthru;
+  scripts: boolean;
+  index: string[];
+  allow: boolean;
+  headers: StringMap;
+  rules: WebRules;
+}
+
+export type WebLocations = { [key: string]: WebLocation };
+
+export interface WebRule {
+  expires: Duration;
+  passthru: Passthru;
+  scripts: boolean;
+  allow: boolean;
+  headers: StringMap;
+}
+
+export type WebRules = { [key: string]: WebRule };
+
+export interface Worker {
+  commands: Commands;
+}
+
+export type Workers = { [key: string]: Worker };
+
+export function lookupApplication(env: Env, prefix: string = DEFAULT_PREFIX): Application | undefined {
+  const value = env[prefix + "APPLICATION"];
+  return value === undefined ? undefined : decodeJSON<Application>(value);
+}
+
+export function lookupApplicationName(env: Env, prefix: string = DEFAULT_PREFIX): string | undefined {
+  const value = env[prefix + "APPLICATION_NAME"];
+  return value === undefined ? undefined : value;
+}
+
+export const lookupAppName = lookupApplicationName;
+
+export function lookupAppCommand(env: Env, prefix: string = DEFAULT_PREFIX): string | undefined {
+  const value = env[prefix + "APP_COMMAND"];
+  return value === undefined ? undefined : value;
+}
+
+export const lookupApplicationCommand = lookupAppCommand;
+
+export function lookupAppDir(env: Env, prefix: string = DEFAULT_PREFIX): string | undefined {
+  const value = env[prefix + "APP_DIR"];
+  return value === undefined ? undefined : value === "" ? undefined : value;
+}
+
+export function lookupBranch(env: Env, prefix: string = DEFAULT_PREFIX): string | undefined {
+  const value = env[prefix + "BRANCH"];
+  return value === undefined ? undefined : value;
+}
+
+export function lookupCacheDir(env: Env, prefix: string = DEFAULT_PREFIX): string | undefined {
+  const value = env[prefix + "CACHE_DIR"];
+  return value === undefined ? undefined : value === "" ? undefined : value;
+}
+
+export function lookupDir(env: Env, prefix: string = DEFAULT_PREFIX): string | undefined {
+  const value = env[prefix + "DIR"];
+  return value === undefined ? undefined : value === "" ? undefined : value;
+}
+
+export function lookupDocumentRoot(env: Env, prefix: string = DEFAULT_PREFIX): string | undefined {
+  const value = env[prefix + "DOCUMENT_ROOT"];
+  return value === undefined ? undefined : value === "" ? undefined : value;
+}
+
+export function lookupEnvironment(env: Env, prefix: string = DEFAULT_PREFIX): string | undefined {
+  const value = env[prefix + "ENVIRONMENT"];
+  return value === undefined ? undefined : value;
+}
+
+export function lookupEnvironmentType(env: Env, prefix: string = DEFAULT_PREFIX): EnvironmentType | undefined {
+  const value = env[prefix + "ENVIRONMENT_TYPE"];
+  return value === undefined ? undefined : parseEnvironmentType(value);
+}
+
+export function lookupMode(env: Env, prefix: string = DEFAULT_PREFIX): string | undefined {
+  const value = env[prefix + "MODE"];
+  return value === undefined ? undefined : value;
+}
+
+export function lookupOutputDir(env: Env, prefix: string = DEFAULT_PREFIX): string | undefined {
+  const value = env[prefix + "OUTPUT_DIR"];
+  return value === undefined ? undefined : value === "" ? undefined : value;
+}
+
+export function lookupPort(env: Env): string | undefined {
+  const value = env["PORT"];
+  return value === undefined ? undefined : value;
+}
+
+export function lookupProject(env: Env, prefix: string = DEFAULT_PREFIX): string | undefined {
+  const value = env[prefix + "PROJECT"];
+  return value === undefined ? undefined : value;
+}
+
+export function lookupProjectEntropy(env: Env, prefix: string = DEFAULT_PREFIX): string | undefined {
+  const value = env[prefix + "PROJECT_ENTROPY"];
+  return value === undefined ? undefined : value;
+}
+
+export function lookupRelationships(env: Env, prefix: string = DEFAULT_PREFIX): Relationships | undefined {
+  const value = env[prefix + "RELATIONSHIPS"];
+  return value === undefined ? undefined : decodeJSON<Relationships>(value);
+}
+
+export function lookupRoutes(env: Env, prefix: string = DEFAULT_PREFIX): Routes | undefined {
+  const value = env[prefix + "ROUTES"];
+  return value === undefined ? undefined : decodeJSON<Routes>(value);
+}
+
+export function lookupSMTPHost(env: Env, prefix: string = DEFAULT_PREFIX): string | undefined {
+  const value = env[prefix + "SMTP_HOST"];
+  return value === undefined ? undefined : value;
+}
+
+export function lookupSocket(env: Env): string | undefined {
+  const value = env["SOCKET"];
+  return value === undefined ? undefined : value;
+}
+
+export function lookupSourceDir(env: Env, prefix: string = DEFAULT_PREFIX): string | undefined {
+  const value = env[prefix + "SOURCE_DIR"];
+  return value === undefined ? undefined : value === "" ? undefined : value;
+}
+
+export function lookupTreeID(env: Env, prefix: string = DEFAULT_PREFIX): string | undefined {
+  const value = env[prefix + "TREE_ID"];
+  return value === undefined ? undefined : value;
+}
+
+export function lookupVariables(env: Env, prefix: string = DEFAULT_PREFIX): Variables | undefined {
+  const value = env[prefix + "VARIABLES"];
+  return value === undefined ? undefined : decodeJSON<Variables>(value);
+}
+
+export const lookupVars = lookupVariables;
+
+export function lookupXClientCert(env: Env): string | undefined {
+  const value = env["X_CLIENT_CERT"];
+  return value === undefined ? undefined : value;
+}
+
+export function lookupXClientDN(env: Env): string | undefined {
+  const value = env["X_CLIENT_DN"];
+  return value === undefined ? undefined : value;
+}
+
+export function lookupXClientIP(env: Env): string | undefined {
+  const value = env["X_CLIENT_IP"];
+  return value === undefined ? undefined : value;
+}
+
+export function lookupXClientVerify(env: Env): string | undefined {
+  const value = env["X_CLIENT_VERIFY"];
+  return value === undefined ? undefined : value;
+}
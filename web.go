@@ -0,0 +1,104 @@
+package pshgo
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Normalize returns web with its deprecated settings converted into the
+// equivalent location for /, along with a warning describing each
+// conversion. Deprecated settings are dropped when locations are configured.
+func (w Web) Normalize() (Web, []string) {
+	var warnings []string
+	warnf := func(format string, args ...interface{}) {
+		warnings = append(warnings, fmt.Sprintf(format, args...))
+	}
+
+	rv := w
+	rv.DocumentRoot, rv.Passthru, rv.IndexFiles, rv.Whitelist, rv.Blacklist, rv.Expires, rv.MoveToRoot = nil, nil, nil, nil, nil, nil, nil
+
+	if len(w.Locations) > 0 {
+		for _, name := range w.deprecated() {
+			warnf("ignoring deprecated web.%s: web.locations is configured", name)
+		}
+		return rv, warnings
+	}
+
+	if len(w.deprecated()) == 0 {
+		return rv, nil
+	}
+
+	loc := WebLocation{
+		Allow: true,
+		Rules: make(WebRules),
+	}
+
+	if w.DocumentRoot != nil {
+		loc.Root = strings.TrimPrefix(*w.DocumentRoot, "/")
+		warnf("converted web.document_root %q to web.locations[\"/\"].root %q", *w.DocumentRoot, loc.Root)
+	}
+
+	if w.Passthru != nil && *w.Passthru != "" {
+		loc.Passthru = Passthru{Enabled: true, Path: *w.Passthru}
+		warnf("converted web.passthru %q to web.locations[\"/\"].passthru", *w.Passthru)
+	}
+
+	if w.IndexFiles != nil {
+		loc.Index = w.IndexFiles
+		warnf("converted web.index_files to web.locations[\"/\"].index")
+	}
+
+	if w.Expires != nil {
+		loc.Expires = *w.Expires
+		warnf("converted web.expires %s to web.locations[\"/\"].expires", w.Expires.Duration)
+	}
+
+	if len(w.Whitelist) > 0 {
+		loc.Allow = false
+		warnf("converted web.whitelist to web.locations[\"/\"].allow false")
+	}
+
+	for _, pattern := range w.Whitelist {
+		loc.Rules[pattern] = WebRule{Allow: true, Passthru: loc.Passthru, Expires: loc.Expires}
+		warnf("converted web.whitelist %q to an allowing rule", pattern)
+	}
+
+	for _, pattern := range w.Blacklist {
+		loc.Rules[pattern] = WebRule{Allow: false, Passthru: loc.Passthru, Expires: loc.Expires}
+		warnf("converted web.blacklist %q to a denying rule", pattern)
+	}
+
+	if w.MoveToRoot != nil {
+		warnf("ignoring deprecated web.move_to_root: it has no equivalent")
+	}
+
+	rv.Locations = WebLocations{"/": loc}
+	return rv, warnings
+}
+
+// deprecated returns the names of the deprecated settings of w which are set.
+func (w Web) deprecated() []string {
+	var rv []string
+	if w.DocumentRoot != nil {
+		rv = append(rv, "document_root")
+	}
+	if w.Passthru != nil {
+		rv = append(rv, "passthru")
+	}
+	if w.IndexFiles != nil {
+		rv = append(rv, "index_files")
+	}
+	if w.Whitelist != nil {
+		rv = append(rv, "whitelist")
+	}
+	if w.Blacklist != nil {
+		rv = append(rv, "blacklist")
+	}
+	if w.Expires != nil {
+		rv = append(rv, "expires")
+	}
+	if w.MoveToRoot != nil {
+		rv = append(rv, "move_to_root")
+	}
+	return rv
+}
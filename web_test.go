@@ -0,0 +1,79 @@
+package pshgo_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	. "github.com/demosdemon/pshgo"
+)
+
+func TestWeb_Normalize(t *testing.T) {
+	root, passthru, move := "/public", "/index.php", true
+	expires := Duration{Duration: time.Hour}
+
+	web := Web{
+		Commands:     Commands{Start: "./app"},
+		DocumentRoot: &root,
+		Passthru:     &passthru,
+		IndexFiles:   []string{"index.php"},
+		Whitelist:    []string{`\.css$`},
+		Blacklist:    []string{`\.sql$`},
+		Expires:      &expires,
+		MoveToRoot:   &move,
+	}
+
+	normalized, warnings := web.Normalize()
+	assert.Equal(t, Web{
+		Commands: Commands{Start: "./app"},
+		Locations: WebLocations{
+			"/": {
+				Root:     "public",
+				Expires:  expires,
+				Passthru: Passthru{Enabled: true, Path: "/index.php"},
+				Index:    []string{"index.php"},
+				Allow:    false,
+				Rules: WebRules{
+					`\.css$`: {Allow: true, Passthru: Passthru{Enabled: true, Path: "/index.php"}, Expires: expires},
+					`\.sql$`: {Allow: false, Passthru: Passthru{Enabled: true, Path: "/index.php"}, Expires: expires},
+				},
+			},
+		},
+	}, normalized)
+
+	assert.Equal(t, []string{
+		`converted web.document_root "/public" to web.locations["/"].root "public"`,
+		`converted web.passthru "/index.php" to web.locations["/"].passthru`,
+		`converted web.index_files to web.locations["/"].index`,
+		`converted web.expires 1h0m0s to web.locations["/"].expires`,
+		`converted web.whitelist to web.locations["/"].allow false`,
+		`converted web.whitelist "\\.css$" to an allowing rule`,
+		`converted web.blacklist "\\.sql$" to a denying rule`,
+		`ignoring deprecated web.move_to_root: it has no equivalent`,
+	}, warnings)
+
+	data, err := json.Marshal(normalized)
+	require.NoError(t, err)
+	var decoded Web
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, normalized, decoded)
+
+	again, warnings := normalized.Normalize()
+	assert.Equal(t, normalized, again)
+	assert.Empty(t, warnings)
+}
+
+func TestWeb_Normalize_Locations(t *testing.T) {
+	root := "public"
+	web := Web{
+		Locations:    WebLocations{"/": {Root: "web", Allow: true}},
+		DocumentRoot: &root,
+	}
+
+	normalized, warnings := web.Normalize()
+	assert.Equal(t, Web{Locations: web.Locations}, normalized)
+	assert.Equal(t, []string{"ignoring deprecated web.document_root: web.locations is configured"}, warnings)
+}
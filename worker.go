@@ -0,0 +1,342 @@
+package pshgo
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// WorkerState is the state of a supervised worker.
+type WorkerState string
+
+const (
+	WorkerStarting WorkerState = "starting"
+	WorkerRunning  WorkerState = "running"
+	WorkerBackoff  WorkerState = "backoff"
+	WorkerStopping WorkerState = "stopping"
+	WorkerStopped  WorkerState = "stopped"
+	WorkerFatal    WorkerState = "fatal"
+)
+
+// Defaults of a WorkerSupervisor.
+const (
+	DefaultWorkerStopTimeout = 30 * time.Second
+	DefaultWorkerMinBackoff  = time.Second
+	DefaultWorkerMaxBackoff  = time.Minute
+)
+
+// WorkerStatus describes a supervised worker.
+type WorkerStatus struct {
+	Name  string      `json:"name"`
+	State WorkerState `json:"state"`
+	PID   int         `json:"pid,omitempty"`
+	// Restarts counts the times the worker was started again after exiting.
+	Restarts  int        `json:"restarts"`
+	StartedAt *time.Time `json:"started_at,omitempty"`
+	// ExitStatus is the exit status of the last run, -1 when it was killed.
+	ExitStatus *int       `json:"exit_status,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	NextStart  *time.Time `json:"next_start,omitempty"`
+}
+
+// WorkerSupervisor runs the start command of every worker through the
+// shell, restarting the workers that exit with an exponential backoff. On
+// shutdown, each worker is stopped by its stop command, given the process id
+// of the worker as $PID, or by SIGTERM when it has none, and killed when it
+// does not exit within StopTimeout.
+//
+// The output of the workers is written to Output line by line, each line
+// prefixed with the name of its worker.
+type WorkerSupervisor struct {
+	Workers Workers
+
+	// Shell runs the commands, as Shell -c Cmd.
+	Shell string
+	// Dir is the working directory of the commands.
+	Dir string
+	// Env is the environment of the commands; that of the process when nil.
+	Env []string
+
+	Output io.Writer
+
+	StopTimeout time.Duration
+	// MinBackoff is the delay before a worker is first restarted; it doubles
+	// for each restart up to MaxBackoff, and is reset once a worker runs for
+	// MaxBackoff.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+
+	mu     sync.Mutex
+	status map[string]*WorkerStatus
+}
+
+// NewWorkerSupervisor returns the supervisor of the workers of app.
+func NewWorkerSupervisor(app Application) *WorkerSupervisor {
+	return &WorkerSupervisor{
+		Workers:     app.Workers,
+		Shell:       "/bin/sh",
+		Output:      os.Stdout,
+		StopTimeout: DefaultWorkerStopTimeout,
+		MinBackoff:  DefaultWorkerMinBackoff,
+		MaxBackoff:  DefaultWorkerMaxBackoff,
+	}
+}
+
+// Run supervises the workers until ctx is done, then stops them.
+func (s *WorkerSupervisor) Run(ctx context.Context) {
+	names := make([]string, 0, len(s.Workers))
+	for name := range s.Workers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	s.mu.Lock()
+	s.status = make(map[string]*WorkerStatus, len(names))
+	for _, name := range names {
+		s.status[name] = &WorkerStatus{Name: name, State: WorkerStarting}
+	}
+	s.mu.Unlock()
+
+	out := &lockedWriter{w: s.Output}
+
+	var wg sync.WaitGroup
+	for _, name := range names {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			s.supervise(ctx, name, out)
+		}(name)
+	}
+	wg.Wait()
+}
+
+// Status returns the status of every worker, sorted by name.
+func (s *WorkerSupervisor) Status() []WorkerStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rv := make([]WorkerStatus, 0, len(s.status))
+	for _, status := range s.status {
+		rv = append(rv, *status)
+	}
+	sort.Slice(rv, func(i, j int) bool {
+		return rv[i].Name < rv[j].Name
+	})
+	return rv
+}
+
+func (s *WorkerSupervisor) update(name string, fn func(*WorkerStatus)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(s.status[name])
+}
+
+func (s *WorkerSupervisor) supervise(ctx context.Context, name string, out io.Writer) {
+	log := logrus.WithField("worker", name)
+	worker := s.Workers[name]
+
+	backoff := s.MinBackoff
+	for restarts := 0; ; restarts++ {
+		w := &prefixWriter{prefix: []byte(name + " | "), w: out}
+		cmd := s.command(worker.Commands.Start, w)
+
+		start := time.Now()
+		if err := cmd.Start(); err != nil {
+			log.WithError(err).Error("unable to start worker")
+			s.update(name, func(st *WorkerStatus) {
+				st.State, st.Error = WorkerFatal, err.Error()
+			})
+			return
+		}
+
+		log.WithField("pid", cmd.Process.Pid).Info("worker started")
+		s.update(name, func(st *WorkerStatus) {
+			st.State, st.PID, st.Restarts, st.StartedAt, st.NextStart = WorkerRunning, cmd.Process.Pid, restarts, &start, nil
+		})
+
+		exited := make(chan error, 1)
+		go func() {
+			exited <- cmd.Wait()
+		}()
+
+		var err error
+		select {
+		case err = <-exited:
+		case <-ctx.Done():
+			s.update(name, func(st *WorkerStatus) { st.State = WorkerStopping })
+			err = s.stop(log, worker, cmd, exited, w)
+		}
+		w.Flush()
+
+		status := -1
+		if cmd.ProcessState != nil {
+			status = cmd.ProcessState.ExitCode()
+		}
+		uptime := time.Since(start)
+
+		log = log.WithFields(logrus.Fields{"exit_status": status, "uptime": uptime.String()})
+		s.update(name, func(st *WorkerStatus) {
+			st.PID, st.ExitStatus, st.Error = 0, &status, ""
+			if err != nil {
+				st.Error = err.Error()
+			}
+		})
+
+		if ctx.Err() != nil {
+			log.Info("worker stopped")
+			s.update(name, func(st *WorkerStatus) { st.State = WorkerStopped })
+			return
+		}
+
+		if uptime >= s.MaxBackoff {
+			backoff = s.MinBackoff
+		}
+
+		next := time.Now().Add(backoff)
+		log.WithError(err).WithField("backoff", backoff.String()).Warn("worker exited; restarting")
+		s.update(name, func(st *WorkerStatus) {
+			st.State, st.NextStart = WorkerBackoff, &next
+		})
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			s.update(name, func(st *WorkerStatus) { st.State, st.NextStart = WorkerStopped, nil })
+			return
+		case <-timer.C:
+		}
+
+		if backoff *= 2; backoff > s.MaxBackoff {
+			backoff = s.MaxBackoff
+		}
+	}
+}
+
+// stop stops a running worker, returning the result of its start command.
+func (s *WorkerSupervisor) stop(log *logrus.Entry, worker Worker, cmd *exec.Cmd, exited <-chan error, out io.Writer) error {
+	timeout := time.NewTimer(s.StopTimeout)
+	defer timeout.Stop()
+
+	if worker.Commands.Stop != "" {
+		log.Info("running the stop command")
+		stop := s.command(worker.Commands.Stop, out)
+		stop.Env = append(append([]string(nil), stop.Env...), "PID="+strconv.Itoa(cmd.Process.Pid))
+
+		ctx, cancel := context.WithTimeout(context.Background(), s.StopTimeout)
+		defer cancel()
+		if err := runCommand(ctx, stop); err != nil {
+			log.WithError(err).Warn("stop command failed")
+		}
+	} else {
+		log.Info("sending SIGTERM")
+		if err := signalProcessGroup(cmd, syscall.SIGTERM); err != nil {
+			log.WithError(err).Warn("unable to signal worker")
+		}
+	}
+
+	select {
+	case err := <-exited:
+		return err
+	case <-timeout.C:
+		log.WithField("timeout", s.StopTimeout.String()).Warn("worker did not stop in time; killing it")
+		_ = signalProcessGroup(cmd, syscall.SIGKILL)
+		return <-exited
+	}
+}
+
+func (s *WorkerSupervisor) command(command string, out io.Writer) *exec.Cmd {
+	cmd := exec.Command(s.Shell, "-c", command)
+	cmd.Dir = s.Dir
+	cmd.Env = s.Env
+	if cmd.Env == nil {
+		cmd.Env = os.Environ()
+	}
+	cmd.Stdout = out
+	cmd.Stderr = out
+	setProcessGroup(cmd)
+	return cmd
+}
+
+// WorkerEnviron returns environ without the variables telling an application
+// where to listen, as workers do not serve requests.
+func WorkerEnviron(environ []string) []string {
+	rv := make([]string, 0, len(environ))
+	for _, kv := range environ {
+		if strings.HasPrefix(kv, "PORT=") || strings.HasPrefix(kv, "SOCKET=") {
+			continue
+		}
+		rv = append(rv, kv)
+	}
+	return rv
+}
+
+// lockedWriter serializes the writes of the workers.
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (w *lockedWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.w.Write(p)
+}
+
+// prefixWriter writes complete lines to w, each one prefixed.
+type prefixWriter struct {
+	prefix []byte
+	w      io.Writer
+
+	mu  sync.Mutex
+	buf []byte
+}
+
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = append(w.buf, p...)
+
+	var out []byte
+	for {
+		idx := bytes.IndexByte(w.buf, '\n')
+		if idx < 0 {
+			break
+		}
+		out = append(out, w.prefix...)
+		out = append(out, w.buf[:idx+1]...)
+		w.buf = w.buf[idx+1:]
+	}
+
+	if len(out) > 0 {
+		if _, err := w.w.Write(out); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Flush writes the last line when it is incomplete.
+func (w *prefixWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.buf) == 0 {
+		return
+	}
+
+	line := append(append(append([]byte(nil), w.prefix...), w.buf...), '\n')
+	w.buf = nil
+	_, _ = w.w.Write(line)
+}
@@ -0,0 +1,177 @@
+package pshgo_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	. "github.com/demosdemon/pshgo"
+)
+
+// syncBuffer is a bytes.Buffer safe to read while the workers write to it.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func testWorkerSupervisor(workers Workers) (*WorkerSupervisor, *syncBuffer) {
+	s := NewWorkerSupervisor(Application{Workers: workers})
+	out := &syncBuffer{}
+	s.Output = out
+	s.StopTimeout = time.Second
+	s.MinBackoff = 50 * time.Millisecond
+	s.MaxBackoff = 200 * time.Millisecond
+	return s, out
+}
+
+func runWorkerSupervisor(s *WorkerSupervisor) (context.CancelFunc, <-chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+	return cancel, done
+}
+
+func waitWorkers(t *testing.T, done <-chan struct{}, timeout time.Duration) {
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		t.Fatal("Run did not return once its context was done")
+	}
+}
+
+func workerStatus(s *WorkerSupervisor, name string) WorkerStatus {
+	for _, status := range s.Status() {
+		if status.Name == name {
+			return status
+		}
+	}
+	return WorkerStatus{}
+}
+
+func TestWorkerSupervisor_Output(t *testing.T) {
+	s, out := testWorkerSupervisor(Workers{
+		"greeter": {Commands: Commands{Start: `echo "hello $GREETING"; printf partial >&2; sleep 10`}},
+	})
+	s.Env = []string{"GREETING=world"}
+
+	cancel, done := runWorkerSupervisor(s)
+	time.Sleep(300 * time.Millisecond)
+
+	status := workerStatus(s, "greeter")
+	assert.Equal(t, WorkerRunning, status.State)
+	assert.NotZero(t, status.PID)
+	assert.NotNil(t, status.StartedAt)
+
+	cancel()
+	waitWorkers(t, done, 2*time.Second)
+
+	assert.Equal(t, "greeter | hello world\ngreeter | partial\n", out.String())
+
+	status = workerStatus(s, "greeter")
+	assert.Equal(t, WorkerStopped, status.State)
+	assert.Zero(t, status.PID)
+	assert.Equal(t, 0, status.Restarts)
+}
+
+func TestWorkerSupervisor_Restart(t *testing.T) {
+	s, out := testWorkerSupervisor(Workers{
+		"crashy": {Commands: Commands{Start: "echo crash; exit 2"}},
+	})
+
+	cancel, done := runWorkerSupervisor(s)
+	// runs at 0, 50ms, 150ms, 350ms and 550ms
+	time.Sleep(450 * time.Millisecond)
+	cancel()
+	waitWorkers(t, done, time.Second)
+
+	assert.Equal(t, strings.Repeat("crashy | crash\n", 4), out.String())
+
+	status := workerStatus(s, "crashy")
+	assert.Equal(t, WorkerStopped, status.State)
+	assert.Equal(t, 3, status.Restarts)
+	if assert.NotNil(t, status.ExitStatus) {
+		assert.Equal(t, 2, *status.ExitStatus)
+	}
+	assert.Equal(t, "exit status 2", status.Error)
+	assert.Nil(t, status.NextStart)
+}
+
+func TestWorkerSupervisor_Stop(t *testing.T) {
+	s, out := testWorkerSupervisor(Workers{
+		"graceful": {Commands: Commands{
+			Start: `trap 'echo bye; exit 0' TERM; while true; do sleep 0.05; done`,
+		}},
+		"custom": {Commands: Commands{
+			Start: "sleep 10",
+			Stop:  `echo "stopping $PID"; kill $PID`,
+		}},
+		"stubborn": {Commands: Commands{
+			Start: `trap '' TERM; while true; do sleep 0.05; done`,
+		}},
+	})
+	s.StopTimeout = 300 * time.Millisecond
+
+	cancel, done := runWorkerSupervisor(s)
+	time.Sleep(200 * time.Millisecond)
+	pid := workerStatus(s, "custom").PID
+
+	start := time.Now()
+	cancel()
+	waitWorkers(t, done, 2*time.Second)
+	assert.True(t, time.Since(start) < time.Second)
+
+	assert.Contains(t, out.String(), "graceful | bye\n")
+	assert.Contains(t, out.String(), "custom | stopping ")
+	assert.NotZero(t, pid)
+
+	statuses := s.Status()
+	require.Len(t, statuses, 3)
+	assert.Equal(t, []string{"custom", "graceful", "stubborn"}, []string{statuses[0].Name, statuses[1].Name, statuses[2].Name})
+	for _, status := range statuses {
+		assert.Equal(t, WorkerStopped, status.State, status.Name)
+	}
+
+	assert.Equal(t, 0, *statuses[1].ExitStatus)
+	assert.Equal(t, -1, *statuses[2].ExitStatus)
+}
+
+func TestWorkerSupervisor_Fatal(t *testing.T) {
+	s, _ := testWorkerSupervisor(Workers{
+		"broken": {Commands: Commands{Start: "true"}},
+	})
+	s.Shell = "/does/not/exist"
+
+	cancel, done := runWorkerSupervisor(s)
+	defer cancel()
+	waitWorkers(t, done, time.Second)
+
+	status := workerStatus(s, "broken")
+	assert.Equal(t, WorkerFatal, status.State)
+	assert.NotEmpty(t, status.Error)
+}
+
+func TestWorkerEnviron(t *testing.T) {
+	environ := []string{"PATH=/bin", "PORT=8888", "SOCKET=/run/app.sock", "PORTAL=on"}
+	assert.Equal(t, []string{"PATH=/bin", "PORTAL=on"}, WorkerEnviron(environ))
+}
@@ -0,0 +1,49 @@
+package pshgo
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+)
+
+// YAMLToJSON converts a YAML document into the equivalent JSON document so it
+// can be decoded using the json tags and unmarshalers of the models.
+func YAMLToJSON(data []byte) ([]byte, error) {
+	logrus.Trace("YAMLToJSON")
+	var v interface{}
+	if err := yaml.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(jsonCompatible(v))
+}
+
+func unmarshalYAML(data []byte, v interface{}) error {
+	data, err := YAMLToJSON(data)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+func jsonCompatible(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		rv := make(JSONObject, len(v))
+		for k, val := range v {
+			rv[fmt.Sprint(k)] = jsonCompatible(val)
+		}
+		return rv
+	case []interface{}:
+		rv := make(JSONArray, len(v))
+		for idx, val := range v {
+			rv[idx] = jsonCompatible(val)
+		}
+		return rv
+	default:
+		return v
+	}
+}
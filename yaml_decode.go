@@ -0,0 +1,534 @@
+package pshgo
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+)
+
+// StrictConfig reports the keys of configuration files that the models do not
+// know as errors. They are only logged as warnings otherwise, since the
+// platform accepts keys pshgo does not model.
+var StrictConfig = false
+
+// ConfigError is an error in a configuration file, located by the line and
+// column of the offending key when they are known.
+type ConfigError struct {
+	File   string
+	Line   int
+	Column int
+	Path   string
+	Err    error
+}
+
+func (e *ConfigError) Error() string {
+	var b strings.Builder
+	if e.File != "" {
+		b.WriteString(e.File)
+		b.WriteString(":")
+	}
+	if e.Line > 0 {
+		_, _ = fmt.Fprintf(&b, "%d:%d:", e.Line, e.Column)
+	}
+	if b.Len() > 0 {
+		b.WriteString(" ")
+	}
+	if e.Path != "" {
+		b.WriteString(e.Path)
+		b.WriteString(": ")
+	}
+	b.WriteString(e.Err.Error())
+	return b.String()
+}
+
+func (e *ConfigError) Cause() error {
+	return e.Err
+}
+
+// formatPath renders a path such as web.locations["/"].rules or crons[0].
+func formatPath(path []string) string {
+	var b strings.Builder
+	for _, seg := range path {
+		switch {
+		case strings.HasPrefix(seg, "[") && strings.HasSuffix(seg, "]"):
+			b.WriteString(seg)
+		case yamlIdentifier.MatchString(seg):
+			if b.Len() > 0 {
+				b.WriteString(".")
+			}
+			b.WriteString(seg)
+		default:
+			b.WriteString("[")
+			b.WriteString(strconv.Quote(seg))
+			b.WriteString("]")
+		}
+	}
+	return b.String()
+}
+
+var yamlIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func indexSegment(idx int) string {
+	return "[" + strconv.Itoa(idx) + "]"
+}
+
+type yamlPosition struct {
+	Line   int
+	Column int
+}
+
+// yamlIndex maps the paths of the keys and sequence items of the block
+// collections of a YAML document to their position. Flow collections are not
+// indexed; errors inside them point at the key holding them.
+type yamlIndex map[string]yamlPosition
+
+func yamlPathKey(path []string) string {
+	return strings.Join(path, "\x00")
+}
+
+func newYAMLIndex(data []byte) yamlIndex {
+	type node struct {
+		col  int
+		item bool
+		path []string
+	}
+
+	var (
+		idx      = yamlIndex{}
+		stack    []node
+		counts   = make(map[string]int)
+		blockCol = -1
+	)
+
+	parent := func() []string {
+		if len(stack) == 0 {
+			return nil
+		}
+		return stack[len(stack)-1].path
+	}
+
+	push := func(line, col int, item bool, seg string) {
+		path := append(append([]string(nil), parent()...), seg)
+		idx[yamlPathKey(path)] = yamlPosition{Line: line, Column: col + 1}
+		stack = append(stack, node{col: col, item: item, path: path})
+	}
+
+	for n, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimRight(line, "\r")
+		trimmed := strings.TrimLeft(line, " ")
+		col := len(line) - len(trimmed)
+
+		if blockCol >= 0 {
+			if strings.TrimSpace(trimmed) == "" || col > blockCol {
+				continue
+			}
+			blockCol = -1
+		}
+
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || trimmed == "---" {
+			continue
+		}
+
+		for trimmed != "" {
+			if trimmed == "-" || strings.HasPrefix(trimmed, "- ") {
+				// items are children of a key at the same column
+				for len(stack) > 0 {
+					top := stack[len(stack)-1]
+					if top.col < col || (top.col == col && !top.item) {
+						break
+					}
+					stack = stack[:len(stack)-1]
+				}
+
+				key := yamlPathKey(parent())
+				push(n+1, col, true, indexSegment(counts[key]))
+				counts[key]++
+
+				rest := strings.TrimLeft(strings.TrimPrefix(trimmed, "-"), " ")
+				col += len(trimmed) - len(rest)
+				trimmed = rest
+				if isBlockScalar(trimmed) {
+					blockCol = col - 2
+				}
+				continue
+			}
+
+			key, value, ok := splitYAMLKey(trimmed)
+			if !ok {
+				break
+			}
+
+			for len(stack) > 0 && stack[len(stack)-1].col >= col {
+				stack = stack[:len(stack)-1]
+			}
+
+			push(n+1, col, false, key)
+			if isBlockScalar(value) {
+				blockCol = col
+			}
+			break
+		}
+	}
+
+	return idx
+}
+
+func isBlockScalar(value string) bool {
+	return strings.HasPrefix(value, "|") || strings.HasPrefix(value, ">")
+}
+
+var yamlQuotedKey = regexp.MustCompile(`^("(?:[^"\\]|\\.)*"|'(?:[^']|'')*')\s*:(?:\s|$)`)
+
+// splitYAMLKey splits "key: value" returning the unquoted key and the value.
+func splitYAMLKey(s string) (key, value string, ok bool) {
+	if m := yamlQuotedKey.FindStringSubmatch(s); m != nil {
+		if err := yaml.Unmarshal([]byte(m[1]), &key); err != nil {
+			return "", "", false
+		}
+		return key, strings.TrimSpace(s[len(m[0]):]), true
+	}
+
+	switch s[0] {
+	case '{', '[', '"', '\'', '#', '?', '&', '*', '!', '|', '>':
+		return "", "", false
+	}
+
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '#' && i > 0 && s[i-1] == ' ':
+			return "", "", false
+		case s[i] == ':' && (i+1 == len(s) || s[i+1] == ' ' || s[i+1] == '\t'):
+			return strings.TrimSpace(s[:i]), strings.TrimSpace(s[i+1:]), true
+		}
+	}
+
+	return "", "", false
+}
+
+// lookup returns the position of the deepest indexed node of path.
+func (idx yamlIndex) lookup(path []string) yamlPosition {
+	for n := len(path); n > 0; n-- {
+		if pos, ok := idx[yamlPathKey(path[:n])]; ok {
+			return pos
+		}
+	}
+	return yamlPosition{Line: 1, Column: 1}
+}
+
+// yamlDecoder decodes a YAML document into the models, reporting every error
+// with the position of the node it was found at. Values implementing
+// yaml.Unmarshaler or encoding.TextUnmarshaler are decoded by yaml.v2.
+type yamlDecoder struct {
+	file  string
+	index yamlIndex
+	errs  error
+}
+
+func newYAMLDecoder(file string, data []byte) *yamlDecoder {
+	return &yamlDecoder{
+		file:  file,
+		index: newYAMLIndex(data),
+	}
+}
+
+func (d *yamlDecoder) errorAt(path []string, err error) *ConfigError {
+	pos := d.index.lookup(path)
+	return &ConfigError{
+		File:   d.file,
+		Line:   pos.Line,
+		Column: pos.Column,
+		Path:   formatPath(path),
+		Err:    err,
+	}
+}
+
+func (d *yamlDecoder) fail(path []string, err error) {
+	d.errs = multierror.Append(d.errs, d.errorAt(path, err))
+}
+
+// unknown reports a key matching no field, failing only with StrictConfig.
+func (d *yamlDecoder) unknown(path []string, name string) {
+	err := fmt.Errorf("unknown field %q", name)
+	if StrictConfig {
+		d.fail(path, err)
+		return
+	}
+	logrus.WithError(d.errorAt(path, err)).Warn("ignoring unknown configuration key")
+}
+
+// Decode parses data into v, which must be a pointer.
+func (d *yamlDecoder) Decode(data []byte, v interface{}) error {
+	var node interface{}
+	if err := d.parse(data, &node); err != nil {
+		return err
+	}
+
+	d.decode(nil, node, reflect.ValueOf(v).Elem())
+	return d.errs
+}
+
+// parse unmarshals data into v, locating syntax errors by their line.
+func (d *yamlDecoder) parse(data []byte, v interface{}) error {
+	if err := yaml.Unmarshal(data, v); err != nil {
+		line := 1
+		if m := yamlErrorLine.FindStringSubmatch(err.Error()); m != nil {
+			line, _ = strconv.Atoi(m[1])
+		}
+		return &ConfigError{File: d.file, Line: line, Column: 1, Err: err}
+	}
+	return nil
+}
+
+// yamlLookup returns the node at path in a document parsed by yaml.v2.
+func yamlLookup(node interface{}, path ...string) (interface{}, bool) {
+	for _, key := range path {
+		m, ok := node.(map[interface{}]interface{})
+		if !ok {
+			return nil, false
+		}
+
+		node, ok = m[key]
+		if !ok {
+			return nil, false
+		}
+	}
+	return node, true
+}
+
+var yamlErrorLine = regexp.MustCompile(`line (\d+)`)
+
+var (
+	yamlUnmarshalerType = reflect.TypeOf((*yaml.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+func (d *yamlDecoder) decode(path []string, node interface{}, v reflect.Value) bool {
+	if node == nil {
+		return true
+	}
+
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return d.decode(path, node, v.Elem())
+	}
+
+	pt := reflect.PtrTo(v.Type())
+	if pt.Implements(yamlUnmarshalerType) || pt.Implements(textUnmarshalerType) {
+		return d.leaf(path, node, v)
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		return d.decodeStruct(path, node, v)
+	case reflect.Map:
+		return d.decodeMap(path, node, v)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return d.leaf(path, node, v)
+		}
+		return d.decodeSlice(path, node, v)
+	case reflect.Interface:
+		v.Set(reflect.ValueOf(jsonCompatible(node)))
+		return true
+	default:
+		return d.leaf(path, node, v)
+	}
+}
+
+// leaf decodes node into v with yaml.v2.
+func (d *yamlDecoder) leaf(path []string, node interface{}, v reflect.Value) bool {
+	data, err := yaml.Marshal(node)
+	if err == nil {
+		ptr := reflect.New(v.Type())
+		err = yaml.Unmarshal(data, ptr.Interface())
+		if err == nil {
+			v.Set(ptr.Elem())
+			return true
+		}
+	}
+
+	if te, ok := err.(*yaml.TypeError); ok {
+		msgs := make([]string, len(te.Errors))
+		for idx, msg := range te.Errors {
+			msgs[idx] = yamlErrorPrefix.ReplaceAllString(msg, "")
+		}
+		err = fmt.Errorf("%s", strings.Join(msgs, "; "))
+	}
+
+	d.fail(path, err)
+	return false
+}
+
+var yamlErrorPrefix = regexp.MustCompile(`^line \d+: `)
+
+func (d *yamlDecoder) mapping(path []string, node interface{}) (map[interface{}]interface{}, []interface{}, bool) {
+	m, ok := node.(map[interface{}]interface{})
+	if !ok {
+		d.fail(path, fmt.Errorf("expected a mapping, found %s", yamlKind(node)))
+		return nil, nil, false
+	}
+
+	keys := make([]interface{}, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j])
+	})
+
+	return m, keys, true
+}
+
+func (d *yamlDecoder) decodeStruct(path []string, node interface{}, v reflect.Value) bool {
+	m, keys, ok := d.mapping(path, node)
+	if !ok {
+		return false
+	}
+
+	fields := yamlFields(v.Type())
+	for _, k := range keys {
+		name := fmt.Sprint(k)
+		sub := append(append([]string(nil), path...), name)
+
+		index, ok := fields[name]
+		if ok {
+			d.decode(sub, m[k], v.FieldByIndex(index))
+			continue
+		}
+
+		index, ok = fields[yamlInlineMap]
+		if !ok {
+			d.unknown(sub, name)
+			continue
+		}
+
+		// remaining keys are collected by the inlined map
+		extra := v.FieldByIndex(index)
+		if extra.IsNil() {
+			extra.Set(reflect.MakeMap(extra.Type()))
+		}
+		ev := reflect.New(extra.Type().Elem()).Elem()
+		if d.decode(sub, m[k], ev) {
+			extra.SetMapIndex(reflect.ValueOf(name).Convert(extra.Type().Key()), ev)
+		}
+	}
+
+	return true
+}
+
+func (d *yamlDecoder) decodeMap(path []string, node interface{}, v reflect.Value) bool {
+	m, keys, ok := d.mapping(path, node)
+	if !ok {
+		return false
+	}
+
+	t := v.Type()
+	if v.IsNil() {
+		v.Set(reflect.MakeMapWithSize(t, len(m)))
+	}
+
+	for _, k := range keys {
+		name := fmt.Sprint(k)
+		sub := append(append([]string(nil), path...), name)
+
+		kv := reflect.New(t.Key()).Elem()
+		pk := reflect.PtrTo(t.Key())
+		if t.Key().Kind() == reflect.String && !pk.Implements(yamlUnmarshalerType) && !pk.Implements(textUnmarshalerType) {
+			kv.SetString(name)
+		} else if !d.leaf(sub, k, kv) {
+			continue
+		}
+
+		ev := reflect.New(t.Elem()).Elem()
+		if d.decode(sub, m[k], ev) {
+			v.SetMapIndex(kv, ev)
+		}
+	}
+
+	return true
+}
+
+func (d *yamlDecoder) decodeSlice(path []string, node interface{}, v reflect.Value) bool {
+	list, ok := node.([]interface{})
+	if !ok {
+		d.fail(path, fmt.Errorf("expected a sequence, found %s", yamlKind(node)))
+		return false
+	}
+
+	rv := reflect.MakeSlice(v.Type(), len(list), len(list))
+	for idx, item := range list {
+		sub := append(append([]string(nil), path...), indexSegment(idx))
+		d.decode(sub, item, rv.Index(idx))
+	}
+	v.Set(rv)
+
+	return true
+}
+
+func yamlKind(node interface{}) string {
+	switch node.(type) {
+	case map[interface{}]interface{}:
+		return "a mapping"
+	case []interface{}:
+		return "a sequence"
+	default:
+		return fmt.Sprintf("%q", fmt.Sprint(node))
+	}
+}
+
+// yamlInlineMap is the yamlFields key of the inlined map, if any, collecting
+// the keys matching no other field.
+const yamlInlineMap = ",inline"
+
+// yamlFields maps the YAML names of the fields of a struct to their index,
+// following inlined structs.
+func yamlFields(t reflect.Type) map[string][]int {
+	rv := make(map[string][]int)
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" && !sf.Anonymous {
+			continue
+		}
+
+		tag := sf.Tag.Get("yaml")
+		if tag == "-" {
+			continue
+		}
+
+		name, opts := tag, ""
+		if idx := strings.Index(tag, ","); idx >= 0 {
+			name, opts = tag[:idx], tag[idx+1:]
+		}
+
+		if strings.Contains(","+opts+",", ",inline,") {
+			switch sf.Type.Kind() {
+			case reflect.Struct:
+				for k, index := range yamlFields(sf.Type) {
+					if _, ok := rv[k]; !ok {
+						rv[k] = append([]int{i}, index...)
+					}
+				}
+				continue
+			case reflect.Map:
+				rv[yamlInlineMap] = []int{i}
+				continue
+			}
+		}
+
+		if name == "" {
+			name = strings.ToLower(sf.Name)
+		}
+		rv[name] = []int{i}
+	}
+	return rv
+}